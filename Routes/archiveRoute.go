@@ -0,0 +1,35 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing download beberapa file sekaligus dalam satu archive
+func NewArchiveRoutes(app *fiber.App, archiveHandler *handler.ArchiveHandler) {
+	app.Post("/files/archive", middleware.RequireLogin, archiveHandler.Archive)
+
+	describeArchiveRoutes()
+}
+
+// dokumentasi openapi untuk route archive
+func describeArchiveRoutes() {
+	ApiDocs.Describe(http.MethodPost, "/files/archive", openapi.Operation{
+		Summary:     "download files as one zip or tar.gz archive",
+		Description: "only files of the current user, or any file for admins; the archive is streamed while it is built, files deleted meanwhile are listed in MISSING.txt inside the archive",
+		Tags:        []string{"file"},
+		Request:     dto.ArchiveRequest{},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentType: "application/zip"},
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusForbidden, "file of another user or quarantined", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestEntityTooLarge, "archive exceeds archive.max_size", dto.ApiResponse{}),
+		},
+	})
+}
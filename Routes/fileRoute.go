@@ -0,0 +1,83 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing file hasil upload
+func NewFileRoutes(app *fiber.App, fileHandler *handler.FileHandler) {
+	app.Get("/files", middleware.RequireLogin, fileHandler.List)
+	app.Get("/files/:id", middleware.RequireLogin, fileHandler.Download)
+	app.Delete("/files/:id", middleware.RequireLogin, fileHandler.Delete)
+	app.Get("/files/:id/variants/:name", middleware.RequireLogin, fileHandler.Variant)
+
+	describeFileRoutes()
+}
+
+// dokumentasi openapi untuk route file
+func describeFileRoutes() {
+	rangeHeader := openapi.Parameter{Name: "Range", In: "header", Description: "single byte range, e.g. bytes=0-1023"}
+	unauthorized := openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{})
+	ApiDocs.Describe(http.MethodGet, "/files", openapi.Operation{
+		Summary:    "list uploaded files",
+		Tags:       []string{"file"},
+		Parameters: openapi.ParamsOf(dto.FileQuery{}),
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only admins can list the files of other users", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodDelete, "/files/:id", openapi.Operation{
+		Summary: "delete uploaded file and its variants",
+		Tags:    []string{"file"},
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Description: "file id", Required: true},
+		},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only the owner or an admin can delete the file", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/files/:id", openapi.Operation{
+		Summary: "download uploaded file, only for its owner or an admin",
+		Tags:    []string{"file"},
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Description: "file id", Required: true},
+			rangeHeader,
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentType: "application/octet-stream"},
+			{Status: http.StatusPartialContent, Description: "requested byte range", ContentType: "application/octet-stream"},
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "not the owner or an admin, or file is quarantined until scanned clean", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestedRangeNotSatisfiable, "", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/files/:id/variants/:name", openapi.Operation{
+		Summary: "download image variant, only for the owner of the file or an admin",
+		Tags:    []string{"file"},
+		Parameters: []openapi.Parameter{
+			{Name: "id", In: "path", Description: "file id", Required: true},
+			{Name: "name", In: "path", Description: "variant name from config images.variants", Required: true},
+			rangeHeader,
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentType: "image/*"},
+			{Status: http.StatusPartialContent, Description: "requested byte range", ContentType: "image/*"},
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "not the owner or an admin, or file is quarantined until scanned clean", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestedRangeNotSatisfiable, "", dto.ApiResponse{}),
+		},
+	})
+}
@@ -0,0 +1,26 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// dokumentasi route, diisi oleh setiap NewXxxRoutes di samping registrasi route-nya
+var ApiDocs = openapi.NewRegistry()
+
+// spec di-generate saat request pertama dari route yang terdaftar di app
+func NewOpenApiSpec(app *fiber.App, info openapi.Info) *openapi.Spec {
+	return openapi.NewSpec(app, ApiDocs, info)
+}
+
+func NewOpenApiRoutes(app *fiber.App, spec *openapi.Spec) {
+	handler := handler.NewOpenApiHandler(spec)
+
+	app.Get("/openapi.json", handler.OpenApiJson)
+	app.Get("/docs", handler.Docs)
+
+	ApiDocs.Describe(http.MethodGet, "/openapi.json", openapi.Operation{Hidden: true})
+	ApiDocs.Describe(http.MethodGet, "/docs", openapi.Operation{Hidden: true})
+}
@@ -0,0 +1,70 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing order per user, login dengan http basic auth seperti route user
+func NewOrderRoutes(app *fiber.App, orderHandler *handler.OrderHandler) {
+	app.Post("/user/:userId/order", orderHandler.Create)
+	app.Get("/user/:userId/order", orderHandler.List)
+	app.Get("/user/:userId/order/:orderId", orderHandler.Get)
+	app.Patch("/user/:userId/order/:orderId", orderHandler.UpdateStatus)
+	app.Post("/user/:userId/order/:orderId/cancel", orderHandler.Cancel)
+
+	describeOrderRoutes()
+}
+
+// dokumentasi openapi untuk route order
+func describeOrderRoutes() {
+	userId := openapi.ParamsOf(dto.OrderParams{})[:1]
+	ok := openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{})
+	badRequest := openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{})
+	unauthorized := openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{})
+	forbidden := openapi.JSONResponse(http.StatusForbidden, "only the user or an admin", dto.ApiResponse{})
+	notFound := openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{})
+	conflict := openapi.JSONResponse(http.StatusConflict, "status transition not allowed", dto.ApiResponse{})
+
+	ApiDocs.Describe(http.MethodPost, "/user/:userId/order", openapi.Operation{
+		Summary:    "create order",
+		Tags:       []string{"order"},
+		Parameters: userId,
+		Request:    dto.CreateOrder{},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusCreated, "", dto.ApiResponse{}),
+			badRequest, unauthorized, forbidden,
+			openapi.JSONResponse(http.StatusNotFound, "user not found", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/user/:userId/order", openapi.Operation{
+		Summary:    "list orders of user",
+		Tags:       []string{"order"},
+		Parameters: openapi.ParamsOf(dto.OrderQuery{}),
+		Responses:  []openapi.Response{ok, badRequest, unauthorized, forbidden},
+	})
+	ApiDocs.Describe(http.MethodGet, "/user/:userId/order/:orderId", openapi.Operation{
+		Summary:    "get order of user",
+		Tags:       []string{"order"},
+		Parameters: openapi.ParamsOf(dto.OrderParams{}),
+		Responses:  []openapi.Response{ok, badRequest, unauthorized, forbidden, notFound},
+	})
+	ApiDocs.Describe(http.MethodPatch, "/user/:userId/order/:orderId", openapi.Operation{
+		Summary:     "update order status",
+		Description: "only admins; pending -> paid | cancelled, paid -> shipped | cancelled, shipped -> delivered",
+		Tags:        []string{"order"},
+		Parameters:  openapi.ParamsOf(dto.OrderParams{}),
+		Request:     dto.UpdateOrderStatus{},
+		Responses:   []openapi.Response{ok, badRequest, unauthorized, forbidden, notFound, conflict},
+	})
+	ApiDocs.Describe(http.MethodPost, "/user/:userId/order/:orderId/cancel", openapi.Operation{
+		Summary:     "cancel order",
+		Description: "only pending and paid orders can be cancelled",
+		Tags:        []string{"order"},
+		Parameters:  openapi.ParamsOf(dto.OrderParams{}),
+		Responses:   []openapi.Response{ok, badRequest, unauthorized, forbidden, notFound, conflict},
+	})
+}
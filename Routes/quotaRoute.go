@@ -0,0 +1,30 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing kuota storage user
+func NewQuotaRoutes(app *fiber.App, quotaHandler *handler.QuotaHandler) {
+	app.Get("/me/quota", middleware.RequireLogin, quotaHandler.Me)
+
+	describeQuotaRoutes()
+}
+
+// dokumentasi openapi untuk route kuota
+func describeQuotaRoutes() {
+	ApiDocs.Describe(http.MethodGet, "/me/quota", openapi.Operation{
+		Summary:     "storage used by the current user",
+		Description: "limit and remaining are 0 when the user quota is unlimited",
+		Tags:        []string{"file"},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{}),
+		},
+	})
+}
@@ -0,0 +1,83 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing link share, download lewat /shared diverifikasi signedLink
+func NewShareRoutes(app *fiber.App, shareHandler *handler.ShareHandler, fileHandler *handler.FileHandler, signedLink *middleware.SignedLinkMiddleware) {
+	app.Post("/files/:id/share", middleware.RequireLogin, shareHandler.Create)
+	app.Get("/files/:id/share", middleware.RequireLogin, shareHandler.List)
+	app.Delete("/files/:id/share/:linkId", middleware.RequireLogin, shareHandler.Revoke)
+	app.Get("/shared/:linkId", signedLink.Handle, fileHandler.Shared)
+
+	describeShareRoutes()
+}
+
+// dokumentasi openapi untuk route link share
+func describeShareRoutes() {
+	id := openapi.Parameter{Name: "id", In: "path", Description: "file id", Required: true}
+	linkId := openapi.Parameter{Name: "linkId", In: "path", Description: "share link id", Required: true}
+	unauthorized := openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{})
+
+	ApiDocs.Describe(http.MethodPost, "/files/:id/share", openapi.Operation{
+		Summary:     "create signed share link",
+		Description: "expires_in is in seconds and defaults to sharing.default_expiry; a link with ip can only be used from that ip",
+		Tags:        []string{"share"},
+		Parameters:  []openapi.Parameter{id},
+		Request:     dto.ShareRequest{},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusCreated, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only the owner or an admin can share the file", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/files/:id/share", openapi.Operation{
+		Summary:    "list share links with download counts",
+		Tags:       []string{"share"},
+		Parameters: []openapi.Parameter{id},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodDelete, "/files/:id/share/:linkId", openapi.Operation{
+		Summary:    "revoke share link",
+		Tags:       []string{"share"},
+		Parameters: []openapi.Parameter{id, linkId},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/shared/:linkId", openapi.Operation{
+		Summary: "download file through a signed share link",
+		Tags:    []string{"share"},
+		Parameters: []openapi.Parameter{
+			linkId,
+			{Name: "expires", In: "query", Description: "unix expiry time", Required: true, Type: int64(0)},
+			{Name: "signature", In: "query", Description: "link signature", Required: true},
+			{Name: "ip", In: "query", Description: "1 for links bound to the client ip"},
+			{Name: "Range", In: "header", Description: "single byte range, every request counts as a download"},
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusOK, ContentType: "application/octet-stream"},
+			{Status: http.StatusPartialContent, Description: "requested byte range", ContentType: "application/octet-stream"},
+			openapi.JSONResponse(http.StatusForbidden, "invalid signature or file quarantined", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusGone, "link expired, revoked or already used", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestedRangeNotSatisfiable, "", dto.ApiResponse{}),
+		},
+	})
+}
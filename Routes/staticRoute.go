@@ -0,0 +1,14 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+)
+
+// membuat routing untuk static file, setiap mount didefinisikan di config "static.mounts"
+func NewStaticRoutes(app *fiber.App, mounts ...handler.StaticMount) {
+	for _, mount := range mounts {
+		handler := handler.NewStaticHandler(mount)
+		app.Use(handler.Mount.Prefix, handler.Serve)
+	}
+}
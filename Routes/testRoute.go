@@ -4,12 +4,19 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"go_fiber/render"
 	"net/http"
 )
 
 func NewTestRoutes(app *fiber.App, validate *validator.Validate) {
-	handler := handler.NewTestHandler(validate)
+	NewTestHandlerRoutes(app, handler.NewTestHandler(validate))
+}
 
+// sama dengan NewTestRoutes, dengan handler yang sudah dikonfigurasi (storage & pipeline upload)
+func NewTestHandlerRoutes(app *fiber.App, handler *handler.TestHandler) {
 	app.Get("/", func(ctx *fiber.Ctx) error {
 		return ctx.JSON(map[string]any{
 			"status_code": http.StatusOK,
@@ -19,10 +26,11 @@ func NewTestRoutes(app *fiber.App, validate *validator.Validate) {
 
 	app.Get("/hello", handler.Hello)
 	app.Get("/request", handler.RequestHandler)
-	app.Get("/user/:userId/order/:orderId", handler.RouteParameterHandler)
 	app.Get("/hello-form", handler.RequestFormHandler)
-	app.Post("/upload-file", handler.MultiPartFormHandler)
+	app.Post("/upload-file", middleware.RequireLogin, handler.MultiPartFormHandler)
+	app.Get("/login", handler.LoginPage)
 	app.Post("/login", handler.RequestBodyHandler)
+	app.Get("/register", handler.RegisterPage)
 	app.Post("/register", handler.RegisterUserBodyParser)
 	app.Get("/response-json", handler.ResponseJsonHandler)
 	app.Get("/download", handler.DownloadFile)
@@ -34,7 +42,115 @@ func NewTestRoutes(app *fiber.App, validate *validator.Validate) {
 	hello := app.Group("/hello")
 	hello.Get("/test", handler.RoutingGroup)
 
-	// membuat routing untuk static file
-	app.Static("/public", "C:\\Users\\HP\\Documents\\go\\src\\go_fiber\\multipart\\source")
+	describeTestRoutes()
+}
+
+// dokumentasi openapi untuk route di atas
+func describeTestRoutes() {
+	message := map[string]any{}
+	bodyContentTypes := []string{
+		fiber.MIMEApplicationJSON, fiber.MIMEApplicationXML, fiber.MIMEApplicationForm,
+		render.MIMEApplicationMsgPack, render.MIMEApplicationCBOR, render.MIMEApplicationProtobuf,
+	}
+	html := openapi.Response{Status: http.StatusOK, ContentType: fiber.MIMETextHTML}
 
+	ApiDocs.Describe(http.MethodGet, "/", openapi.Operation{
+		Summary:   "health check",
+		Tags:      []string{"test"},
+		Responses: []openapi.Response{openapi.JSONResponse(http.StatusOK, "", message)},
+	})
+	ApiDocs.Describe(http.MethodGet, "/hello", openapi.Operation{
+		Summary:    "say hello",
+		Tags:       []string{"test"},
+		Parameters: []openapi.Parameter{openapi.QueryParam("name", "default guest", "")},
+		Responses:  []openapi.Response{openapi.JSONResponse(http.StatusOK, "", message)},
+	})
+	ApiDocs.Describe(http.MethodGet, "/request", openapi.Operation{
+		Summary: "say hello from header and cookie",
+		Tags:    []string{"test"},
+		Parameters: []openapi.Parameter{
+			{Name: "firstname", In: "header", Description: "default this", Type: ""},
+			{Name: "lastname", In: "cookie", Description: "default guest", Type: ""},
+		},
+		Responses: []openapi.Response{openapi.JSONResponse(http.StatusOK, "", message)},
+	})
+	ApiDocs.Describe(http.MethodGet, "/hello-form", openapi.Operation{
+		Summary:   "say hello from form value",
+		Tags:      []string{"test"},
+		Responses: []openapi.Response{openapi.JSONResponse(http.StatusOK, "", message)},
+	})
+	ApiDocs.Describe(http.MethodPost, "/upload-file", openapi.Operation{
+		Summary: "upload file",
+		Tags:    []string{"file"},
+		Request: struct {
+			File []byte `json:"file" validate:"required"`
+		}{},
+		RequestContentTypes: []string{fiber.MIMEMultipartForm},
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestEntityTooLarge, "body too large or user storage quota exceeded", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusUnprocessableEntity, "file rejected, e.g. infected or image too large", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusInternalServerError, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusServiceUnavailable, "malware scanner unavailable", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusInsufficientStorage, "server storage quota exceeded", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/login", openapi.Operation{
+		Summary:   "login page",
+		Tags:      []string{"view"},
+		Responses: []openapi.Response{html},
+	})
+	ApiDocs.Describe(http.MethodGet, "/register", openapi.Operation{
+		Summary:   "register page",
+		Tags:      []string{"view"},
+		Responses: []openapi.Response{html},
+	})
+	ApiDocs.Describe(http.MethodPost, "/login", openapi.Operation{
+		Summary:             "login",
+		Tags:                []string{"auth"},
+		Request:             dto.LoginRequest{},
+		RequestContentTypes: bodyContentTypes,
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			{Status: http.StatusSeeOther, Description: "html form submitted, redirect with flash message"},
+		},
+	})
+	ApiDocs.Describe(http.MethodPost, "/register", openapi.Operation{
+		Summary:             "register user",
+		Tags:                []string{"auth"},
+		Request:             dto.RegisterUser{},
+		RequestContentTypes: bodyContentTypes,
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusConflict, "username is already taken", dto.ApiResponse{}),
+			{Status: http.StatusSeeOther, Description: "html form submitted, redirect with flash message"},
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/response-json", openapi.Operation{
+		Summary:    "response json",
+		Tags:       []string{"test"},
+		Parameters: []openapi.Parameter{openapi.QueryParam("name", "default guest", "")},
+		Responses:  []openapi.Response{openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{})},
+	})
+	ApiDocs.Describe(http.MethodGet, "/download", openapi.Operation{
+		Summary:   "download file",
+		Tags:      []string{"file"},
+		Responses: []openapi.Response{{Status: http.StatusOK, ContentType: fiber.MIMEOctetStream}},
+	})
+	for _, path := range []string{"/v1/test", "/hello/test"} {
+		ApiDocs.Describe(http.MethodGet, path, openapi.Operation{
+			Summary:   "routing group",
+			Tags:      []string{"test"},
+			Responses: []openapi.Response{openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{})},
+		})
+	}
+	ApiDocs.Describe(http.MethodGet, "/v1/view", openapi.Operation{
+		Summary:   "render mustache view",
+		Tags:      []string{"view"},
+		Responses: []openapi.Response{html},
+	})
 }
@@ -0,0 +1,76 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing upload resumable dengan protokol tus
+func NewTusRoutes(app *fiber.App, tusHandler *handler.TusHandler) {
+	app.Use("/uploads", tusHandler.RequireResumable)
+	app.Options("/uploads", tusHandler.Options)
+	app.Post("/uploads", middleware.RequireLogin, tusHandler.Create)
+	app.Options("/uploads/:id", tusHandler.Options)
+	app.Head("/uploads/:id", middleware.RequireLogin, tusHandler.Head)
+	app.Patch("/uploads/:id", middleware.RequireLogin, tusHandler.Patch)
+	app.Delete("/uploads/:id", middleware.RequireLogin, tusHandler.Terminate)
+
+	describeTusRoutes()
+}
+
+// dokumentasi openapi untuk route tus
+func describeTusRoutes() {
+	// tidak ditandai required agar request tanpa header tetap dijawab 412 oleh handler, bukan 400 validator
+	tusResumable := openapi.Parameter{Name: "Tus-Resumable", In: "header", Description: "tus protocol version, must be 1.0.0"}
+	id := openapi.Parameter{Name: "id", In: "path", Description: "upload id", Required: true}
+	noContent := openapi.Response{Status: http.StatusNoContent}
+	unauthorized := openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{})
+	notFound := openapi.JSONResponse(http.StatusNotFound, "unknown upload or upload of another user", dto.ApiResponse{})
+
+	ApiDocs.Describe(http.MethodPost, "/uploads", openapi.Operation{
+		Summary: "create resumable upload",
+		Tags:    []string{"upload"},
+		Parameters: []openapi.Parameter{
+			tusResumable,
+			{Name: "Upload-Length", In: "header", Description: "total size in bytes", Required: true, Validate: "min=0"},
+			{Name: "Upload-Metadata", In: "header", Description: "comma separated key and base64 value pairs, filename and filetype are used"},
+		},
+		Responses: []openapi.Response{
+			{Status: http.StatusCreated, Description: "upload created, Location points to the upload"},
+			unauthorized,
+			openapi.JSONResponse(http.StatusRequestEntityTooLarge, "upload exceeds Tus-Max-Size or user storage quota", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusInsufficientStorage, "server storage quota exceeded", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodPatch, "/uploads/:id", openapi.Operation{
+		Summary: "upload chunk at Upload-Offset",
+		Tags:    []string{"upload"},
+		Parameters: []openapi.Parameter{
+			id, tusResumable,
+			{Name: "Upload-Offset", In: "header", Description: "offset of this chunk", Required: true, Validate: "min=0"},
+			{Name: "Upload-Checksum", In: "header", Description: "algorithm and base64 checksum of this chunk, e.g. sha1 <base64>"},
+		},
+		Responses: []openapi.Response{
+			noContent,
+			unauthorized,
+			notFound,
+			openapi.JSONResponse(http.StatusConflict, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusGone, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusRequestEntityTooLarge, "chunk exceeds Upload-Length or user storage quota", dto.ApiResponse{}),
+			openapi.JSONResponse(handler.StatusChecksumMismatch, "checksum mismatch", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusUnprocessableEntity, "completed file rejected, e.g. infected", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusServiceUnavailable, "malware scanner unavailable", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusInsufficientStorage, "server storage quota exceeded", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodDelete, "/uploads/:id", openapi.Operation{
+		Summary:    "terminate upload",
+		Tags:       []string{"upload"},
+		Parameters: []openapi.Parameter{id, tusResumable},
+		Responses:  []openapi.Response{noContent, unauthorized, notFound},
+	})
+}
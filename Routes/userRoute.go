@@ -0,0 +1,92 @@
+package Routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/handler"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"net/http"
+)
+
+// routing user, login dengan http basic auth dari user yang terdaftar lewat /register
+func NewUserRoutes(app *fiber.App, userHandler *handler.UserHandler) {
+	app.Get("/me", userHandler.Me)
+	app.Get("/users", userHandler.List)
+	app.Get("/users/:id", userHandler.Get)
+	app.Patch("/users/:id", userHandler.Update)
+	app.Delete("/users/:id", userHandler.Delete)
+	app.Post("/users/:id/restore", userHandler.Restore)
+
+	describeUserRoutes()
+}
+
+// dokumentasi openapi untuk route user
+func describeUserRoutes() {
+	id := openapi.Parameter{Name: "id", In: "path", Description: "user id", Required: true}
+	ok := openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{})
+	unauthorized := openapi.JSONResponse(http.StatusUnauthorized, "login with http basic auth required", dto.ApiResponse{})
+	notFound := openapi.JSONResponse(http.StatusNotFound, "", dto.ApiResponse{})
+
+	ApiDocs.Describe(http.MethodGet, "/me", openapi.Operation{
+		Summary:   "current user",
+		Tags:      []string{"user"},
+		Responses: []openapi.Response{ok, unauthorized},
+	})
+	ApiDocs.Describe(http.MethodGet, "/users", openapi.Operation{
+		Summary:    "list users",
+		Tags:       []string{"user"},
+		Parameters: openapi.ParamsOf(dto.UserQuery{}),
+		Responses: []openapi.Response{
+			openapi.JSONResponse(http.StatusOK, "", dto.ApiResponse{}),
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only admins can list users", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodGet, "/users/:id", openapi.Operation{
+		Summary:    "get user",
+		Tags:       []string{"user"},
+		Parameters: []openapi.Parameter{id},
+		Responses: []openapi.Response{
+			ok, unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only the user or an admin", dto.ApiResponse{}),
+			notFound,
+		},
+	})
+	ApiDocs.Describe(http.MethodPatch, "/users/:id", openapi.Operation{
+		Summary:     "update user",
+		Description: "fields that are not sent are not changed; only admins can change the role",
+		Tags:        []string{"user"},
+		Parameters:  []openapi.Parameter{id},
+		Request:     dto.UpdateUser{},
+		Responses: []openapi.Response{
+			ok,
+			openapi.JSONResponse(http.StatusBadRequest, "", dto.ApiResponse{}),
+			unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only the user or an admin", dto.ApiResponse{}),
+			notFound,
+			openapi.JSONResponse(http.StatusConflict, "username is already taken", dto.ApiResponse{}),
+		},
+	})
+	ApiDocs.Describe(http.MethodDelete, "/users/:id", openapi.Operation{
+		Summary:     "soft delete user",
+		Description: "a deleted user cannot log in until an admin restores it",
+		Tags:        []string{"user"},
+		Parameters:  []openapi.Parameter{id},
+		Responses: []openapi.Response{
+			ok, unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only the user or an admin", dto.ApiResponse{}),
+			notFound,
+		},
+	})
+	ApiDocs.Describe(http.MethodPost, "/users/:id/restore", openapi.Operation{
+		Summary:    "restore soft deleted user",
+		Tags:       []string{"user"},
+		Parameters: []openapi.Parameter{id},
+		Responses: []openapi.Response{
+			ok, unauthorized,
+			openapi.JSONResponse(http.StatusForbidden, "only admins can restore users", dto.ApiResponse{}),
+			notFound,
+		},
+	})
+}
@@ -0,0 +1,166 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+var ErrUnknownFormat = errors.New("unknown archive format")
+
+// Config is loaded from the "archive" section of config.json.
+type Config struct {
+	MaxSize int64 `mapstructure:"max_size"`
+}
+
+// Writer writes entries of one archive straight to the underlying writer, nothing is
+// buffered besides the current compression window.
+type Writer interface {
+	// Add writes one file, size must be the exact number of bytes in reader
+	Add(name string, size int64, modTime time.Time, contentType string, reader io.Reader) error
+	Close() error
+}
+
+// function provider
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatZip, "":
+		return &zipWriter{writer: zip.NewWriter(w)}, nil
+	case FormatTarGz:
+		compressed := gzip.NewWriter(w)
+		return &tarWriter{gzip: compressed, writer: tar.NewWriter(compressed)}, nil
+	}
+	return nil, fmt.Errorf("%w [%v]", ErrUnknownFormat, format)
+}
+
+// content type & extension per format untuk response
+func ContentType(format string) string {
+	if format == FormatTarGz {
+		return "application/gzip"
+	}
+	return "application/zip"
+}
+
+func Extension(format string) string {
+	if format == FormatTarGz {
+		return ".tar.gz"
+	}
+	return ".zip"
+}
+
+type zipWriter struct {
+	writer *zip.Writer
+}
+
+func (z *zipWriter) Add(name string, size int64, modTime time.Time, contentType string, reader io.Reader) error {
+	// file yang sudah terkompresi tidak dikompresi ulang
+	method := zip.Deflate
+	if compressed(contentType) {
+		method = zip.Store
+	}
+
+	entry, err := z.writer.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   method,
+		Modified: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	written, err := io.Copy(entry, reader)
+	if err == nil && written != size {
+		err = fmt.Errorf("file %v changed size while archiving, %v of %v bytes", name, written, size)
+	}
+	return err
+}
+
+func (z *zipWriter) Close() error {
+	return z.writer.Close()
+}
+
+type tarWriter struct {
+	gzip   *gzip.Writer
+	writer *tar.Writer
+}
+
+func (t *tarWriter) Add(name string, size int64, modTime time.Time, contentType string, reader io.Reader) error {
+	err := t.writer.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  modTime,
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+
+	// tar menolak data melebihi size, data kurang dari size terdeteksi dari jumlah byte
+	written, err := io.Copy(t.writer, reader)
+	if err == nil && written != size {
+		err = fmt.Errorf("file %v changed size while archiving, %v of %v bytes", name, written, size)
+	}
+	return err
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.writer.Close(); err != nil {
+		return err
+	}
+	return t.gzip.Close()
+}
+
+func compressed(contentType string) bool {
+	switch {
+	case strings.HasPrefix(contentType, "image/"), strings.HasPrefix(contentType, "video/"), strings.HasPrefix(contentType, "audio/"):
+		return contentType != "image/svg+xml" && contentType != "image/bmp"
+	}
+	switch contentType {
+	case "application/zip", "application/gzip", "application/x-gzip", "application/x-7z-compressed", "application/pdf":
+		return true
+	}
+	return false
+}
+
+// Names gives every file a unique, relative path inside an archive.
+type Names struct {
+	used map[string]bool
+}
+
+// function provider
+func NewNames() *Names {
+	return &Names{used: map[string]bool{}}
+}
+
+// Unique cleans name so it cannot escape the archive root ("../", absolute paths,
+// backslashes) and appends " (n)" before the extension when it was used before.
+func (n *Names) Unique(name string, fallback string) string {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	if cleaned == "" {
+		cleaned = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(fallback, "\\", "/")), "/")
+	}
+	if cleaned == "" {
+		cleaned = "file"
+	}
+
+	unique := cleaned
+	extension := path.Ext(cleaned)
+	base := strings.TrimSuffix(cleaned, extension)
+	for i := 1; n.used[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%v (%v)%v", base, i, extension)
+	}
+	n.used[strings.ToLower(unique)] = true
+	return unique
+}
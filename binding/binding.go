@@ -0,0 +1,280 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go_fiber/model/dto"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sumber value, urutan ini juga urutan tag yang dicek di setiap field
+var sources = []string{"param", "query", "header", "cookie"}
+
+// nama lokasi mengikuti openapi
+var locations = map[string]string{
+	"param":  "path",
+	"query":  "query",
+	"header": "header",
+	"cookie": "cookie",
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	uuidType          = reflect.TypeOf(uuid.UUID{})
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Errors is returned by Bind, it lists every invalid parameter instead of stopping at the first
+type Errors struct {
+	Fields []dto.FieldError
+}
+
+func (e *Errors) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, fmt.Sprintf("%v [%v] %v", field.In, field.Field, field.Message))
+	}
+	return strings.Join(messages, ", ")
+}
+
+type boundField struct {
+	in   string
+	name string
+}
+
+// Bind parses path, query, header and cookie values into dst using the param, query,
+// header and cookie struct tags, then runs the validate tags.
+//
+//	type OrderParams struct {
+//		UserId int `param:"userId" validate:"gt=0"`
+//	}
+//
+// Optional tags: default:"value" for missing values, layout:"2006-01-02" for time.Time.
+// Slices accept repeated query keys or comma separated values.
+func Bind(ctx *fiber.Ctx, validate *validator.Validate, dst any) error {
+	value := reflect.ValueOf(dst)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("binding: dst must be a pointer to struct, got %T", dst)
+	}
+	value = value.Elem()
+
+	errors := &Errors{}
+	fields := map[string]boundField{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		source, name := fieldSource(field)
+		if source == "" || !field.IsExported() {
+			continue
+		}
+
+		in := locations[source]
+		fields[field.Name] = boundField{in: in, name: name}
+
+		raw := rawValues(ctx, source, name)
+		if len(raw) == 0 {
+			if fallback, ok := field.Tag.Lookup("default"); ok {
+				raw = []string{fallback}
+			} else {
+				continue
+			}
+		}
+
+		if err := setField(value.Field(i), field, raw); err != nil {
+			errors.Fields = append(errors.Fields, dto.FieldError{In: in, Field: name, Message: err.Error()})
+		}
+	}
+
+	// field yang gagal di-parse tidak perlu divalidasi lagi
+	if validate != nil {
+		if err := validate.StructCtx(ctx.UserContext(), dst); err != nil {
+			validationErrors, ok := err.(validator.ValidationErrors)
+			if !ok {
+				return err
+			}
+			for _, fieldError := range validationErrors {
+				bound := fields[fieldError.StructField()]
+				if bound.name == "" || hasField(errors.Fields, bound.name) {
+					continue
+				}
+				errors.Fields = append(errors.Fields, dto.FieldError{
+					In:      bound.in,
+					Field:   bound.name,
+					Message: ValidationMessage(fieldError),
+				})
+			}
+		}
+	}
+
+	if len(errors.Fields) > 0 {
+		return errors
+	}
+	return nil
+}
+
+func fieldSource(field reflect.StructField) (string, string) {
+	for _, source := range sources {
+		if name := field.Tag.Get(source); name != "" {
+			return source, name
+		}
+	}
+	return "", ""
+}
+
+func rawValues(ctx *fiber.Ctx, source, name string) []string {
+	var values []string
+	switch source {
+	case "param":
+		values = []string{ctx.Params(name)}
+	case "query":
+		ctx.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			if string(key) == name {
+				values = append(values, string(value))
+			}
+		})
+	case "header":
+		values = []string{ctx.Get(name)}
+	case "cookie":
+		values = []string{ctx.Cookies(name)}
+	}
+
+	// buang value kosong, dianggap tidak dikirim
+	result := values[:0]
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func setField(value reflect.Value, field reflect.StructField, raw []string) error {
+	if value.Kind() == reflect.Slice && value.Type().Elem().Kind() != reflect.Uint8 {
+		// a,b,c atau ?id=a&id=b
+		var parts []string
+		for _, item := range raw {
+			parts = append(parts, strings.Split(item, ",")...)
+		}
+
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setScalar(slice.Index(i), field, strings.TrimSpace(part)); err != nil {
+				return fmt.Errorf("item %d %v", i, err)
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+
+	return setScalar(value, field, raw[0])
+}
+
+func setScalar(value reflect.Value, field reflect.StructField, raw string) error {
+	if value.Kind() == reflect.Pointer {
+		pointer := reflect.New(value.Type().Elem())
+		if err := setScalar(pointer.Elem(), field, raw); err != nil {
+			return err
+		}
+		value.Set(pointer)
+		return nil
+	}
+
+	switch value.Type() {
+	case timeType:
+		layout := field.Tag.Get("layout")
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		parsed, err := time.Parse(layout, raw)
+		if err != nil {
+			return fmt.Errorf("must be a time in format %v", layout)
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	case uuidType:
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("must be a valid uuid")
+		}
+		value.Set(reflect.ValueOf(parsed))
+		return nil
+	}
+
+	// tipe enum custom cukup implement encoding.TextUnmarshaler
+	if value.Addr().Type().Implements(textUnmarshalType) {
+		if err := value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		value.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(raw, 10, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive integer")
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(raw, value.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		value.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported type %v", value.Type())
+	}
+	return nil
+}
+
+func hasField(fields []dto.FieldError, name string) bool {
+	for _, field := range fields {
+		if field.Field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pesan validasi yang bisa dibaca client, tag lain fallback ke nama tag
+func ValidationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "gt":
+		return "must be greater than " + fieldError.Param()
+	case "gte", "min":
+		if fieldError.Kind() == reflect.String {
+			return "must be at least " + fieldError.Param() + " characters"
+		}
+		return "must be at least " + fieldError.Param()
+	case "lt":
+		return "must be less than " + fieldError.Param()
+	case "lte", "max":
+		return "must be at most " + fieldError.Param()
+	case "oneof":
+		return "must be one of [" + fieldError.Param() + "]"
+	}
+	return fmt.Sprintf("failed on tag [%v]", fieldError.Tag())
+}
@@ -0,0 +1,45 @@
+package catalogue
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrNotFound = errors.New("file not found in catalogue")
+
+// Entry is the metadata recorded for every completed upload. ID is also the name of
+// the file in storage.
+type Entry struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Owner       string    `json:"owner"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+	Tags        []string  `json:"tags"`
+	Variants    []string  `json:"variants,omitempty"`
+	VariantSize int64     `json:"variant_size,omitempty"`
+}
+
+// Query filters, sorts and paginates List. Zero values do not filter. Type is either a
+// full content type ("image/png") or only its top-level type ("image").
+type Query struct {
+	Owner   string
+	Type    string
+	From    time.Time
+	To      time.Time
+	Sort    string // field dengan prefix "-" untuk urutan menurun, contoh "-created_at"
+	Page    int
+	PerPage int
+}
+
+// Store keeps the catalogue entries.
+type Store interface {
+	Save(ctx context.Context, entry Entry) error
+	Get(ctx context.Context, id string) (Entry, error)
+	Delete(ctx context.Context, id string) error
+	// List returns one page of the matching entries and the number of all matching entries
+	List(ctx context.Context, query Query) ([]Entry, int, error)
+}
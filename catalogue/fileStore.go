@@ -0,0 +1,249 @@
+package catalogue
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FileStore keeps one JSON document per entry in a directory, so every prefork process
+// sees the same catalogue without a database.
+//
+// An index under owners/ holds an empty file per entry of every owner, so listing the
+// files of one owner only reads their entries. Listing without an owner (admins) still
+// reads every entry; filters other than the owner, sorting and pagination are done in
+// memory, which is fine for tens of thousands of entries but not more.
+type FileStore struct {
+	directory string
+}
+
+// function provider
+func NewFileStore(directory string) *FileStore {
+	return &FileStore{
+		directory: directory,
+	}
+}
+
+func (f *FileStore) Save(ctx context.Context, entry Entry) error {
+	if err := os.MkdirAll(f.directory, 0o755); err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// tulis ke file sementara lalu rename, supaya List tidak membaca entry setengah jadi
+	temp, err := os.CreateTemp(f.directory, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(encoded)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), f.path(entry.ID)); err != nil {
+		return err
+	}
+	return f.index(entry)
+}
+
+// tandai entry di index owner, file tanpa owner hanya muncul di list semua owner
+func (f *FileStore) index(entry Entry) error {
+	if entry.Owner == "" {
+		return nil
+	}
+	directory := f.ownerPath(entry.Owner)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(directory, filepath.Base(f.path(entry.ID))), nil, 0o644)
+}
+
+func (f *FileStore) Get(ctx context.Context, id string) (Entry, error) {
+	encoded, err := os.ReadFile(f.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Entry{}, ErrNotFound
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var entry Entry
+	err = json.Unmarshal(encoded, &entry)
+	return entry, err
+}
+
+func (f *FileStore) Delete(ctx context.Context, id string) error {
+	entry, err := f.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(f.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// penanda yang tertinggal tidak masalah, List tetap mencocokkan owner entry
+	if entry.Owner != "" {
+		os.Remove(filepath.Join(f.ownerPath(entry.Owner), filepath.Base(f.path(id))))
+	}
+	return nil
+}
+
+func (f *FileStore) List(ctx context.Context, query Query) ([]Entry, int, error) {
+	directory := f.directory
+	if query.Owner != "" {
+		if err := f.buildIndex(ctx); err != nil {
+			return nil, 0, err
+		}
+		directory = f.ownerPath(query.Owner)
+	}
+
+	files, err := os.ReadDir(directory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return query.Paginate(nil), 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		entry, err := f.Get(ctx, strings.TrimSuffix(file.Name(), ".json"))
+		if errors.Is(err, ErrNotFound) {
+			// dihapus oleh proses lain setelah ReadDir
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if query.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	query.SortEntries(entries)
+	return query.Paginate(entries), len(entries), nil
+}
+
+// catalogue dari sebelum ada index owner di-index sekali dari semua entry. Save yang
+// berjalan bersamaan menulis penandanya sendiri, jadi index tetap lengkap
+func (f *FileStore) buildIndex(ctx context.Context) error {
+	built := filepath.Join(f.directory, "owners", ".built")
+	if _, err := os.Stat(built); err == nil {
+		return nil
+	}
+
+	entries, _, err := f.List(ctx, Query{})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := f.index(entry); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(built), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(built, nil, 0o644)
+}
+
+// nama file entry, id dibersihkan agar tidak bisa keluar dari directory
+func (f *FileStore) path(id string) string {
+	return filepath.Join(f.directory, filepath.Base(filepath.Clean("/"+id))+".json")
+}
+
+// directory index satu owner, dibersihkan seperti id
+func (f *FileStore) ownerPath(owner string) string {
+	return filepath.Join(f.directory, "owners", filepath.Base(filepath.Clean("/"+owner)))
+}
+
+// Matches reports whether entry passes the filters of the query.
+func (q Query) Matches(entry Entry) bool {
+	if q.Owner != "" && entry.Owner != q.Owner {
+		return false
+	}
+	if q.Type != "" {
+		if strings.Contains(q.Type, "/") {
+			if !strings.EqualFold(entry.ContentType, q.Type) {
+				return false
+			}
+		} else if !strings.HasPrefix(strings.ToLower(entry.ContentType), strings.ToLower(q.Type)+"/") {
+			return false
+		}
+	}
+	if !q.From.IsZero() && entry.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !entry.CreatedAt.Before(q.To) {
+		return false
+	}
+	return true
+}
+
+// SortEntries sorts by the Sort field, newest first by default. Ties are ordered by id
+// so pages stay stable.
+func (q Query) SortEntries(entries []Entry) {
+	field, descending := strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+	if q.Sort == "" {
+		field, descending = "created_at", true
+	}
+
+	compare := func(a, b Entry) int {
+		switch field {
+		case "name":
+			return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		case "size":
+			return cmp.Compare(a.Size, b.Size)
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		result := compare(entries[i], entries[j])
+		if descending {
+			result = -result
+		}
+		if result == 0 {
+			return entries[i].ID < entries[j].ID
+		}
+		return result < 0
+	})
+}
+
+// Paginate returns the entries of the requested page, pages start at 1.
+func (q Query) Paginate(entries []Entry) []Entry {
+	if q.PerPage <= 0 {
+		return entries
+	}
+	page := max(q.Page, 1)
+
+	start := (page - 1) * q.PerPage
+	if start >= len(entries) {
+		return []Entry{}
+	}
+	return entries[start:min(start+q.PerPage, len(entries))]
+}
@@ -0,0 +1,48 @@
+package catalogue
+
+import (
+	"context"
+	"errors"
+	"go_fiber/upload"
+	"time"
+)
+
+// Processor records every completed upload in the catalogue. It runs last in the
+// upload pipeline so the entry reflects the final size, checksum and variants.
+type Processor struct {
+	store Store
+}
+
+// function provider
+func NewProcessor(store Store) *Processor {
+	return &Processor{
+		store: store,
+	}
+}
+
+func (p *Processor) Process(ctx context.Context, file *upload.File) error {
+	tags := file.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return p.store.Save(ctx, Entry{
+		ID:          file.ID,
+		Name:        file.Name,
+		Owner:       file.Owner,
+		Size:        file.Size,
+		ContentType: file.ContentType,
+		Checksum:    file.Checksum,
+		CreatedAt:   time.Now().UTC(),
+		Tags:        tags,
+		Variants:    file.Variants,
+		VariantSize: file.VariantSize,
+	})
+}
+
+func (p *Processor) Revert(ctx context.Context, file *upload.File) error {
+	if err := p.store.Delete(ctx, file.ID); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
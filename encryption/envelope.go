@@ -0,0 +1,381 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	ErrNotEncrypted = errors.New("file is not encrypted")
+	ErrCorrupted    = errors.New("encrypted file is corrupted or has been modified")
+)
+
+// Encrypted files start with a fixed size header followed by the content, sealed with
+// AES-256-GCM in segments so any byte range can be decrypted on its own:
+//
+//	magic "FENC" | version | key id length | key id (32) | segment size | wrapped data key (60)
+//	segment 0 | segment 1 | ... | final segment
+//
+// Every file has its own random data key, wrapped with a master key; everything in the
+// header before the wrapped key is authenticated with it. The header has a fixed size so
+// rotating the master key only rewrites the header in place. Version 1 headers, with the
+// unauthenticated segment size after the wrapped key, are still read and are rewritten
+// as version 2 when the master key is rotated.
+const (
+	magic              = "FENC"
+	version            = 2
+	legacyVersion      = 1
+	wrappedSize        = 12 + 32 + 16
+	headerSize         = len(magic) + 1 + 1 + maxKeyIDSize + 4 + wrappedSize
+	defaultSegmentSize = 64 * 1024
+	maxSegmentSize     = 1 << 20
+	segmentTagSize     = 16
+
+	// HeaderSize is the size of the header at the start of every encrypted file.
+	HeaderSize = headerSize
+)
+
+// Encrypt returns a writer that encrypts everything written to it into w with a new
+// data key. Close must be called to write the final segment.
+func (k *Keyring) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	header, err := newHeader(k.current, dataKey, defaultSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &writer{
+		out:    w,
+		aead:   aead,
+		buffer: make([]byte, 0, defaultSegmentSize),
+	}, nil
+}
+
+// Decrypt returns a reader of the plain content of r with Seek support. Content that
+// has no encryption header returns ErrNotEncrypted.
+func (k *Keyring) Decrypt(r io.ReadSeeker) (io.ReadSeeker, error) {
+	header := make([]byte, headerSize)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, ErrNotEncrypted
+		}
+		return nil, err
+	}
+
+	dataKey, segmentSize, err := k.unwrap(header)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// ukuran konten dihitung dari ukuran file, setiap segment menambah tag gcm
+	total, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	sealed := int64(segmentSize) + segmentTagSize
+	content := total - int64(headerSize)
+	segments := (content + sealed - 1) / sealed
+	if segments == 0 || content-(segments-1)*sealed < segmentTagSize {
+		return nil, ErrCorrupted
+	}
+
+	return &reader{
+		source:      r,
+		aead:        aead,
+		segmentSize: int64(segmentSize),
+		segments:    segments,
+		size:        content - segments*segmentTagSize,
+		loaded:      -1,
+	}, nil
+}
+
+// Rewrap returns header, the first HeaderSize bytes of an encrypted file, with its data
+// key wrapped with the current master key. Writing it over the old header rotates the
+// master key without touching the content. It returns nil when the file already uses
+// the current master key and header version.
+func (k *Keyring) Rewrap(header []byte) ([]byte, error) {
+	if len(header) < headerSize {
+		return nil, ErrNotEncrypted
+	}
+	header = header[:headerSize]
+
+	parsed, err := parseHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.keyID == k.current.ID && parsed.version == version {
+		return nil, nil
+	}
+
+	dataKey, segmentSize, err := k.unwrap(header)
+	if err != nil {
+		return nil, err
+	}
+	return newHeader(k.current, dataKey, segmentSize)
+}
+
+// header dengan data key yang di-wrap master key, id key & ukuran segment ikut diautentikasi
+func newHeader(key Key, dataKey []byte, segmentSize uint32) ([]byte, error) {
+	header := make([]byte, 0, headerSize)
+	header = append(header, magic...)
+	header = append(header, version, byte(len(key.ID)))
+	header = append(header, key.ID...)
+	header = append(header, make([]byte, maxKeyIDSize-len(key.ID))...)
+	header = binary.BigEndian.AppendUint32(header, segmentSize)
+
+	aead, err := newGCM(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	wrapped := aead.Seal(nonce, nonce, dataKey, header)
+
+	return append(header, wrapped...), nil
+}
+
+// isi header, authenticated adalah byte yang diautentikasi bersama data key
+type parsedHeader struct {
+	version       byte
+	keyID         string
+	segmentSize   uint32
+	wrapped       []byte
+	authenticated []byte
+}
+
+func parseHeader(header []byte) (parsedHeader, error) {
+	if string(header[:len(magic)]) != magic {
+		return parsedHeader{}, ErrNotEncrypted
+	}
+	position := len(magic)
+	parsed := parsedHeader{version: header[position]}
+	if parsed.version != version && parsed.version != legacyVersion {
+		return parsedHeader{}, fmt.Errorf("%w : unsupported version %v", ErrCorrupted, parsed.version)
+	}
+	idSize := int(header[position+1])
+	if idSize == 0 || idSize > maxKeyIDSize {
+		return parsedHeader{}, ErrCorrupted
+	}
+	position += 2
+	parsed.keyID = string(header[position : position+idSize])
+	position += maxKeyIDSize
+
+	// versi 1 menyimpan ukuran segment setelah data key, di luar byte yang diautentikasi
+	if parsed.version == legacyVersion {
+		parsed.authenticated = header[:position]
+		parsed.wrapped = header[position : position+wrappedSize]
+		parsed.segmentSize = binary.BigEndian.Uint32(header[position+wrappedSize:])
+	} else {
+		parsed.segmentSize = binary.BigEndian.Uint32(header[position:])
+		position += 4
+		parsed.authenticated = header[:position]
+		parsed.wrapped = header[position : position+wrappedSize]
+	}
+
+	// ukuran segment menentukan buffer yang dialokasikan saat membaca
+	if parsed.segmentSize == 0 || parsed.segmentSize > maxSegmentSize {
+		return parsedHeader{}, ErrCorrupted
+	}
+	return parsed, nil
+}
+
+func (k *Keyring) unwrap(header []byte) ([]byte, uint32, error) {
+	parsed, err := parseHeader(header)
+	if err != nil {
+		return nil, 0, err
+	}
+	secret, ok := k.keys[parsed.keyID]
+	if !ok {
+		return nil, 0, fmt.Errorf("%w : %q", ErrUnknownKey, parsed.keyID)
+	}
+
+	aead, err := newGCM(secret)
+	if err != nil {
+		return nil, 0, err
+	}
+	nonce, sealed := parsed.wrapped[:aead.NonceSize()], parsed.wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, parsed.authenticated)
+	if err != nil {
+		return nil, 0, ErrCorrupted
+	}
+	return dataKey, parsed.segmentSize, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// nonce segment adalah nomor segment, aman karena setiap file punya data key sendiri.
+// segment terakhir ditandai di additional data supaya file yang terpotong ketahuan
+func sealSegment(aead cipher.AEAD, index int64, content []byte, final bool, destination []byte) []byte {
+	return aead.Seal(destination, segmentNonce(aead, index), content, segmentData(final))
+}
+
+func openSegment(aead cipher.AEAD, index int64, sealed []byte, final bool, destination []byte) ([]byte, error) {
+	plain, err := aead.Open(destination, segmentNonce(aead, index), sealed, segmentData(final))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+	return plain, nil
+}
+
+func segmentNonce(aead cipher.AEAD, index int64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], uint64(index))
+	return nonce
+}
+
+func segmentData(final bool) []byte {
+	if final {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// writer mengenkripsi per segment, segment penuh baru ditulis ketika ada data berikutnya
+// karena segment terakhir harus ditandai final
+type writer struct {
+	out     io.Writer
+	aead    cipher.AEAD
+	buffer  []byte
+	segment int64
+	closed  bool
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(w.buffer) == cap(w.buffer) {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buffer[len(w.buffer):cap(w.buffer)], p)
+		w.buffer = w.buffer[:len(w.buffer)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *writer) seal(final bool) error {
+	_, err := w.out.Write(sealSegment(w.aead, w.segment, w.buffer, final, nil))
+	w.buffer = w.buffer[:0]
+	w.segment++
+	return err
+}
+
+// reader mendekripsi segment yang berisi offset, satu segment disimpan di memory
+type reader struct {
+	source      io.ReadSeeker
+	aead        cipher.AEAD
+	segmentSize int64
+	segments    int64
+	size        int64
+	offset      int64
+	loaded      int64
+	plain       []byte
+	sealed      []byte
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	index := r.offset / r.segmentSize
+	if index != r.loaded {
+		if err := r.load(index); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.plain[r.offset-index*r.segmentSize:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+func (r *reader) load(index int64) error {
+	sealed := r.segmentSize + segmentTagSize
+	if _, err := r.source.Seek(int64(headerSize)+index*sealed, io.SeekStart); err != nil {
+		return err
+	}
+
+	length := sealed
+	if index == r.segments-1 {
+		length = r.size - index*r.segmentSize + segmentTagSize
+	}
+	if int64(cap(r.sealed)) < length {
+		r.sealed = make([]byte, sealed)
+	}
+	r.sealed = r.sealed[:length]
+	if _, err := io.ReadFull(r.source, r.sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrCorrupted
+		}
+		return err
+	}
+
+	plain, err := openSegment(r.aead, index, r.sealed, index == r.segments-1, r.plain[:0])
+	if err != nil {
+		r.loaded = -1
+		return err
+	}
+	r.plain = plain
+	r.loaded = index
+	return nil
+}
+
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	case io.SeekStart:
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.offset = offset
+	return offset, nil
+}
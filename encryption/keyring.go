@@ -0,0 +1,111 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	ErrInvalidKey = errors.New("master key must be 32 bytes, base64 encoded")
+	ErrUnknownKey = errors.New("data key is wrapped by an unknown master key")
+)
+
+// panjang maksimum id master key, id disimpan di header setiap file
+const maxKeyIDSize = 32
+
+// Key is an AES-256 master key. Its ID is stored with every data key it wraps so the
+// right master key is picked on decryption, also after rotation.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// KeyConfig is a master key from config, the key file takes precedence over the key so
+// the key does not have to live in the config file. Both hold the key base64 encoded.
+type KeyConfig struct {
+	ID      string `mapstructure:"id"`
+	Key     string `mapstructure:"key"`
+	KeyFile string `mapstructure:"key_file"`
+}
+
+// Config is loaded from the "encryption" section of config.json. Files are encrypted
+// with the master key, previous keys are only used to decrypt files that have not been
+// rewrapped yet.
+type Config struct {
+	Enabled       bool        `mapstructure:"enabled"`
+	KeyID         string      `mapstructure:"key_id"`
+	MasterKey     string      `mapstructure:"master_key"`
+	MasterKeyFile string      `mapstructure:"master_key_file"`
+	PreviousKeys  []KeyConfig `mapstructure:"previous_keys"`
+}
+
+func (k KeyConfig) Load() (Key, error) {
+	encoded := []byte(k.Key)
+	if k.KeyFile != "" {
+		content, err := os.ReadFile(k.KeyFile)
+		if err != nil {
+			return Key{}, err
+		}
+		encoded = bytes.TrimSpace(content)
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(string(encoded))
+	if err != nil || len(secret) != 32 {
+		return Key{}, fmt.Errorf("%w : key %q", ErrInvalidKey, k.ID)
+	}
+	return Key{ID: k.ID, Secret: secret}, nil
+}
+
+// keyring dari master key & previous keys di config
+func (c Config) Keyring() (*Keyring, error) {
+	current, err := KeyConfig{ID: c.KeyID, Key: c.MasterKey, KeyFile: c.MasterKeyFile}.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []Key
+	for _, config := range c.PreviousKeys {
+		key, err := config.Load()
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return NewKeyring(current, previous...)
+}
+
+// Keyring holds the current master key, which wraps new data keys, and the previous
+// master keys that may still wrap data keys of older files.
+type Keyring struct {
+	current Key
+	keys    map[string][]byte
+}
+
+// function provider
+func NewKeyring(current Key, previous ...Key) (*Keyring, error) {
+	keyring := &Keyring{
+		current: current,
+		keys:    map[string][]byte{},
+	}
+	for _, key := range append([]Key{current}, previous...) {
+		if key.ID == "" || len(key.ID) > maxKeyIDSize {
+			return nil, fmt.Errorf("master key id must be 1 to %v bytes, got %q", maxKeyIDSize, key.ID)
+		}
+		if len(key.Secret) != 32 {
+			return nil, fmt.Errorf("%w : key %q", ErrInvalidKey, key.ID)
+		}
+		if _, exists := keyring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate master key id %q", key.ID)
+		}
+		keyring.keys[key.ID] = key.Secret
+	}
+	return keyring, nil
+}
+
+// id master key yang dipakai untuk file baru
+func (k *Keyring) CurrentID() string {
+	return k.current.ID
+}
@@ -0,0 +1,36 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lock yang tertinggal lebih lama dari ini dianggap milik proses yang sudah mati
+const staleLock = 10 * time.Minute
+
+// Lock takes an exclusive lock on path and blocks until it is free. The returned
+// function releases it.
+//
+// tanpa flock, lock berupa file yang dibuat eksklusif dan dihapus saat dilepas
+func Lock(path string) (func(), error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > staleLock {
+			os.Remove(path)
+			continue
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,24 +1,43 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"go_fiber/binding"
 	"go_fiber/model/dto"
+	"go_fiber/public"
+	"go_fiber/quota"
+	"go_fiber/render"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"go_fiber/users"
+	"go_fiber/view"
 	"net/http"
-	"strconv"
+	"path/filepath"
 	"strings"
+	"time"
 )
 
+// TestHandler serves the test routes. When Users is set /register creates the user,
+// otherwise the registration is only validated. When Quota is set a multipart upload
+// whose Content-Length would exceed it is refused before the body is read.
 type TestHandler struct {
-	Validate *validator.Validate
+	Validate   *validator.Validate
+	Storage    storage.Storage
+	Pipeline   upload.Pipeline
+	Quota      *quota.Checker
+	Users      users.Store
+	UserConfig users.Config
 }
 
 // function Provider
 func NewTestHandler(validate *validator.Validate) *TestHandler {
 	return &TestHandler{
 		Validate: validate,
+		Storage:  storage.NewLocalStorage(filepath.Join("multipart", "target")),
 	}
 }
 
@@ -47,20 +66,6 @@ func (t *TestHandler) RequestHandler(ctx *fiber.Ctx) error {
 	})
 }
 
-// handler with url parameter
-func (t *TestHandler) RouteParameterHandler(ctx *fiber.Ctx) error {
-	// get data from url parameters
-	userId, _ := strconv.Atoi(ctx.Params("userId"))
-	orderId, _ := strconv.Atoi(ctx.Params("orderId"))
-
-	ctx.SendStatus(http.StatusOK)
-	return ctx.JSON(map[string]any{
-		"status_code": http.StatusOK,
-		"user":        userId,
-		"order":       orderId,
-	})
-}
-
 // handler with request http-form
 func (t *TestHandler) RequestFormHandler(ctx *fiber.Ctx) error {
 	// get name from Form
@@ -73,57 +78,85 @@ func (t *TestHandler) RequestFormHandler(ctx *fiber.Ctx) error {
 	})
 }
 
-// hander with request MultiPart Form
+// hander with request MultiPart Form, file di-stream langsung ke storage tanpa buffer di memory
 func (t *TestHandler) MultiPartFormHandler(ctx *fiber.Ctx) error {
-	// get data from multipart form
-	file, err := ctx.FormFile("file")
+	// panjang body termasuk header multipart, sedikit lebih besar dari isi file
+	if length := ctx.Request().Header.ContentLength(); t.Quota != nil && length > 0 {
+		if err := t.Quota.Check(ctx.UserContext(), fileOwner(ctx), int64(length)); err != nil {
+			return uploadErrorResponse(ctx, err)
+		}
+	}
 
-	// jika error ketika read file
+	files, values, err := upload.StreamMultipart(ctx, t.Storage)
 	if err != nil {
-		ctx.SendStatus(http.StatusInternalServerError)
-		return ctx.JSON(map[string]any{
-			"status_code": http.StatusInternalServerError,
-			"message":     err.Error(),
-		})
+		return uploadErrorResponse(ctx, err)
 	}
 
-	// save file to target folder
-	err = ctx.SaveFile(file, "C:/Users/HP/Documents/go/src/go_fiber/multipart/target/"+file.Filename)
+	var uploaded *upload.File
+	for i := range files {
+		if files[i].Field == "file" {
+			uploaded = &files[i]
+		}
+	}
 
-	// jika error ketika save file
-	if err != nil {
-		ctx.SendStatus(http.StatusInternalServerError)
-		return ctx.JSON(map[string]any{
-			"status_code": http.StatusInternalServerError,
-			"message":     err.Error(),
+	// tidak ada file dengan field "file", part lain dihapus tanpa diproses
+	if uploaded == nil {
+		t.removeFiles(ctx, files)
+		ctx.Status(http.StatusBadRequest)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "bad request",
+			Message:    http.ErrMissingFile.Error(),
 		})
 	}
 
+	// post-processing setelah upload selesai. jika satu file gagal, file yang sudah diproses
+	// dibatalkan seluruhnya (variant, status scan, catalogue) dan semua file dihapus
+	for i := range files {
+		files[i].Owner = fileOwner(ctx)
+		files[i].Tags = upload.ParseTags(values["tags"])
+		if err := t.Pipeline.Run(ctx.UserContext(), &files[i]); err != nil {
+			for j := range files[:i] {
+				t.Pipeline.Revert(ctx.UserContext(), &files[j])
+			}
+			t.removeFiles(ctx, files)
+			return uploadErrorResponse(ctx, err)
+		}
+	}
+
 	// sucess save file
-	ctx.SendStatus(http.StatusOK)
-	return ctx.JSON(map[string]any{
-		"status_code": http.StatusOK,
-		"message":     "success upload file",
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success upload file",
+		Data:       uploaded,
 	})
 }
 
+func (t *TestHandler) removeFiles(ctx *fiber.Ctx, files []upload.File) {
+	for _, file := range files {
+		t.Storage.Remove(context.WithoutCancel(ctx.UserContext()), file.ID)
+	}
+}
+
 // handler with request body
 func (t *TestHandler) RequestBodyHandler(ctx *fiber.Ctx) error {
 	// get data from request_body
-	body := ctx.Body()
 	requestBody := dto.LoginRequest{}
-	if err := json.Unmarshal(body, &requestBody); err != nil {
-		ctx.Status(http.StatusInternalServerError)
-		return ctx.JSON(&dto.ApiResponse{
-			StatusCode: http.StatusInternalServerError,
-			Status:     "internal server error",
-			Message:    err.Error(),
-		})
+	if err := render.Decode(ctx, &requestBody); err != nil {
+		return decodeErrorResponse(ctx, err)
 	}
 
 	// validate
-	if err := t.Validate.StructCtx(ctx.Context(), &requestBody); err != nil {
+	if err := t.Validate.StructCtx(ctx.UserContext(), &requestBody); err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			// form html dirender ulang dengan error per field
+			if wantsHTML(ctx) {
+				ctx.Status(http.StatusBadRequest)
+				return t.renderLoginPage(ctx, requestBody, formErrors(validationErrors, requestBody))
+			}
+
 			var errorMessage []string
 			for _, fieldError := range validationErrors {
 				msg := fmt.Sprintf("error on field [%v] with tag [%v]", fieldError.Field(), fieldError.Tag())
@@ -131,7 +164,7 @@ func (t *TestHandler) RequestBodyHandler(ctx *fiber.Ctx) error {
 			}
 
 			ctx.Status(http.StatusBadRequest)
-			return ctx.JSON(&dto.ApiResponse{
+			return render.Respond(ctx, &dto.ApiResponse{
 				StatusCode: http.StatusBadRequest,
 				Status:     "bad request",
 				Message:    strings.Join(errorMessage, ", "),
@@ -140,8 +173,13 @@ func (t *TestHandler) RequestBodyHandler(ctx *fiber.Ctx) error {
 	}
 
 	// success get request body
+	if wantsHTML(ctx) {
+		view.SetFlash(ctx, "success", "success login")
+		return ctx.Redirect("/v1/view", http.StatusSeeOther)
+	}
+
 	ctx.Status(http.StatusOK)
-	return ctx.JSON(&dto.ApiResponse{
+	return render.Respond(ctx, &dto.ApiResponse{
 		StatusCode: http.StatusOK,
 		Status:     "ok",
 		Message:    "success login",
@@ -154,23 +192,24 @@ func (t *TestHandler) RequestBodyHandler(ctx *fiber.Ctx) error {
 
 // handelr register menggunakan Body Parser
 func (t *TestHandler) RegisterUserBodyParser(ctx *fiber.Ctx) error {
-	// ambil request body
+	// ambil request body, json/xml/form/msgpack/cbor
 	request := dto.RegisterUser{}
-	if err := ctx.BodyParser(&request); err != nil {
-		// error bad request
-		ctx.Status(http.StatusBadRequest)
-		return ctx.JSON(&dto.ApiResponse{
-			StatusCode: http.StatusBadRequest,
-			Status:     "bad request",
-			Message:    err.Error(),
-		})
+	if err := render.Decode(ctx, &request); err != nil {
+		// error bad request / unsupported media type
+		return decodeErrorResponse(ctx, err)
 	}
 
 	// validasi
-	if err := t.Validate.StructCtx(ctx.Context(), &request); err != nil {
+	if err := t.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
 		// error validasi
 		validationErrors, ok := err.(validator.ValidationErrors)
 		if ok {
+			// form html dirender ulang dengan error per field
+			if wantsHTML(ctx) {
+				ctx.Status(http.StatusBadRequest)
+				return t.renderRegisterPage(ctx, request, formErrors(validationErrors, request))
+			}
+
 			var errorMessage []string
 			for _, fieldError := range validationErrors {
 				message := fmt.Sprintf("error in field [%v] with tag [%v]", fieldError.Field(), fieldError.Tag())
@@ -179,7 +218,7 @@ func (t *TestHandler) RegisterUserBodyParser(ctx *fiber.Ctx) error {
 
 			// bad request
 			ctx.Status(http.StatusBadRequest)
-			return ctx.JSON(&dto.ApiResponse{
+			return render.Respond(ctx, &dto.ApiResponse{
 				StatusCode: http.StatusBadRequest,
 				Status:     "bad request",
 				Message:    strings.Join(errorMessage, ", "),
@@ -187,20 +226,73 @@ func (t *TestHandler) RegisterUserBodyParser(ctx *fiber.Ctx) error {
 		}
 	}
 
+	// simpan user, response tanpa password
+	var data any = map[string]any{
+		"username": request.Username,
+		"password": request.Password,
+		"name":     request.Name,
+	}
+	if t.Users != nil {
+		user, err := t.UserConfig.NewUser(request.Username, request.Password, request.Name)
+		if err != nil {
+			return err
+		}
+		user, err = t.Users.Create(ctx.UserContext(), user)
+		if errors.Is(err, users.ErrUsernameTaken) {
+			if wantsHTML(ctx) {
+				ctx.Status(http.StatusConflict)
+				return t.renderRegisterPage(ctx, request, map[string]string{"username": err.Error()})
+			}
+			return usernameTaken(ctx)
+		}
+		if err != nil {
+			return err
+		}
+		data = userResponse(user)
+	}
+
 	// success
+	if wantsHTML(ctx) {
+		view.SetFlash(ctx, "success", "registration success, please login")
+		return ctx.Redirect("/login", http.StatusSeeOther)
+	}
+
 	ctx.Status(http.StatusOK)
-	return ctx.JSON(&dto.ApiResponse{
+	return render.Respond(ctx, &dto.ApiResponse{
 		StatusCode: http.StatusOK,
 		Status:     "ok",
 		Message:    "success",
-		Data: map[string]any{
-			"username": request.Username,
-			"password": request.Password,
-			"name":     request.Name,
-		},
+		Data:       data,
 	})
 }
 
+// handler halaman login
+func (t *TestHandler) LoginPage(ctx *fiber.Ctx) error {
+	return t.renderLoginPage(ctx, dto.LoginRequest{}, nil)
+}
+
+// handler halaman register
+func (t *TestHandler) RegisterPage(ctx *fiber.Ctx) error {
+	return t.renderRegisterPage(ctx, dto.RegisterUser{}, nil)
+}
+
+// password tidak pernah dikirim balik ke form
+func (t *TestHandler) renderLoginPage(ctx *fiber.Ctx, form dto.LoginRequest, errors map[string]string) error {
+	return ctx.Render("login", fiber.Map{
+		"title":  "Login",
+		"form":   fiber.Map{"email": form.Email},
+		"errors": errors,
+	}, view.DefaultLayout)
+}
+
+func (t *TestHandler) renderRegisterPage(ctx *fiber.Ctx, form dto.RegisterUser, errors map[string]string) error {
+	return ctx.Render("register", fiber.Map{
+		"title":  "Register",
+		"form":   fiber.Map{"username": form.Username, "name": form.Name},
+		"errors": errors,
+	}, view.DefaultLayout)
+}
+
 // handler HTTP Response
 func (t *TestHandler) ResponseJsonHandler(ctx *fiber.Ctx) error {
 	// get query params
@@ -208,7 +300,7 @@ func (t *TestHandler) ResponseJsonHandler(ctx *fiber.Ctx) error {
 
 	// send to response
 	ctx.Status(http.StatusOK)
-	return ctx.JSON(&dto.ApiResponse{
+	return render.Respond(ctx, &dto.ApiResponse{
 		StatusCode: http.StatusOK,
 		Status:     "ok",
 		Message:    "success send response json",
@@ -216,14 +308,20 @@ func (t *TestHandler) ResponseJsonHandler(ctx *fiber.Ctx) error {
 	})
 }
 
-// handler untuk download file
+// handler untuk download file, file diambil dari asset yang di-embed
 func (t *TestHandler) DownloadFile(ctx *fiber.Ctx) error {
-	return ctx.Download("C:\\Users\\HP\\Documents\\go\\src\\go_fiber\\multipart\\source\\contoh.txt", "contoh2.txt")
+	content, err := public.Files.ReadFile("contoh.txt")
+	if err != nil {
+		return err
+	}
+
+	ctx.Attachment("contoh2.txt")
+	return ctx.Send(content)
 }
 
 // handler routing group
 func (t *TestHandler) RoutingGroup(ctx *fiber.Ctx) error {
-	return ctx.JSON(&dto.ApiResponse{
+	return render.Respond(ctx, &dto.ApiResponse{
 		StatusCode: http.StatusOK,
 		Status:     "ok",
 		Message:    "success routing group",
@@ -233,8 +331,89 @@ func (t *TestHandler) RoutingGroup(ctx *fiber.Ctx) error {
 // handler render template mustache
 func (t *TestHandler) RenderTemplateView(ctx *fiber.Ctx) error {
 	return ctx.Render("index", fiber.Map{
-		"title":   "Belajar Fiber",
-		"header":  "Belajar GOlang Fiber",
-		"content": "melalui web ini",
+		"title":      "Belajar Fiber",
+		"header":     "Belajar GOlang Fiber",
+		"content":    "melalui web ini",
+		"renderedAt": time.Now().Format(time.RFC3339),
+	}, view.DefaultLayout)
+}
+
+// response untuk error upload, 413 jika melebihi limit body
+func uploadErrorResponse(ctx *fiber.Ctx, err error) error {
+	code := http.StatusBadRequest
+	switch {
+	case errors.Is(err, upload.ErrBodyTooLarge), errors.Is(err, upload.ErrQuotaExceeded):
+		code = http.StatusRequestEntityTooLarge
+	case errors.Is(err, upload.ErrInsufficientStorage):
+		code = http.StatusInsufficientStorage
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		// diubah menjadi 504/503 oleh middleware timeout
+		return err
+	case errors.Is(err, upload.ErrRejected):
+		code = http.StatusUnprocessableEntity
+	case errors.Is(err, upload.ErrUnavailable):
+		code = http.StatusServiceUnavailable
+	case errors.Is(err, upload.ErrStorage):
+		code = http.StatusInternalServerError
+	}
+
+	// sisa body upload tidak dibaca, koneksi ditutup setelah response
+	ctx.Context().SetConnectionClose()
+	ctx.Status(code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     strings.ToLower(http.StatusText(code)),
+		Message:    err.Error(),
+	})
+}
+
+// response 400 untuk error dari binding.Bind, detail per field di data
+func bindErrorResponse(ctx *fiber.Ctx, err error) error {
+	bindErrors, ok := err.(*binding.Errors)
+	if !ok {
+		return err
+	}
+
+	ctx.Status(http.StatusBadRequest)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusBadRequest,
+		Status:     "bad request",
+		Message:    bindErrors.Error(),
+		Data:       bindErrors.Fields,
+	})
+}
+
+// response 400 untuk error validasi request api
+func validationErrorResponse(ctx *fiber.Ctx, err error) error {
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+
+	var errorMessage []string
+	for _, fieldError := range validationErrors {
+		errorMessage = append(errorMessage, fmt.Sprintf("error on field [%v] with tag [%v]", fieldError.Field(), fieldError.Tag()))
+	}
+
+	ctx.Status(http.StatusBadRequest)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusBadRequest,
+		Status:     "bad request",
+		Message:    strings.Join(errorMessage, ", "),
+	})
+}
+
+// response untuk error dari render.Decode, 400 atau 415
+func decodeErrorResponse(ctx *fiber.Ctx, err error) error {
+	fiberError, ok := err.(*fiber.Error)
+	if !ok {
+		return err
+	}
+
+	ctx.Status(fiberError.Code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: fiberError.Code,
+		Status:     strings.ToLower(http.StatusText(fiberError.Code)),
+		Message:    fiberError.Message,
 	})
 }
@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/archive"
+	"go_fiber/catalogue"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/scanning"
+	"go_fiber/storage"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// nama entry berisi daftar file yang hilang saat archive dibuat
+const missingFilesName = "MISSING.txt"
+
+// ArchiveHandler streams several catalogue files as one ZIP or tar.gz archive.
+type ArchiveHandler struct {
+	Catalogue  catalogue.Store
+	Storage    storage.Storage
+	Quarantine *scanning.Quarantine
+	Config     archive.Config
+	Validate   *validator.Validate
+}
+
+// file yang akan dimasukkan ke archive
+type archiveItem struct {
+	entry catalogue.Entry
+	name  string
+}
+
+// function provider
+func NewArchiveHandler(store catalogue.Store, files storage.Storage, config archive.Config, validate *validator.Validate) *ArchiveHandler {
+	if config.MaxSize <= 0 {
+		config.MaxSize = 1 << 30
+	}
+
+	return &ArchiveHandler{
+		Catalogue: store,
+		Storage:   files,
+		Config:    config,
+		Validate:  validate,
+	}
+}
+
+// handler POST /files/archive. Semua file dicek sebelum response dimulai; file yang
+// hilang setelah itu dilewati dan dicatat di MISSING.txt. Jika file gagal dibaca di
+// tengah jalan archive sengaja tidak ditutup, sehingga client melihat archive rusak
+// dan bukan file yang terpotong diam-diam.
+func (a *ArchiveHandler) Archive(ctx *fiber.Ctx) error {
+	request := dto.ArchiveRequest{}
+	if err := render.Decode(ctx, &request); err != nil {
+		return decodeErrorResponse(ctx, err)
+	}
+	if err := a.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
+		return validationErrorResponse(ctx, err)
+	}
+	if request.Format == "" {
+		request.Format = archive.FormatZip
+	}
+
+	var items []archiveItem
+	var total int64
+	names := archive.NewNames()
+	for _, file := range request.Files {
+		entry, err := a.Catalogue.Get(ctx.UserContext(), file.ID)
+		if errors.Is(err, catalogue.ErrNotFound) {
+			return archiveErrorResponse(ctx, http.StatusNotFound, fmt.Sprintf("file %v not found", file.ID))
+		}
+		if err != nil {
+			return err
+		}
+		if !ownsFile(ctx, entry) {
+			return archiveErrorResponse(ctx, http.StatusForbidden, fmt.Sprintf("file %v belongs to another user", file.ID))
+		}
+
+		if a.Quarantine != nil {
+			released, err := a.Quarantine.Released(ctx.UserContext(), entry.ID)
+			if err != nil {
+				return err
+			}
+			if !released {
+				return archiveErrorResponse(ctx, http.StatusForbidden, fmt.Sprintf("file %v is quarantined until it has been scanned clean", file.ID))
+			}
+		}
+
+		total += entry.Size
+		if total > a.Config.MaxSize {
+			return archiveErrorResponse(ctx, http.StatusRequestEntityTooLarge, fmt.Sprintf("archive exceeds the limit of %d bytes", a.Config.MaxSize))
+		}
+		items = append(items, archiveItem{entry: entry, name: names.Unique(file.Path, entry.Name)})
+	}
+	missingName := names.Unique(missingFilesName, "")
+
+	ctx.Set(fiber.HeaderContentType, archive.ContentType(request.Format))
+	ctx.Attachment("files" + archive.Extension(request.Format))
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+
+	// body ditulis setelah handler selesai, ctx tidak boleh dipakai di dalam stream writer dan
+	// context dari TimeoutMiddleware sudah dibatalkan. deadline route diteruskan ke context baru,
+	// sedangkan koneksi dibatasi WriteTimeout route dari TimeoutConfig.RequestConfig
+	streamCtx, cancel := context.Background(), context.CancelFunc(func() {})
+	if deadline, ok := ctx.UserContext().Deadline(); ok {
+		streamCtx, cancel = context.WithDeadline(context.Background(), deadline)
+	}
+	format := request.Format
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		if err := a.write(streamCtx, w, format, items, missingName); err != nil {
+			log.Printf("error streaming archive : %v", err)
+		}
+	})
+	return nil
+}
+
+func (a *ArchiveHandler) write(ctx context.Context, w *bufio.Writer, format string, items []archiveItem, missingName string) error {
+	writer, err := archive.NewWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, item := range items {
+		// melewati deadline route, archive sengaja tidak ditutup
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reader, err := a.Storage.Open(ctx, item.entry.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			missing = append(missing, item.name)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		err = writer.Add(item.name, item.entry.Size, item.entry.CreatedAt, item.entry.ContentType, reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(missing) > 0 {
+		content := "these files were deleted while the archive was created:\n" + strings.Join(missing, "\n") + "\n"
+		if err := writer.Add(missingName, int64(len(content)), time.Now(), "text/plain", strings.NewReader(content)); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func archiveErrorResponse(ctx *fiber.Ctx, code int, message string) error {
+	ctx.Status(code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     strings.ToLower(http.StatusText(code)),
+		Message:    message,
+	})
+}
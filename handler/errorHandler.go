@@ -1,24 +1,132 @@
 package handler
 
 import (
+	"errors"
 	"github.com/gofiber/fiber/v2"
 	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/view"
 	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
 )
 
-type ErrorHandler struct{}
+// template halaman error per status code, status lain memakai errors/500
+var errorPages = map[int]string{
+	http.StatusForbidden:           "errors/403",
+	http.StatusNotFound:            "errors/404",
+	http.StatusInternalServerError: "errors/500",
+	http.StatusServiceUnavailable:  "errors/maintenance",
+}
+
+// header yang tidak ditampilkan di halaman error dev mode
+var maskedHeaders = map[string]bool{
+	fiber.HeaderAuthorization: true,
+	fiber.HeaderCookie:        true,
+}
+
+// error yang membawa stack trace sendiri, ditampilkan di halaman error dev mode
+type StackTracer interface {
+	StackTrace() string
+}
+
+type ErrorHandler struct {
+	devMode bool
+}
 
 // function provider
 func NewErrorHandler() *ErrorHandler {
 	return &ErrorHandler{}
 }
 
+// error handler untuk development, halaman error menampilkan stack trace & detail request
+func NewDevErrorHandler() *ErrorHandler {
+	return &ErrorHandler{devMode: true}
+}
+
 // method error
 func (e *ErrorHandler) ErrorHandler(ctx *fiber.Ctx, err error) error {
-	ctx.Status(http.StatusInternalServerError)
-	return ctx.JSON(&dto.ApiResponse{
-		StatusCode: http.StatusInternalServerError,
-		Status:     "internal server error",
-		Message:    err.Error(),
+	// browser mendapat halaman html, client api tetap json
+	if wantsHTML(ctx) && ctx.App().Config().Views != nil {
+		if renderErr := e.renderErrorPage(ctx, err); renderErr == nil {
+			return nil
+		}
+	}
+
+	code, message := e.errorMessage(err)
+	ctx.Status(code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     strings.ToLower(http.StatusText(code)),
+		Message:    message,
+	})
+}
+
+// status code dari *fiber.Error, error lain 500. pesan error internal hanya ditampilkan di dev mode
+func (e *ErrorHandler) errorMessage(err error) (int, string) {
+	code := http.StatusInternalServerError
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		code = fiberError.Code
+	}
+
+	if code < http.StatusInternalServerError || e.devMode {
+		return code, err.Error()
+	}
+	return code, http.StatusText(code)
+}
+
+func (e *ErrorHandler) renderErrorPage(ctx *fiber.Ctx, err error) error {
+	code, message := e.errorMessage(err)
+
+	data := fiber.Map{
+		"title":      http.StatusText(code),
+		"statusCode": code,
+		"statusText": http.StatusText(code),
+		"message":    message,
+		"requestId":  ctx.Locals(view.RequestIdKey),
+	}
+
+	page, ok := errorPages[code]
+	if !ok {
+		page = errorPages[http.StatusInternalServerError]
+	}
+	if e.devMode {
+		page = "errors/dev"
+		data["method"] = ctx.Method()
+		data["url"] = ctx.OriginalURL()
+		data["route"] = ctx.Route().Path
+		data["ip"] = ctx.IP()
+		data["headers"] = requestHeaders(ctx)
+		data["stack"] = stackTrace(err)
+	}
+
+	ctx.Status(code)
+	return ctx.Render(page, data, view.DefaultLayout)
+}
+
+// header request terurut berdasarkan nama, header sensitif disamarkan
+func requestHeaders(ctx *fiber.Ctx) []fiber.Map {
+	var headers []fiber.Map
+	for name, values := range ctx.GetReqHeaders() {
+		value := strings.Join(values, ", ")
+		if maskedHeaders[name] {
+			value = "[hidden]"
+		}
+		headers = append(headers, fiber.Map{"name": name, "value": value})
+	}
+
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i]["name"].(string) < headers[j]["name"].(string)
 	})
+	return headers
+}
+
+func stackTrace(err error) string {
+	var tracer StackTracer
+	if errors.As(err, &tracer) {
+		return tracer.StackTrace()
+	}
+	return string(debug.Stack())
 }
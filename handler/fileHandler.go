@@ -0,0 +1,340 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/binding"
+	"go_fiber/catalogue"
+	"go_fiber/imaging"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/quota"
+	"go_fiber/render"
+	"go_fiber/scanning"
+	"go_fiber/storage"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+)
+
+// FileHandler serves files stored by the upload handlers. When Quarantine is set only
+// files that have been scanned clean are served; when Catalogue is set only files
+// recorded in it are served, under their original name. When Quota is set deleted
+// files are released from the usage of their owner.
+type FileHandler struct {
+	Storage    storage.Storage
+	Images     *imaging.Processor
+	Quarantine *scanning.Quarantine
+	Catalogue  catalogue.Store
+	Quota      *quota.Checker
+	Validate   *validator.Validate
+}
+
+// function provider
+func NewFileHandler(store storage.Storage, images *imaging.Processor) *FileHandler {
+	return &FileHandler{
+		Storage:  store,
+		Images:   images,
+		Validate: validator.New(),
+	}
+}
+
+// pemilik file adalah id user yang login, kosong tanpa login
+func fileOwner(ctx *fiber.Ctx) string {
+	if user := middleware.GetCurrentUser(ctx); user != nil {
+		return user.ID
+	}
+	return ""
+}
+
+// file hanya boleh diakses pemiliknya atau admin, file tanpa pemilik hanya oleh admin
+func ownsFile(ctx *fiber.Ctx, entry catalogue.Entry) bool {
+	user := middleware.GetCurrentUser(ctx)
+	if user == nil {
+		return false
+	}
+	return (entry.Owner != "" && entry.Owner == user.ID) || user.IsAdmin()
+}
+
+// handler daftar file dari catalogue, contoh GET /files?type=image&sort=-size&page=2
+func (f *FileHandler) List(ctx *fiber.Ctx) error {
+	query := dto.FileQuery{}
+	if err := binding.Bind(ctx, f.Validate, &query); err != nil {
+		return bindErrorResponse(ctx, err)
+	}
+
+	// selain admin hanya melihat file miliknya sendiri
+	current := middleware.GetCurrentUser(ctx)
+	if !current.IsAdmin() {
+		if query.Owner != "" && query.Owner != current.ID {
+			return forbiddenResponse(ctx, "only admins can list the files of other users")
+		}
+		query.Owner = current.ID
+	}
+
+	// tanggal to inklusif, jadi batasnya awal hari berikutnya
+	to := query.To
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	entries, total, err := f.Catalogue.List(ctx.UserContext(), catalogue.Query{
+		Owner:   query.Owner,
+		Type:    query.Type,
+		From:    query.From,
+		To:      to,
+		Sort:    query.Sort,
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success list files",
+		Data:       dto.NewPage(entries, query.Page, query.PerPage, total),
+	})
+}
+
+// handler hapus file beserta variant, status scan & entry catalogue
+func (f *FileHandler) Delete(ctx *fiber.Ctx) error {
+	entry, err := f.Catalogue.Get(ctx.UserContext(), ctx.Params("id"))
+	if errors.Is(err, catalogue.ErrNotFound) {
+		return fileNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !ownsFile(ctx, entry) {
+		return forbiddenResponse(ctx, "only the owner can delete this file")
+	}
+
+	names := []string{entry.ID}
+	for _, variant := range entry.Variants {
+		names = append(names, imaging.VariantName(entry.ID, variant))
+	}
+	for _, name := range names {
+		if err := f.Storage.Remove(ctx.UserContext(), name); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	if f.Quarantine != nil {
+		if err := f.Quarantine.Remove(ctx.UserContext(), entry.ID); err != nil {
+			return err
+		}
+	}
+
+	if f.Quota != nil {
+		if err := f.Quota.Release(ctx.UserContext(), entry.Owner, entry.ID); err != nil {
+			return err
+		}
+	}
+
+	// entry dihapus terakhir, jika gagal di tengah file masih terlihat dan bisa dihapus ulang
+	if err := f.Catalogue.Delete(ctx.UserContext(), entry.ID); err != nil && !errors.Is(err, catalogue.ErrNotFound) {
+		return err
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success delete file",
+		Data:       entry,
+	})
+}
+
+// handler download lewat link share, link sudah diverifikasi SignedLinkMiddleware
+func (f *FileHandler) Shared(ctx *fiber.Ctx) error {
+	link := middleware.GetSharedLink(ctx)
+	if link == nil {
+		return fileNotFound(ctx)
+	}
+
+	entry, err := f.Catalogue.Get(ctx.UserContext(), link.FileID)
+	if errors.Is(err, catalogue.ErrNotFound) {
+		return fileNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	return f.send(ctx, entry.ID, entry.ID, func() {
+		ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+		ctx.Attachment(entry.Name)
+	})
+}
+
+// handler download file asli oleh pemilik atau admin, contoh GET /files/0b6f...
+func (f *FileHandler) Download(ctx *fiber.Ctx) error {
+	entry, err := f.ownedEntry(ctx, "only the owner can download this file")
+	if err != nil || entry == nil {
+		return err
+	}
+
+	return f.send(ctx, entry.ID, entry.ID, func() {
+		ctx.Set(fiber.HeaderCacheControl, "private, no-store")
+		ctx.Attachment(entry.Name)
+	})
+}
+
+// handler variant gambar oleh pemilik atau admin, contoh GET /files/0b6f.../variants/thumb
+func (f *FileHandler) Variant(ctx *fiber.Ctx) error {
+	name := ctx.Params("name")
+	if !f.Images.HasVariant(name) {
+		return fileNotFound(ctx)
+	}
+	entry, err := f.ownedEntry(ctx, "only the owner can download this file")
+	if err != nil || entry == nil {
+		return err
+	}
+	if f.Catalogue != nil && !slices.Contains(entry.Variants, name) {
+		return fileNotFound(ctx)
+	}
+
+	// hanya boleh di-cache browser user itu sendiri, bukan cache bersama
+	return f.send(ctx, entry.ID, imaging.VariantName(entry.ID, name), func() {
+		ctx.Set(fiber.HeaderCacheControl, "private, max-age=86400")
+	})
+}
+
+// entry catalogue dari param id yang boleh diakses user yang login. tanpa catalogue pemilik
+// tidak diketahui, sehingga hanya admin yang boleh. response sudah ditulis jika entry nil
+func (f *FileHandler) ownedEntry(ctx *fiber.Ctx, forbidden string) (*catalogue.Entry, error) {
+	id, err := url.PathUnescape(ctx.Params("id"))
+	if err != nil {
+		return nil, fileNotFound(ctx)
+	}
+
+	entry := catalogue.Entry{ID: id, Name: id}
+	if f.Catalogue != nil {
+		entry, err = f.Catalogue.Get(ctx.UserContext(), id)
+		if errors.Is(err, catalogue.ErrNotFound) {
+			return nil, fileNotFound(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !ownsFile(ctx, entry) {
+		return nil, forbiddenResponse(ctx, forbidden)
+	}
+	return &entry, nil
+}
+
+// kirim file name dari storage, file yang masih dikarantina (dilihat dari file asli id) tidak dikirim.
+// header tambahan di-set oleh headers hanya jika file benar-benar dikirim
+func (f *FileHandler) send(ctx *fiber.Ctx, id string, name string, headers func()) error {
+	reader, err := f.Storage.Open(ctx.UserContext(), name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return fileNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if f.Quarantine != nil {
+		released, err := f.Quarantine.Released(ctx.UserContext(), id)
+		if err != nil {
+			reader.Close()
+			return err
+		}
+		if !released {
+			reader.Close()
+			ctx.Set(fiber.HeaderCacheControl, "no-store")
+			ctx.Status(http.StatusForbidden)
+			return render.Respond(ctx, &dto.ApiResponse{
+				StatusCode: http.StatusForbidden,
+				Status:     "forbidden",
+				Message:    "file is quarantined until it has been scanned clean",
+			})
+		}
+	}
+
+	// content type dari isi file
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(reader, header)
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		reader.Close()
+		return err
+	}
+
+	headers()
+	ctx.Set(fiber.HeaderContentType, http.DetectContentType(header[:n]))
+	return sendRange(ctx, reader)
+}
+
+// kirim reader, request dengan header Range satu rentang dijawab 206 dengan bagian yang diminta.
+// file terenkripsi juga bisa, reader dari storage sudah mendekripsi per segment saat Seek
+func sendRange(ctx *fiber.Ctx, reader io.ReadSeekCloser) error {
+	size, err := reader.Seek(0, io.SeekEnd)
+	if err == nil {
+		_, err = reader.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		reader.Close()
+		return err
+	}
+
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	if ctx.Get(fiber.HeaderRange) == "" {
+		return ctx.SendStream(reader, int(size))
+	}
+
+	ranges, err := ctx.Range(int(size))
+	if errors.Is(err, fiber.ErrRangeUnsatisfiable) {
+		reader.Close()
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", size))
+		ctx.Status(http.StatusRequestedRangeNotSatisfiable)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusRequestedRangeNotSatisfiable,
+			Status:     "requested range not satisfiable",
+			Message:    fmt.Sprintf("range is outside of the file size %d", size),
+		})
+	}
+	// range tidak valid atau lebih dari satu rentang, file dikirim utuh
+	if err != nil || ranges.Type != "bytes" || len(ranges.Ranges) != 1 {
+		return ctx.SendStream(reader, int(size))
+	}
+
+	start, end := int64(ranges.Ranges[0].Start), int64(ranges.Ranges[0].End)
+	if _, err := reader.Seek(start, io.SeekStart); err != nil {
+		reader.Close()
+		return err
+	}
+	ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes %d-%d/%d", start, end, size))
+	ctx.Status(http.StatusPartialContent)
+	return ctx.SendStream(&limitedReader{Reader: io.LimitReader(reader, end-start+1), Closer: reader}, int(end-start+1))
+}
+
+// bagian file yang dikirim, Close dipanggil fasthttp setelah body selesai dikirim
+type limitedReader struct {
+	io.Reader
+	io.Closer
+}
+
+func forbiddenResponse(ctx *fiber.Ctx, message string) error {
+	ctx.Status(http.StatusForbidden)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusForbidden,
+		Status:     "forbidden",
+		Message:    message,
+	})
+}
+
+func fileNotFound(ctx *fiber.Ctx) error {
+	ctx.Status(http.StatusNotFound)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusNotFound,
+		Status:     "not found",
+		Message:    "file not found",
+	})
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/binding"
+	"go_fiber/render"
+	"reflect"
+)
+
+// browser (Accept text/html) mendapat halaman html, client lain tetap json
+func wantsHTML(ctx *fiber.Ctx) bool {
+	mediaType, _ := render.Negotiate(ctx.Get(fiber.HeaderAccept), []string{fiber.MIMEApplicationJSON, fiber.MIMETextHTML})
+	return mediaType == fiber.MIMETextHTML
+}
+
+// error validasi per field, key-nya nama field form supaya bisa dipakai di template
+func formErrors(validationErrors validator.ValidationErrors, form any) map[string]string {
+	formType := reflect.TypeOf(form)
+	for formType.Kind() == reflect.Pointer {
+		formType = formType.Elem()
+	}
+
+	errors := map[string]string{}
+	for _, fieldError := range validationErrors {
+		name := fieldError.Field()
+		if field, ok := formType.FieldByName(fieldError.StructField()); ok && field.Tag.Get("form") != "" {
+			name = field.Tag.Get("form")
+		}
+		if _, exists := errors[name]; !exists {
+			errors[name] = binding.ValidationMessage(fieldError)
+		}
+	}
+	return errors
+}
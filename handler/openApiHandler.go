@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/middleware"
+	"go_fiber/openapi"
+)
+
+// bundle redoc di-embed bersama public assets, tidak ada script pihak ketiga
+const redocPage = `<!doctype html>
+<html lang="en">
+    <head>
+        <meta charset="UTF-8">
+        <meta name="viewport" content="width=device-width, initial-scale=1">
+        <title>%[1]s</title>
+    </head>
+    <body>
+        <redoc spec-url="%[2]s"></redoc>
+        <script nonce="%[3]s" src="/public/vendor/redoc/redoc.standalone.js"></script>
+    </body>
+</html>`
+
+// redoc memakai inline style dan web worker, jadi CSP halaman docs dilonggarkan
+const redocPolicy = "default-src 'self'; script-src 'self' 'nonce-%s'; " +
+	"style-src 'self' 'unsafe-inline'; img-src 'self' data:; worker-src blob:"
+
+type OpenApiHandler struct {
+	Spec *openapi.Spec
+}
+
+// function provider
+func NewOpenApiHandler(spec *openapi.Spec) *OpenApiHandler {
+	return &OpenApiHandler{
+		Spec: spec,
+	}
+}
+
+// handler GET /openapi.json
+func (o *OpenApiHandler) OpenApiJson(ctx *fiber.Ctx) error {
+	spec, err := o.Spec.JSON()
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return ctx.Send(spec)
+}
+
+// handler GET /docs
+func (o *OpenApiHandler) Docs(ctx *fiber.Ctx) error {
+	nonce := middleware.GetCspNonce(ctx)
+	if nonce == "" {
+		nonce = "docs"
+	}
+
+	ctx.Set(fiber.HeaderContentSecurityPolicy, fmt.Sprintf(redocPolicy, nonce))
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return ctx.SendString(fmt.Sprintf(redocPage, o.Spec.Document().Info.Title, "/openapi.json", nonce))
+}
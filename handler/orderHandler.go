@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/binding"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/orders"
+	"go_fiber/render"
+	"go_fiber/users"
+	"net/http"
+	"strings"
+)
+
+// OrderHandler manages the orders of a user under /user/:userId/order. Users can only
+// see and cancel their own orders; admins can do so for every user and move orders
+// along the status transitions.
+type OrderHandler struct {
+	Orders   orders.Repository
+	Users    users.Store
+	Validate *validator.Validate
+}
+
+// function provider
+func NewOrderHandler(repository orders.Repository, store users.Store, validate *validator.Validate) *OrderHandler {
+	return &OrderHandler{
+		Orders:   repository,
+		Users:    store,
+		Validate: validate,
+	}
+}
+
+// order di response
+func orderResponse(order orders.Order) dto.Order {
+	items := make([]dto.OrderItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, dto.OrderItem{Product: item.Product, Quantity: item.Quantity, Price: item.Price})
+	}
+	return dto.Order{
+		ID:        order.ID,
+		UserID:    order.UserID,
+		Status:    string(order.Status),
+		Items:     items,
+		Total:     order.Total,
+		CreatedAt: order.CreatedAt,
+		UpdatedAt: order.UpdatedAt,
+	}
+}
+
+// handler POST /user/:userId/order, order baru berstatus pending
+func (o *OrderHandler) Create(ctx *fiber.Ctx) error {
+	request := dto.CreateOrder{}
+	if err := render.Decode(ctx, &request); err != nil {
+		return decodeErrorResponse(ctx, err)
+	}
+	if err := o.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
+		return validationErrorResponse(ctx, err)
+	}
+
+	// param fiber hanya valid selama request, id disimpan di order
+	userId := strings.Clone(ctx.Params("userId"))
+	if ok, err := o.checkOwner(ctx, userId, "only the user or an admin can create orders for this user"); !ok {
+		return err
+	}
+
+	// admin membuat order untuk user lain, user harus ada & belum dihapus
+	if userId != middleware.GetCurrentUser(ctx).ID {
+		user, err := o.Users.Get(ctx.UserContext(), userId)
+		if errors.Is(err, users.ErrNotFound) || (err == nil && user.Deleted()) {
+			return userNotFound(ctx)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	items := make([]orders.Item, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, orders.Item{Product: item.Product, Quantity: item.Quantity, Price: item.Price})
+	}
+	order, err := orders.NewOrder(userId, items)
+	if errors.Is(err, orders.ErrInvalidTotal) {
+		ctx.Status(http.StatusBadRequest)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "bad request",
+			Message:    err.Error(),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	order, err = o.Orders.Create(ctx.UserContext(), order)
+	if err != nil {
+		return err
+	}
+
+	ctx.Location(fmt.Sprintf("/user/%v/order/%v", order.UserID, order.ID))
+	ctx.Status(http.StatusCreated)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusCreated,
+		Status:     "created",
+		Message:    "success create order",
+		Data:       orderResponse(order),
+	})
+}
+
+// handler GET /user/:userId/order?status=pending&page=2, order terbaru lebih dulu
+func (o *OrderHandler) List(ctx *fiber.Ctx) error {
+	query := dto.OrderQuery{}
+	if err := binding.Bind(ctx, o.Validate, &query); err != nil {
+		return bindErrorResponse(ctx, err)
+	}
+	if ok, err := o.checkOwner(ctx, query.UserId, "only the user or an admin can list the orders of this user"); !ok {
+		return err
+	}
+
+	found, total, err := o.Orders.List(ctx.UserContext(), orders.Query{
+		UserID:  query.UserId,
+		Status:  orders.Status(query.Status),
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	items := make([]dto.Order, 0, len(found))
+	for _, order := range found {
+		items = append(items, orderResponse(order))
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success list orders",
+		Data:       dto.NewPage(items, query.Page, query.PerPage, total),
+	})
+}
+
+// handler GET /user/:userId/order/:orderId
+func (o *OrderHandler) Get(ctx *fiber.Ctx) error {
+	order, err := o.ownedOrder(ctx, "only the user or an admin can see this order")
+	if err != nil || order == nil {
+		return err
+	}
+	return orderOkResponse(ctx, "success get order", *order)
+}
+
+// handler POST /user/:userId/order/:orderId/cancel, hanya order pending atau paid
+func (o *OrderHandler) Cancel(ctx *fiber.Ctx) error {
+	order, err := o.ownedOrder(ctx, "only the user or an admin can cancel this order")
+	if err != nil || order == nil {
+		return err
+	}
+	return o.updateStatus(ctx, order.ID, orders.StatusCancelled, "success cancel order")
+}
+
+// handler PATCH /user/:userId/order/:orderId, hanya admin. status mengikuti transisi yang diizinkan
+func (o *OrderHandler) UpdateStatus(ctx *fiber.Ctx) error {
+	request := dto.UpdateOrderStatus{}
+	if err := render.Decode(ctx, &request); err != nil {
+		return decodeErrorResponse(ctx, err)
+	}
+	if err := o.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
+		return validationErrorResponse(ctx, err)
+	}
+
+	order, err := o.ownedOrder(ctx, "only admins can change the status of an order")
+	if err != nil || order == nil {
+		return err
+	}
+	if !middleware.GetCurrentUser(ctx).IsAdmin() {
+		return forbiddenResponse(ctx, "only admins can change the status of an order")
+	}
+	return o.updateStatus(ctx, order.ID, orders.Status(request.Status), "success update order")
+}
+
+func (o *OrderHandler) updateStatus(ctx *fiber.Ctx, id int, status orders.Status, message string) error {
+	order, err := o.Orders.UpdateStatus(ctx.UserContext(), id, status)
+	if errors.Is(err, orders.ErrInvalidTransition) {
+		ctx.Status(http.StatusConflict)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusConflict,
+			Status:     "conflict",
+			Message:    err.Error(),
+		})
+	}
+	if errors.Is(err, orders.ErrNotFound) {
+		return orderNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	return orderOkResponse(ctx, message, order)
+}
+
+// user yang login harus pemilik order (userId di path) atau admin.
+// false berarti response sudah dikirim
+func (o *OrderHandler) checkOwner(ctx *fiber.Ctx, userId string, forbidden string) (bool, error) {
+	current := middleware.GetCurrentUser(ctx)
+	if current == nil {
+		return false, loginRequired(ctx)
+	}
+	if current.ID != userId && !current.IsAdmin() {
+		return false, forbiddenResponse(ctx, forbidden)
+	}
+	return true, nil
+}
+
+// order dari param orderId yang boleh diakses user yang login. order milik user lain
+// daripada userId di path dianggap tidak ada. order nil dengan error nil berarti response sudah dikirim
+func (o *OrderHandler) ownedOrder(ctx *fiber.Ctx, forbidden string) (*orders.Order, error) {
+	params := dto.OrderParams{}
+	if err := binding.Bind(ctx, o.Validate, &params); err != nil {
+		return nil, bindErrorResponse(ctx, err)
+	}
+	if ok, err := o.checkOwner(ctx, params.UserId, forbidden); !ok {
+		return nil, err
+	}
+
+	order, err := o.Orders.Get(ctx.UserContext(), params.OrderId)
+	if errors.Is(err, orders.ErrNotFound) || (err == nil && order.UserID != params.UserId) {
+		return nil, orderNotFound(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func orderOkResponse(ctx *fiber.Ctx, message string, order orders.Order) error {
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    message,
+		Data:       orderResponse(order),
+	})
+}
+
+func orderNotFound(ctx *fiber.Ctx) error {
+	ctx.Status(http.StatusNotFound)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusNotFound,
+		Status:     "not found",
+		Message:    orders.ErrNotFound.Error(),
+	})
+}
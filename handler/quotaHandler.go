@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/quota"
+	"go_fiber/render"
+	"net/http"
+)
+
+// QuotaHandler reports the storage used by the current user.
+type QuotaHandler struct {
+	Quota *quota.Checker
+}
+
+// function provider
+func NewQuotaHandler(checker *quota.Checker) *QuotaHandler {
+	return &QuotaHandler{
+		Quota: checker,
+	}
+}
+
+// handler kuota user yang sedang login, contoh GET /me/quota
+func (q *QuotaHandler) Me(ctx *fiber.Ctx) error {
+	usage, err := q.Quota.Usage(ctx.UserContext(), fileOwner(ctx))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success get quota",
+		Data:       usage,
+	})
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/catalogue"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/sharing"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ShareHandler creates, lists and revokes signed share links of catalogue files.
+type ShareHandler struct {
+	Catalogue catalogue.Store
+	Links     *sharing.Store
+	Signer    *sharing.Signer
+	Config    sharing.Config
+	Validate  *validator.Validate
+}
+
+// link share dengan url yang sudah ditandatangani
+type sharedLink struct {
+	sharing.Link
+	URL string `json:"url,omitempty"`
+}
+
+// function provider
+func NewShareHandler(store catalogue.Store, links *sharing.Store, signer *sharing.Signer, config sharing.Config, validate *validator.Validate) *ShareHandler {
+	if config.DefaultExpiry <= 0 {
+		config.DefaultExpiry = 24 * time.Hour
+	}
+	if config.MaxExpiry <= 0 {
+		config.MaxExpiry = 7 * 24 * time.Hour
+	}
+	config.DefaultExpiry = min(config.DefaultExpiry, config.MaxExpiry)
+
+	return &ShareHandler{
+		Catalogue: store,
+		Links:     links,
+		Signer:    signer,
+		Config:    config,
+		Validate:  validate,
+	}
+}
+
+// handler POST /files/:id/share, body kosong memakai default_expiry
+func (s *ShareHandler) Create(ctx *fiber.Ctx) error {
+	request := dto.ShareRequest{}
+	if len(ctx.Body()) > 0 {
+		if err := render.Decode(ctx, &request); err != nil {
+			return decodeErrorResponse(ctx, err)
+		}
+	}
+	if err := s.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
+		return validationErrorResponse(ctx, err)
+	}
+
+	expiry := s.Config.DefaultExpiry
+	if request.ExpiresIn > 0 {
+		expiry = time.Duration(request.ExpiresIn) * time.Second
+	}
+	if expiry > s.Config.MaxExpiry {
+		ctx.Status(http.StatusBadRequest)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "bad request",
+			Message:    fmt.Sprintf("expires_in must be at most %v seconds", int(s.Config.MaxExpiry.Seconds())),
+		})
+	}
+
+	entry, err := s.ownedEntry(ctx, "only the owner can share this file")
+	if err != nil || entry == nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	link, err := s.Links.Create(sharing.Link{
+		FileID:    entry.ID,
+		Owner:     fileOwner(ctx),
+		CreatedAt: now,
+		ExpiresAt: now.Add(expiry).Truncate(time.Second),
+		SingleUse: request.SingleUse,
+		IP:        request.IP,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx.Status(http.StatusCreated)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusCreated,
+		Status:     "created",
+		Message:    "success create share link",
+		Data:       sharedLink{Link: *link, URL: s.linkURL(ctx, link)},
+	})
+}
+
+// handler GET /files/:id/share, daftar link beserta jumlah download
+func (s *ShareHandler) List(ctx *fiber.Ctx) error {
+	entry, err := s.ownedEntry(ctx, "only the owner can see the share links of this file")
+	if err != nil || entry == nil {
+		return err
+	}
+
+	links, err := s.Links.List(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success list share links",
+		Data:       links,
+	})
+}
+
+// handler DELETE /files/:id/share/:linkId, link yang dicabut tidak bisa dipakai lagi
+func (s *ShareHandler) Revoke(ctx *fiber.Ctx) error {
+	entry, err := s.ownedEntry(ctx, "only the owner can revoke share links of this file")
+	if err != nil || entry == nil {
+		return err
+	}
+
+	link, err := s.Links.Get(ctx.Params("linkId"))
+	if errors.Is(err, sharing.ErrLinkNotFound) || (err == nil && link.FileID != entry.ID) {
+		return linkNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if link, err = s.Links.Revoke(link.ID); err != nil {
+		return err
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success revoke share link",
+		Data:       link,
+	})
+}
+
+// entry catalogue dari param id, response 404/403 sudah ditulis jika entry nil
+func (s *ShareHandler) ownedEntry(ctx *fiber.Ctx, forbidden string) (*catalogue.Entry, error) {
+	entry, err := s.Catalogue.Get(ctx.UserContext(), ctx.Params("id"))
+	if errors.Is(err, catalogue.ErrNotFound) {
+		return nil, fileNotFound(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ownsFile(ctx, entry) {
+		return nil, forbiddenResponse(ctx, forbidden)
+	}
+	return &entry, nil
+}
+
+// url download yang ditandatangani, link dengan ip hanya bisa dipakai dari ip tersebut
+func (s *ShareHandler) linkURL(ctx *fiber.Ctx, link *sharing.Link) string {
+	expires := link.ExpiresAt.Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	if link.IP != "" {
+		query.Set("ip", "1")
+	}
+	query.Set("signature", s.Signer.Sign(link.ID, expires, link.IP))
+	return ctx.BaseURL() + "/shared/" + link.ID + "?" + query.Encode()
+}
+
+func linkNotFound(ctx *fiber.Ctx) error {
+	ctx.Status(http.StatusNotFound)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusNotFound,
+		Status:     "not found",
+		Message:    sharing.ErrLinkNotFound.Error(),
+	})
+}
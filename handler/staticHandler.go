@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// nama file hasil build seperti app.3f2a9c1b.css dianggap fingerprinted
+var fingerprintPattern = regexp.MustCompile(`\.[0-9a-fA-F]{8,}\.[A-Za-z0-9]+$`)
+
+// StaticMount is one entry of "static.mounts" in config.json. Root is a disk
+// directory, or "embed" for the assets embedded in the binary; Files is resolved from it.
+type StaticMount struct {
+	Prefix        string `mapstructure:"prefix"`
+	Root          string `mapstructure:"root"`
+	MaxAge        int    `mapstructure:"max_age"`
+	Precompressed bool   `mapstructure:"precompressed"`
+	Browse        bool   `mapstructure:"browse"`
+	SPA           bool   `mapstructure:"spa"`
+	Index         string `mapstructure:"index"`
+	AllowDotfiles bool   `mapstructure:"allow_dotfiles"`
+
+	Files fs.FS `mapstructure:"-"`
+}
+
+type StaticHandler struct {
+	Mount   StaticMount
+	digests sync.Map
+}
+
+// function provider
+func NewStaticHandler(mount StaticMount) *StaticHandler {
+	if mount.Index == "" {
+		mount.Index = "index.html"
+	}
+	mount.Prefix = "/" + strings.Trim(mount.Prefix, "/")
+
+	return &StaticHandler{
+		Mount: mount,
+	}
+}
+
+// handler static file, file yang tidak ada diteruskan ke route berikutnya
+func (s *StaticHandler) Serve(ctx *fiber.Ctx) error {
+	if ctx.Method() != http.MethodGet && ctx.Method() != http.MethodHead {
+		return ctx.Next()
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(ctx.Path(), s.Mount.Prefix)), "/")
+	if name == "" {
+		name = "."
+	}
+
+	// file & folder tersembunyi (.env, .git) tidak pernah dilayani
+	if !s.Mount.AllowDotfiles && isHidden(name) {
+		return ctx.Next()
+	}
+
+	info, err := fs.Stat(s.Mount.Files, name)
+	if err == nil && info.IsDir() {
+		index := path.Join(name, s.Mount.Index)
+		if indexInfo, err := fs.Stat(s.Mount.Files, index); err == nil && !indexInfo.IsDir() {
+			return s.serveFile(ctx, index, indexInfo)
+		}
+		if s.Mount.Browse {
+			return s.listDirectory(ctx, name)
+		}
+		return ctx.Next()
+	}
+
+	if err != nil {
+		// SPA: route client-side dilayani index.html
+		if s.Mount.SPA && path.Ext(name) == "" && ctx.Accepts(fiber.MIMETextHTML) == fiber.MIMETextHTML {
+			if indexInfo, err := fs.Stat(s.Mount.Files, s.Mount.Index); err == nil {
+				return s.serveFile(ctx, s.Mount.Index, indexInfo)
+			}
+		}
+		return ctx.Next()
+	}
+
+	return s.serveFile(ctx, name, info)
+}
+
+// file di-stream dari fs, tidak dibaca utuh ke memory. file yang bisa di-seek mendukung Range
+func (s *StaticHandler) serveFile(ctx *fiber.Ctx, name string, info fs.FileInfo) error {
+	ctx.Vary(fiber.HeaderAcceptEncoding)
+	served, encoding := name, ""
+	if s.Mount.Precompressed {
+		served, encoding, info = s.precompressed(ctx, name, info)
+	}
+
+	digest, err := s.digest(served, info)
+	if err != nil {
+		return err
+	}
+	etag := `"` + digest[:32] + `"`
+
+	file, err := s.Mount.Files.Open(served)
+	if err != nil {
+		return err
+	}
+	seeker, seekable := file.(io.ReadSeekCloser)
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+		if seekable {
+			contentType, err = detectContentType(seeker)
+			if err != nil {
+				file.Close()
+				return err
+			}
+		}
+	}
+	ctx.Set(fiber.HeaderContentType, contentType)
+	if encoding != "" {
+		ctx.Set(fiber.HeaderContentEncoding, encoding)
+	}
+	ctx.Set(fiber.HeaderCacheControl, s.cacheControl(ctx, name))
+	ctx.Set(fiber.HeaderETag, etag)
+
+	// file embed tidak punya mod time, cukup etag
+	modTime := info.ModTime()
+	if !modTime.IsZero() {
+		ctx.Set(fiber.HeaderLastModified, modTime.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(ctx, etag, modTime) {
+		file.Close()
+		return ctx.SendStatus(http.StatusNotModified)
+	}
+
+	if ctx.Method() == http.MethodHead {
+		file.Close()
+		if seekable {
+			ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+		}
+		ctx.Set(fiber.HeaderContentLength, strconv.FormatInt(info.Size(), 10))
+		return ctx.SendStatus(http.StatusOK)
+	}
+	if !seekable {
+		return ctx.SendStream(file, int(info.Size()))
+	}
+	return sendRange(ctx, seeker)
+}
+
+// content type dari 512 byte pertama, posisi file dikembalikan ke awal
+func detectContentType(file io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
+
+// pilih file .br / .gz jika ada dan didukung client
+func (s *StaticHandler) precompressed(ctx *fiber.Ctx, name string, info fs.FileInfo) (string, string, fs.FileInfo) {
+	acceptEncoding := ctx.Get(fiber.HeaderAcceptEncoding)
+	variants := []struct{ encoding, extension string }{{"br", ".br"}, {"gzip", ".gz"}}
+	for _, variant := range variants {
+		if !acceptsEncoding(acceptEncoding, variant.encoding) {
+			continue
+		}
+		if variantInfo, err := fs.Stat(s.Mount.Files, name+variant.extension); err == nil && !variantInfo.IsDir() {
+			return name + variant.extension, variant.encoding, variantInfo
+		}
+	}
+	return name, "", info
+}
+
+func (s *StaticHandler) cacheControl(ctx *fiber.Ctx, name string) string {
+	// asset dengan hash (nama file atau ?v= dari view.AssetHasher) tidak akan berubah.
+	// ?v= hanya dipercaya jika sama dengan hash isi file saat ini
+	if fingerprintPattern.MatchString(path.Base(name)) || s.currentVersion(name, ctx.Query("v")) {
+		return "public, max-age=31536000, immutable"
+	}
+	if s.Mount.MaxAge <= 0 {
+		return "no-cache"
+	}
+	return fmt.Sprintf("public, max-age=%d", s.Mount.MaxAge)
+}
+
+// ?v= dari view.AssetHasher adalah 10 karakter pertama sha256 isi file asli
+func (s *StaticHandler) currentVersion(name string, version string) bool {
+	if len(version) < 10 {
+		return false
+	}
+	info, err := fs.Stat(s.Mount.Files, name)
+	if err != nil {
+		return false
+	}
+	digest, err := s.digest(name, info)
+	return err == nil && strings.HasPrefix(digest, version)
+}
+
+// sha256 hex isi file, di-cache per nama, ukuran & mod time. isi file di-hash sambil dibaca
+func (s *StaticHandler) digest(name string, info fs.FileInfo) (string, error) {
+	key := fmt.Sprintf("%v:%d:%d", name, info.Size(), info.ModTime().UnixNano())
+	if digest, ok := s.digests.Load(key); ok {
+		return digest.(string), nil
+	}
+
+	file, err := s.Mount.Files.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	digest := hex.EncodeToString(hash.Sum(nil))
+	s.digests.Store(key, digest)
+	return digest, nil
+}
+
+func (s *StaticHandler) listDirectory(ctx *fiber.Ctx, name string) error {
+	entries, err := fs.ReadDir(s.Mount.Files, name)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	base := strings.TrimSuffix(s.Mount.Prefix+"/"+strings.TrimPrefix(name, "."), "/")
+	var builder strings.Builder
+	builder.WriteString("<!doctype html>\n<html><head><meta charset=\"UTF-8\"><title>Index of ")
+	builder.WriteString(html.EscapeString(base + "/"))
+	builder.WriteString("</title></head><body><ul>\n")
+	for _, entry := range entries {
+		if !s.Mount.AllowDotfiles && strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		display := entry.Name()
+		if entry.IsDir() {
+			display += "/"
+		}
+		fmt.Fprintf(&builder, "<li><a href=\"%v\">%v</a></li>\n",
+			html.EscapeString(base+"/"+display), html.EscapeString(display))
+	}
+	builder.WriteString("</ul></body></html>\n")
+
+	ctx.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	return ctx.SendString(builder.String())
+}
+
+func notModified(ctx *fiber.Ctx, etag string, modTime time.Time) bool {
+	if match := ctx.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == etag || candidate == "*" {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := ctx.Get(fiber.HeaderIfModifiedSince); since != "" && !modTime.IsZero() {
+		if parsed, err := http.ParseTime(since); err == nil {
+			return !modTime.Truncate(time.Second).After(parsed)
+		}
+	}
+	return false
+}
+
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(name) == encoding && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+func isHidden(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") && segment != "." {
+			return true
+		}
+	}
+	return false
+}
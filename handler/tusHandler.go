@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/quota"
+	"go_fiber/render"
+	"go_fiber/upload"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,termination,expiration,checksum"
+
+	// status code dari ekstensi checksum tus
+	StatusChecksumMismatch = 460
+
+	mimeOffsetOctetStream = "application/offset+octet-stream"
+)
+
+// TusHandler implements the tus 1.0 resumable upload protocol on top of upload.TusStore.
+// When Quota is set an upload that would exceed it is refused on creation.
+type TusHandler struct {
+	Store *upload.TusStore
+	Quota *quota.Checker
+}
+
+// function provider
+func NewTusHandler(store *upload.TusStore) *TusHandler {
+	return &TusHandler{
+		Store: store,
+	}
+}
+
+// semua request kecuali OPTIONS wajib mengirim Tus-Resumable versi yang didukung
+func (t *TusHandler) RequireResumable(ctx *fiber.Ctx) error {
+	ctx.Set("Tus-Resumable", TusVersion)
+	if ctx.Method() != http.MethodOptions && ctx.Get("Tus-Resumable") != TusVersion {
+		ctx.Set("Tus-Version", TusVersion)
+		return tusErrorResponse(ctx, http.StatusPreconditionFailed, "unsupported tus version")
+	}
+	return ctx.Next()
+}
+
+// handler OPTIONS, informasi kemampuan server
+func (t *TusHandler) Options(ctx *fiber.Ctx) error {
+	ctx.Set("Tus-Version", TusVersion)
+	ctx.Set("Tus-Extension", TusExtensions)
+	ctx.Set("Tus-Checksum-Algorithm", strings.Join(upload.ChecksumAlgorithms(), ","))
+	if t.Store.MaxSize() > 0 {
+		ctx.Set("Tus-Max-Size", strconv.FormatInt(t.Store.MaxSize(), 10))
+	}
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+// handler POST, membuat upload baru
+func (t *TusHandler) Create(ctx *fiber.Ctx) error {
+	length, err := strconv.ParseInt(ctx.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return tusErrorResponse(ctx, http.StatusBadRequest, "invalid Upload-Length header")
+	}
+	if t.Store.MaxSize() > 0 && length > t.Store.MaxSize() {
+		return tusErrorResponse(ctx, http.StatusRequestEntityTooLarge, "upload exceeds Tus-Max-Size")
+	}
+
+	metadata, err := upload.ParseMetadata(ctx.Get("Upload-Metadata"))
+	if err != nil {
+		return tusErrorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// Upload-Length sudah pasti, kuota dicek sebelum data dikirim
+	if t.Quota != nil {
+		if err := t.Quota.Check(ctx.UserContext(), fileOwner(ctx), length); err != nil {
+			return t.storeErrorResponse(ctx, err)
+		}
+	}
+
+	created, err := t.Store.Create(length, metadata, fileOwner(ctx))
+	if err != nil {
+		return err
+	}
+
+	// upload kosong langsung selesai
+	if length == 0 {
+		if created, err = t.Store.WriteChunk(ctx.UserContext(), created.ID, 0, strings.NewReader(""), nil); err != nil {
+			return err
+		}
+	}
+
+	ctx.Location(ctx.BaseURL() + strings.TrimSuffix(ctx.Path(), "/") + "/" + created.ID)
+	setUploadHeaders(ctx, created)
+	return ctx.SendStatus(http.StatusCreated)
+}
+
+// handler HEAD, offset upload untuk melanjutkan
+func (t *TusHandler) Head(ctx *fiber.Ctx) error {
+	found, err := t.ownedUpload(ctx)
+	if err != nil {
+		return t.storeErrorResponse(ctx, err)
+	}
+
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Set("Upload-Length", strconv.FormatInt(found.Length, 10))
+	if len(found.Metadata) > 0 {
+		ctx.Set("Upload-Metadata", upload.EncodeMetadata(found.Metadata))
+	}
+	setUploadHeaders(ctx, found)
+	return ctx.SendStatus(http.StatusOK)
+}
+
+// handler PATCH, menulis satu chunk di Upload-Offset
+func (t *TusHandler) Patch(ctx *fiber.Ctx) error {
+	if ctx.Get(fiber.HeaderContentType) != mimeOffsetOctetStream {
+		return tusErrorResponse(ctx, http.StatusUnsupportedMediaType, "Content-Type must be "+mimeOffsetOctetStream)
+	}
+
+	offset, err := strconv.ParseInt(ctx.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return tusErrorResponse(ctx, http.StatusBadRequest, "invalid Upload-Offset header")
+	}
+
+	var checksum *upload.Checksum
+	if header := ctx.Get("Upload-Checksum"); header != "" {
+		if checksum, err = upload.ParseChecksum(header); err != nil {
+			return tusErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		}
+	}
+
+	if _, err := t.ownedUpload(ctx); err != nil {
+		return t.storeErrorResponse(ctx, err)
+	}
+	written, err := t.Store.WriteChunk(ctx.UserContext(), ctx.Params("id"), offset, upload.Body(ctx), checksum)
+	if err != nil {
+		return t.storeErrorResponse(ctx, err)
+	}
+
+	setUploadHeaders(ctx, written)
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+// handler DELETE, ekstensi termination
+func (t *TusHandler) Terminate(ctx *fiber.Ctx) error {
+	if _, err := t.ownedUpload(ctx); err != nil {
+		return t.storeErrorResponse(ctx, err)
+	}
+	if err := t.Store.Terminate(ctx.Params("id")); err != nil {
+		return t.storeErrorResponse(ctx, err)
+	}
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+// upload dari param id, upload milik user lain dianggap tidak ada
+func (t *TusHandler) ownedUpload(ctx *fiber.Ctx) (*upload.TusUpload, error) {
+	found, err := t.Store.Get(ctx.Params("id"))
+	if err != nil {
+		return nil, err
+	}
+	if found.Owner != fileOwner(ctx) {
+		return nil, upload.ErrUploadNotFound
+	}
+	return found, nil
+}
+
+func setUploadHeaders(ctx *fiber.Ctx, found *upload.TusUpload) {
+	ctx.Set("Upload-Offset", strconv.FormatInt(found.Offset, 10))
+	if !found.Completed() {
+		ctx.Set("Upload-Expires", found.ExpiresAt.Format(http.TimeFormat))
+	}
+}
+
+func (t *TusHandler) storeErrorResponse(ctx *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, upload.ErrUploadNotFound):
+		return tusErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, upload.ErrUploadExpired):
+		return tusErrorResponse(ctx, http.StatusGone, err.Error())
+	case errors.Is(err, upload.ErrOffsetMismatch):
+		return tusErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, upload.ErrExceedsLength), errors.Is(err, upload.ErrBodyTooLarge), errors.Is(err, upload.ErrQuotaExceeded):
+		return tusErrorResponse(ctx, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, upload.ErrInsufficientStorage):
+		return tusErrorResponse(ctx, http.StatusInsufficientStorage, err.Error())
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		return tusErrorResponse(ctx, StatusChecksumMismatch, err.Error())
+	case errors.Is(err, upload.ErrRejected):
+		return tusErrorResponse(ctx, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, upload.ErrUnavailable):
+		return tusErrorResponse(ctx, http.StatusServiceUnavailable, err.Error())
+	}
+	// error storage & timeout diteruskan ke error handler / middleware timeout
+	return err
+}
+
+// response error tus, sisa body chunk tidak dibaca sehingga koneksi ditutup
+func tusErrorResponse(ctx *fiber.Ctx, code int, message string) error {
+	if ctx.Method() == http.MethodPatch {
+		ctx.Context().SetConnectionClose()
+	}
+
+	status := http.StatusText(code)
+	if code == StatusChecksumMismatch {
+		status = "Checksum Mismatch"
+	}
+
+	ctx.Status(code)
+	if ctx.Method() == http.MethodHead {
+		return nil
+	}
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     strings.ToLower(status),
+		Message:    message,
+	})
+}
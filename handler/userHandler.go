@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/binding"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/users"
+	"net/http"
+	"time"
+)
+
+// UserHandler manages registered users. Users can read, update and delete their own
+// account; admins can do so for every user, list all users and restore deleted ones.
+type UserHandler struct {
+	Users    users.Store
+	Config   users.Config
+	Validate *validator.Validate
+}
+
+// function provider
+func NewUserHandler(store users.Store, config users.Config, validate *validator.Validate) *UserHandler {
+	return &UserHandler{
+		Users:    store,
+		Config:   config,
+		Validate: validate,
+	}
+}
+
+// user di response, tanpa hash password
+func userResponse(user users.User) dto.User {
+	return dto.User{
+		ID:        user.ID,
+		Username:  user.Username,
+		Name:      user.Name,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		DeletedAt: user.DeletedAt,
+	}
+}
+
+// handler user yang sedang login, contoh GET /me
+func (u *UserHandler) Me(ctx *fiber.Ctx) error {
+	current := middleware.GetCurrentUser(ctx)
+	if current == nil {
+		return loginRequired(ctx)
+	}
+	return userOkResponse(ctx, "success get user", *current)
+}
+
+// handler daftar user, hanya admin. contoh GET /users?name=reo&page=2
+func (u *UserHandler) List(ctx *fiber.Ctx) error {
+	current := middleware.GetCurrentUser(ctx)
+	if current == nil {
+		return loginRequired(ctx)
+	}
+	if !current.IsAdmin() {
+		return forbiddenResponse(ctx, "only admins can list users")
+	}
+
+	query := dto.UserQuery{}
+	if err := binding.Bind(ctx, u.Validate, &query); err != nil {
+		return bindErrorResponse(ctx, err)
+	}
+
+	found, total, err := u.Users.List(ctx.UserContext(), users.Query{
+		Name:    query.Name,
+		Email:   query.Email,
+		Deleted: query.Deleted,
+		Page:    query.Page,
+		PerPage: query.PerPage,
+	})
+	if err != nil {
+		return err
+	}
+
+	items := make([]dto.User, 0, len(found))
+	for _, user := range found {
+		items = append(items, userResponse(user))
+	}
+
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    "success list users",
+		Data:       dto.NewPage(items, query.Page, query.PerPage, total),
+	})
+}
+
+// handler GET /users/:id
+func (u *UserHandler) Get(ctx *fiber.Ctx) error {
+	user, err := u.accessibleUser(ctx, "only the user or an admin can see this user")
+	if err != nil || user == nil {
+		return err
+	}
+	return userOkResponse(ctx, "success get user", *user)
+}
+
+// handler PATCH /users/:id, hanya field yang dikirim yang diubah
+func (u *UserHandler) Update(ctx *fiber.Ctx) error {
+	request := dto.UpdateUser{}
+	if err := render.Decode(ctx, &request); err != nil {
+		return decodeErrorResponse(ctx, err)
+	}
+	if err := u.Validate.StructCtx(ctx.UserContext(), &request); err != nil {
+		return validationErrorResponse(ctx, err)
+	}
+
+	user, err := u.accessibleUser(ctx, "only the user or an admin can update this user")
+	if err != nil || user == nil {
+		return err
+	}
+	if request.Role != nil && *request.Role != user.Role && !middleware.GetCurrentUser(ctx).IsAdmin() {
+		return forbiddenResponse(ctx, "only admins can change the role of a user")
+	}
+
+	if request.Username != nil {
+		user.Username = *request.Username
+	}
+	if request.Name != nil {
+		user.Name = *request.Name
+	}
+	if request.Role != nil {
+		user.Role = *request.Role
+	}
+	if request.Password != nil {
+		if err := u.Config.SetPassword(user, *request.Password); err != nil {
+			return err
+		}
+	}
+
+	if err := u.Users.Update(ctx.UserContext(), *user); err != nil {
+		if errors.Is(err, users.ErrUsernameTaken) {
+			return usernameTaken(ctx)
+		}
+		return err
+	}
+
+	updated, err := u.Users.Get(ctx.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return userOkResponse(ctx, "success update user", updated)
+}
+
+// handler DELETE /users/:id, soft delete. user bisa di-restore admin dan username tetap dipakai
+func (u *UserHandler) Delete(ctx *fiber.Ctx) error {
+	user, err := u.accessibleUser(ctx, "only the user or an admin can delete this user")
+	if err != nil || user == nil {
+		return err
+	}
+
+	if !user.Deleted() {
+		now := time.Now().UTC()
+		user.DeletedAt = &now
+		if err := u.Users.Update(ctx.UserContext(), *user); err != nil {
+			return err
+		}
+	}
+	return userOkResponse(ctx, "success delete user", *user)
+}
+
+// handler POST /users/:id/restore, hanya admin. restore user yang tidak dihapus tidak mengubah apa-apa
+func (u *UserHandler) Restore(ctx *fiber.Ctx) error {
+	current := middleware.GetCurrentUser(ctx)
+	if current == nil {
+		return loginRequired(ctx)
+	}
+	if !current.IsAdmin() {
+		return forbiddenResponse(ctx, "only admins can restore users")
+	}
+
+	user, err := u.Users.Get(ctx.UserContext(), ctx.Params("id"))
+	if errors.Is(err, users.ErrNotFound) {
+		return userNotFound(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	if user.Deleted() {
+		user.DeletedAt = nil
+		if err := u.Users.Update(ctx.UserContext(), user); err != nil {
+			return err
+		}
+	}
+	return userOkResponse(ctx, "success restore user", user)
+}
+
+// user dari param id yang boleh diakses user yang login: dirinya sendiri atau semua user
+// untuk admin. user yang sudah dihapus hanya terlihat oleh admin.
+// user nil dengan error nil berarti response sudah dikirim
+func (u *UserHandler) accessibleUser(ctx *fiber.Ctx, forbidden string) (*users.User, error) {
+	current := middleware.GetCurrentUser(ctx)
+	if current == nil {
+		return nil, loginRequired(ctx)
+	}
+	if current.ID != ctx.Params("id") && !current.IsAdmin() {
+		return nil, forbiddenResponse(ctx, forbidden)
+	}
+
+	user, err := u.Users.Get(ctx.UserContext(), ctx.Params("id"))
+	if errors.Is(err, users.ErrNotFound) || (err == nil && user.Deleted() && !current.IsAdmin()) {
+		return nil, userNotFound(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func userOkResponse(ctx *fiber.Ctx, message string, user users.User) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Status(http.StatusOK)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusOK,
+		Status:     "ok",
+		Message:    message,
+		Data:       userResponse(user),
+	})
+}
+
+func loginRequired(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="users", charset="UTF-8"`)
+	ctx.Status(http.StatusUnauthorized)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusUnauthorized,
+		Status:     "unauthorized",
+		Message:    "login required",
+	})
+}
+
+func usernameTaken(ctx *fiber.Ctx) error {
+	ctx.Status(http.StatusConflict)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusConflict,
+		Status:     "conflict",
+		Message:    users.ErrUsernameTaken.Error(),
+	})
+}
+
+func userNotFound(ctx *fiber.Ctx) error {
+	ctx.Status(http.StatusNotFound)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusNotFound,
+		Status:     "not found",
+		Message:    users.ErrNotFound.Error(),
+	})
+}
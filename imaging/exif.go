@@ -0,0 +1,186 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+const (
+	markerSOI  = 0xD8
+	markerSOS  = 0xDA
+	markerAPP0 = 0xE0
+	markerAPP1 = 0xE1
+	markerAPP2 = 0xE2
+	markerAPPD = 0xED
+	markerCOM  = 0xFE
+
+	tagOrientation = 0x0112
+)
+
+var (
+	exifHeader = []byte("Exif\x00\x00")
+	pngHeader  = []byte("\x89PNG\r\n\x1a\n")
+)
+
+// chunk png yang bisa berisi metadata pribadi (exif, teks, waktu)
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true, "tEXt": true, "iTXt": true, "zTXt": true, "tIME": true,
+}
+
+// Orientation reads the EXIF orientation (1-8) of a JPEG, 1 when it is missing.
+func Orientation(data []byte) int {
+	orientation := 1
+	walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == markerAPP1 && bytes.HasPrefix(segment, exifHeader) {
+			if value := parseOrientation(segment[len(exifHeader):]); value >= 1 && value <= 8 {
+				orientation = value
+			}
+			return false
+		}
+		return true
+	})
+	return orientation
+}
+
+// cari tag orientation di IFD0 dari header TIFF
+func parseOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		if order.Uint16(tiff[entry:]) == tagOrientation {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 0
+}
+
+// StripJPEG removes EXIF, XMP, IPTC and comment segments without re-encoding the image.
+// The orientation is kept in a minimal EXIF segment so the image still displays upright.
+func StripJPEG(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return data
+	}
+
+	// segment orientation ditaruh setelah APP0 (JFIF) yang harus berada tepat setelah SOI
+	orientation := Orientation(data)
+	pendingOrientation := orientation != 1
+
+	stripped := bytes.NewBuffer(make([]byte, 0, len(data)))
+	stripped.Write([]byte{0xFF, markerSOI})
+	rest := walkJPEG(data, func(marker byte, segment []byte) bool {
+		if pendingOrientation && marker != markerAPP0 {
+			stripped.Write(orientationSegment(orientation))
+			pendingOrientation = false
+		}
+
+		switch marker {
+		case markerAPP1, markerAPPD, markerCOM:
+			return true
+		}
+		// APP2 hanya dipertahankan untuk ICC profile
+		if marker == markerAPP2 && !bytes.HasPrefix(segment, []byte("ICC_PROFILE\x00")) {
+			return true
+		}
+		stripped.Write([]byte{0xFF, marker})
+		binary.Write(stripped, binary.BigEndian, uint16(len(segment)+2))
+		stripped.Write(segment)
+		return true
+	})
+	if pendingOrientation {
+		stripped.Write(orientationSegment(orientation))
+	}
+	stripped.Write(rest)
+	return stripped.Bytes()
+}
+
+// segment APP1 exif yang hanya berisi tag orientation
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // header big endian, IFD0 di offset 8
+		0x00, 0x01, // satu entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, byte(orientation), 0x00, 0x00, // orientation SHORT
+		0x00, 0x00, 0x00, 0x00, // tidak ada IFD berikutnya
+	}
+
+	segment := []byte{0xFF, markerAPP1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(exifHeader)+len(tiff)))
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+// walkJPEG calls visit for every header segment up to the start of scan and returns the
+// remaining bytes (SOS and the entropy coded data). visit returns false to stop early.
+func walkJPEG(data []byte, visit func(marker byte, segment []byte) bool) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != markerSOI {
+		return nil
+	}
+
+	position := 2
+	for position+4 <= len(data) {
+		if data[position] != 0xFF {
+			return data[position:]
+		}
+		marker := data[position+1]
+		if marker == markerSOS {
+			return data[position:]
+		}
+
+		length := int(binary.BigEndian.Uint16(data[position+2:]))
+		if length < 2 || position+2+length > len(data) {
+			return data[position:]
+		}
+		if !visit(marker, data[position+4:position+2+length]) {
+			return nil
+		}
+		position += 2 + length
+	}
+	return data[position:]
+}
+
+// StripPNG removes metadata chunks (eXIf, text and time) from a PNG.
+func StripPNG(data []byte) []byte {
+	if !bytes.HasPrefix(data, pngHeader) {
+		return data
+	}
+
+	stripped := bytes.NewBuffer(make([]byte, 0, len(data)))
+	stripped.Write(pngHeader)
+
+	position := len(pngHeader)
+	for position+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[position:]))
+		end := position + 12 + length
+		if end > len(data) {
+			break
+		}
+
+		if !pngMetadataChunks[string(data[position+4:position+8])] {
+			stripped.Write(data[position:end])
+		}
+		position = end
+	}
+	// sisa data yang tidak bisa di-parse tetap disimpan apa adanya
+	stripped.Write(data[position:])
+	return stripped.Bytes()
+}
@@ -0,0 +1,206 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"image"
+	_ "image/gif" // decoder gif untuk image.Decode
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+var ErrImageTooLarge = fmt.Errorf("%w : image dimensions or size exceed the limit", upload.ErrRejected)
+
+// Variant is one generated size of an uploaded image.
+type Variant struct {
+	Name   string `mapstructure:"name"`
+	Width  int    `mapstructure:"width"`
+	Height int    `mapstructure:"height"`
+	Mode   string `mapstructure:"mode"`
+}
+
+// Config is loaded from the "images" section of config.json. Images over MaxPixels or
+// MaxBytes are refused before they are read into memory.
+type Config struct {
+	Variants      []Variant `mapstructure:"variants"`
+	Quality       int       `mapstructure:"quality"`
+	StripMetadata bool      `mapstructure:"strip_metadata"`
+	MaxPixels     int       `mapstructure:"max_pixels"`
+	MaxBytes      int64     `mapstructure:"max_bytes"`
+}
+
+// Processor generates the configured variants for uploaded images. It implements
+// upload.Processor; files that are not JPEG, PNG or GIF are left untouched.
+type Processor struct {
+	config  Config
+	storage storage.Storage
+}
+
+// function provider
+func NewProcessor(config Config, store storage.Storage) *Processor {
+	if config.Quality <= 0 {
+		config.Quality = 85
+	}
+	if config.MaxPixels <= 0 {
+		config.MaxPixels = 40_000_000
+	}
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 50 << 20
+	}
+
+	return &Processor{
+		config:  config,
+		storage: store,
+	}
+}
+
+// nama file variant di storage, disimpan di samping file asli
+func VariantName(name string, variant string) string {
+	return name + "." + variant
+}
+
+func (p *Processor) HasVariant(name string) bool {
+	for _, variant := range p.config.Variants {
+		if variant.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Processor) Process(ctx context.Context, file *upload.File) error {
+	reader, err := p.storage.Open(ctx, file.ID)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	// jenis file dideteksi dari 512 byte pertama, bukan dari content type yang dikirim client
+	header := make([]byte, 512)
+	n, err := io.ReadFull(reader, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return err
+	}
+	contentType := http.DetectContentType(header[:n])
+	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/gif" {
+		return nil
+	}
+	file.ContentType = contentType
+	if file.Size > p.config.MaxBytes {
+		return ErrImageTooLarge
+	}
+
+	// ukuran gambar dibaca dari header dulu, byte yang sudah dibaca disimpan untuk decode
+	buffered := &bytes.Buffer{}
+	config, _, err := image.DecodeConfig(io.TeeReader(io.MultiReader(bytes.NewReader(header[:n]), reader), buffered))
+	if err != nil {
+		// bukan gambar yang valid, disimpan apa adanya tanpa variant
+		return nil
+	}
+	if config.Width*config.Height > p.config.MaxPixels {
+		return ErrImageTooLarge
+	}
+
+	// hanya gambar yang lolos batas dibaca seluruhnya ke memory
+	if _, err := buffered.ReadFrom(io.LimitReader(reader, p.config.MaxBytes+1-int64(buffered.Len()))); err != nil {
+		return err
+	}
+	if int64(buffered.Len()) > p.config.MaxBytes {
+		return ErrImageTooLarge
+	}
+	original := buffered.Bytes()
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = Orientation(original)
+	}
+
+	if p.config.StripMetadata {
+		if err := p.stripOriginal(ctx, file, contentType, original); err != nil {
+			return err
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(original))
+	if err != nil {
+		return nil
+	}
+
+	for _, variant := range p.config.Variants {
+		width, height := variant.Width, variant.Height
+		if orientation >= 5 {
+			width, height = height, width
+		}
+		resized := Orient(Resize(img, width, height, variant.Mode), orientation)
+
+		name := VariantName(file.ID, variant.Name)
+		encoded, err := p.encode(resized, contentType)
+		if err != nil {
+			return err
+		}
+		size, err := p.storage.Save(ctx, name, bytes.NewReader(encoded))
+		if err != nil {
+			return err
+		}
+		file.Variants = append(file.Variants, variant.Name)
+		file.VariantSize += size
+	}
+	return nil
+}
+
+// hapus semua variant yang mungkin sudah tersimpan, termasuk dari Process yang gagal di tengah
+func (p *Processor) Revert(ctx context.Context, file *upload.File) error {
+	for _, variant := range p.config.Variants {
+		if err := p.storage.Remove(ctx, VariantName(file.ID, variant.Name)); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	file.Variants = nil
+	file.VariantSize = 0
+	return nil
+}
+
+// tulis ulang file asli tanpa metadata, gambar tidak di-encode ulang
+func (p *Processor) stripOriginal(ctx context.Context, file *upload.File, contentType string, original []byte) error {
+	var stripped []byte
+	switch contentType {
+	case "image/jpeg":
+		stripped = StripJPEG(original)
+	case "image/png":
+		stripped = StripPNG(original)
+	default:
+		return nil
+	}
+	if len(stripped) == len(original) {
+		return nil
+	}
+
+	size, err := p.storage.Save(ctx, file.ID, bytes.NewReader(stripped))
+	if err != nil {
+		return err
+	}
+	checksum := sha256.Sum256(stripped)
+	file.Size = size
+	file.Checksum = hex.EncodeToString(checksum[:])
+	return nil
+}
+
+// variant di-encode tanpa metadata, jpeg untuk foto dan png untuk lainnya
+func (p *Processor) encode(img image.Image, contentType string) ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	var err error
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: p.config.Quality})
+	} else {
+		err = png.Encode(buffer, img)
+	}
+	return buffer.Bytes(), err
+}
@@ -0,0 +1,77 @@
+package imaging
+
+import (
+	"golang.org/x/image/draw"
+	"image"
+)
+
+const (
+	ModeFit  = "fit"
+	ModeFill = "fill"
+)
+
+// Resize scales img into a width x height box. ModeFit keeps the whole image inside
+// the box, ModeFill covers the box and crops the center. Images are never upscaled.
+func Resize(img image.Image, width int, height int, mode string) image.Image {
+	bounds := img.Bounds()
+	source := bounds
+	targetWidth, targetHeight := bounds.Dx(), bounds.Dy()
+
+	switch mode {
+	case ModeFill:
+		// potong bagian tengah dengan rasio box, lalu perkecil
+		scale := min(float64(bounds.Dx())/float64(width), float64(bounds.Dy())/float64(height))
+		cropWidth, cropHeight := int(float64(width)*scale), int(float64(height)*scale)
+		x := bounds.Min.X + (bounds.Dx()-cropWidth)/2
+		y := bounds.Min.Y + (bounds.Dy()-cropHeight)/2
+		source = image.Rect(x, y, x+cropWidth, y+cropHeight)
+		targetWidth, targetHeight = min(width, cropWidth), min(height, cropHeight)
+	default:
+		scale := min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()), 1)
+		targetWidth = max(1, int(float64(bounds.Dx())*scale+0.5))
+		targetHeight = max(1, int(float64(bounds.Dy())*scale+0.5))
+	}
+
+	resized := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	draw.CatmullRom.Scale(resized, resized.Bounds(), img, source, draw.Src, nil)
+	return resized
+}
+
+// Orient rotates and flips img according to an EXIF orientation so it displays upright.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	outWidth, outHeight := width, height
+	if orientation >= 5 {
+		outWidth, outHeight = height, width
+	}
+
+	oriented := image.NewNRGBA(image.Rect(0, 0, outWidth, outHeight))
+	for y := 0; y < outHeight; y++ {
+		for x := 0; x < outWidth; x++ {
+			var sourceX, sourceY int
+			switch orientation {
+			case 2: // flip horizontal
+				sourceX, sourceY = width-1-x, y
+			case 3: // rotate 180
+				sourceX, sourceY = width-1-x, height-1-y
+			case 4: // flip vertical
+				sourceX, sourceY = x, height-1-y
+			case 5: // transpose
+				sourceX, sourceY = y, x
+			case 6: // rotate 90 searah jarum jam
+				sourceX, sourceY = y, height-1-x
+			case 7: // transverse
+				sourceX, sourceY = width-1-y, height-1-x
+			case 8: // rotate 90 berlawanan jarum jam
+				sourceX, sourceY = width-1-y, x
+			}
+			oriented.Set(x, y, img.At(bounds.Min.X+sourceX, bounds.Min.Y+sourceY))
+		}
+	}
+	return oriented
+}
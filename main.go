@@ -1,16 +1,37 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
 	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/template/mustache/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/spf13/viper"
 	"go_fiber/Routes"
+	"go_fiber/archive"
+	"go_fiber/catalogue"
+	"go_fiber/encryption"
 	"go_fiber/handler"
+	"go_fiber/imaging"
 	"go_fiber/middleware"
+	"go_fiber/openapi"
+	"go_fiber/orders"
+	"go_fiber/public"
+	"go_fiber/quota"
+	"go_fiber/scanning"
+	"go_fiber/server"
+	"go_fiber/sharing"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"go_fiber/users"
+	"go_fiber/view"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -22,44 +43,360 @@ func main() {
 	if err := config.ReadInConfig(); err != nil {
 		log.Fatalf("error cant load config.json")
 	}
+	// default production, development dipilih lokal dengan APP_ENV=development
+	if err := config.BindEnv("app.env", "APP_ENV"); err != nil {
+		log.Fatalf("error cant bind APP_ENV : %v", err)
+	}
+
+	// recover panic, event dikirim ke sentry jika sentry_dsn diset
+	var recoverConfig middleware.RecoverConfig
+	if err := config.UnmarshalKey("recover", &recoverConfig); err != nil {
+		log.Fatalf("error cant load recover config : %v", err)
+	}
+	recoverConfig.Environment = config.GetString("app.env")
+	recoverMiddleware, err := middleware.NewRecoverMiddleware(recoverConfig)
+	if err != nil {
+		log.Fatalf("error cant create recover middleware : %v", err)
+	}
+
+	// load cors & security headers policy
+	var corsConfig middleware.CorsConfig
+	if err := config.UnmarshalKey("cors", &corsConfig); err != nil {
+		log.Fatalf("error cant load cors config : %v", err)
+	}
+	var securityConfig middleware.SecurityHeadersConfig
+	if err := config.UnmarshalKey("security", &securityConfig); err != nil {
+		log.Fatalf("error cant load security config : %v", err)
+	}
+
+	// load tls config, prefork tidak didukung fiber untuk custom listener tls
+	var tlsConfig server.TLSConfig
+	if err := config.UnmarshalKey("tls", &tlsConfig); err != nil {
+		log.Fatalf("error cant load tls config : %v", err)
+	}
+
+	// validasi request terhadap openapi, validasi response hanya di development
+	var validatorConfig middleware.OpenApiValidatorConfig
+	if err := config.UnmarshalKey("openapi_validation", &validatorConfig); err != nil {
+		log.Fatalf("error cant load openapi_validation config : %v", err)
+	}
+	validatorConfig.ValidateResponses = validatorConfig.ValidateResponses && config.GetString("app.env") == "development"
+
+	var maintenanceConfig middleware.MaintenanceConfig
+	if err := config.UnmarshalKey("maintenance", &maintenanceConfig); err != nil {
+		log.Fatalf("error cant load maintenance config : %v", err)
+	}
+
+	// timeout per route, context request dibatalkan ketika timeout terlewati
+	var timeoutConfig middleware.TimeoutConfig
+	if err := config.UnmarshalKey("timeouts", &timeoutConfig); err != nil {
+		log.Fatalf("error cant load timeouts config : %v", err)
+	}
+
+	// limit body per route, route upload dibaca secara streaming
+	var bodyLimitConfig middleware.BodyLimitConfig
+	if err := config.UnmarshalKey("body_limits", &bodyLimitConfig); err != nil {
+		log.Fatalf("error cant load body_limits config : %v", err)
+	}
+
+	// upload resumable tus, upload yang selesai disimpan di storage yang sama dengan /upload-file
+	var tusConfig upload.TusConfig
+	if err := config.UnmarshalKey("tus", &tusConfig); err != nil {
+		log.Fatalf("error cant load tus config : %v", err)
+	}
+	// file disimpan per hash konten, upload dengan isi sama hanya disimpan sekali
+	uploadStorage := storage.NewContentStorage(filepath.Join("multipart", "target"))
+
+	// enkripsi file at rest, setiap file punya data key sendiri yang di-wrap master key.
+	// upload tus yang belum selesai di tus.directory tetap tanpa enkripsi sampai selesai
+	var encryptionConfig encryption.Config
+	if err := config.UnmarshalKey("encryption", &encryptionConfig); err != nil {
+		log.Fatalf("error cant load encryption config : %v", err)
+	}
+	if encryptionConfig.Enabled {
+		keyring, err := encryptionConfig.Keyring()
+		if err != nil {
+			log.Fatalf("error invalid encryption config : %v", err)
+		}
+		uploadStorage.Cipher = keyring
+	}
+
+	// "rotate-keys" me-wrap ulang data key semua file dengan master key sekarang lalu berhenti,
+	// master key lama harus tetap ada di encryption.previous_keys selama rotasi
+	if len(os.Args) > 1 && os.Args[1] == "rotate-keys" {
+		rewrapped, err := uploadStorage.Rewrap(context.Background())
+		if err != nil {
+			log.Fatalf("error cant rotate keys : %v", err)
+		}
+		log.Printf("rewrapped %v files with master key %v", rewrapped, encryptionConfig.KeyID)
+		return
+	}
+	tusStore := upload.NewTusStore(tusConfig, uploadStorage)
+
+	// variant gambar dibuat setelah upload selesai
+	var imagesConfig imaging.Config
+	if err := config.UnmarshalKey("images", &imagesConfig); err != nil {
+		log.Fatalf("error cant load images config : %v", err)
+	}
+	imageProcessor := imaging.NewProcessor(imagesConfig, uploadStorage)
+
+	// scan malware dengan clamd, file dikarantina sampai hasil scan bersih
+	var scanConfig scanning.ClamdConfig
+	if err := config.UnmarshalKey("scanning", &scanConfig); err != nil {
+		log.Fatalf("error cant load scanning config : %v", err)
+	}
+	// karantina tidak bergantung scanning.enabled: tanpa clamd hanya development yang
+	// melepas file tanpa scan, di luar development upload tetap dikarantina
+	quarantine := scanning.NewQuarantine(uploadStorage)
+	var uploadPipeline upload.Pipeline
+	switch {
+	case scanConfig.Enabled:
+		uploadPipeline = append(uploadPipeline, scanning.NewProcessor(scanning.NewClamdScanner(scanConfig), quarantine, uploadStorage))
+	case config.GetString("app.env") == "development":
+		log.Println("scanning is disabled, uploads are released without a malware scan")
+		uploadPipeline = append(uploadPipeline, scanning.NewProcessor(scanning.SkipScanner{}, quarantine, uploadStorage))
+	default:
+		log.Println("scanning is disabled, uploads stay quarantined until scanning.enabled is set")
+	}
+
+	// kuota per user & global dicek sebelum variant dibuat, lalu upload beserta variant
+	// dihitung ke pemiliknya. 0 berarti tanpa batas
+	var quotaConfig quota.Config
+	if err := config.UnmarshalKey("quota", &quotaConfig); err != nil {
+		log.Fatalf("error cant load quota config : %v", err)
+	}
+	catalogueStore := catalogue.NewFileStore(config.GetString("catalogue.directory"))
+	quotaChecker := quota.NewChecker(quotaConfig, catalogueStore, uploadStorage)
+
+	// metadata setiap upload dicatat paling akhir, setelah scan & variant selesai
+	uploadPipeline = append(uploadPipeline, quotaChecker, imageProcessor, quotaChecker.Recorder(), catalogue.NewProcessor(catalogueStore))
+	tusStore.Pipeline = uploadPipeline
+
+	// download beberapa file sebagai zip / tar.gz
+	var archiveConfig archive.Config
+	if err := config.UnmarshalKey("archive", &archiveConfig); err != nil {
+		log.Fatalf("error cant load archive config : %v", err)
+	}
+
+	// link share ditandatangani hmac, secret sama untuk semua proses prefork
+	var sharingConfig sharing.Config
+	if err := config.UnmarshalKey("sharing", &sharingConfig); err != nil {
+		log.Fatalf("error cant load sharing config : %v", err)
+	}
+	shareSecret, err := sharingConfig.LoadSecret()
+	if err != nil {
+		log.Fatalf("error cant load sharing secret : %v", err)
+	}
+	if len(shareSecret) == 0 {
+		if config.GetString("app.env") != "development" {
+			log.Fatalf("error sharing secret is not set : set sharing.secret_file or sharing.secret")
+		}
+		log.Println("sharing secret is not set, using the development secret")
+		shareSecret = []byte(sharing.DevelopmentSecret)
+	}
+	shareSigner, err := sharing.NewSigner(shareSecret)
+	if err != nil {
+		log.Fatalf("error invalid sharing config : %v", err)
+	}
+	shareLinks := sharing.NewStore(sharingConfig.Directory)
+
+	// user terdaftar lewat /register dengan role user
+	var usersConfig users.Config
+	if err := config.UnmarshalKey("users", &usersConfig); err != nil {
+		log.Fatalf("error cant load users config : %v", err)
+	}
+	userStore := users.NewFileStore(usersConfig.Directory)
+
+	// "promote-admin <username>" memberi role admin ke user yang sudah terdaftar lalu berhenti
+	if len(os.Args) > 1 && os.Args[1] == "promote-admin" {
+		if len(os.Args) != 3 {
+			log.Fatalf("usage : promote-admin <username>")
+		}
+		user, err := users.PromoteAdmin(context.Background(), userStore, os.Args[2])
+		if err != nil {
+			log.Fatalf("error cant promote %v : %v", os.Args[2], err)
+		}
+		log.Printf("user %v (%v) is an admin", user.Username, user.ID)
+		return
+	}
+
+	// order per user, driver memory tidak dibagi antar proses prefork
+	var ordersConfig orders.Config
+	if err := config.UnmarshalKey("orders", &ordersConfig); err != nil {
+		log.Fatalf("error cant load orders config : %v", err)
+	}
+	orderRepository, err := ordersConfig.Open()
+	if err != nil {
+		log.Fatalf("error cant open orders repository : %v", err)
+	}
 
 	// instance validate
 	validate := validator.New()
+
+	// halaman error development menampilkan stack trace & detail request
 	errorHandler := handler.NewErrorHandler()
+	if config.GetString("app.env") == "development" {
+		errorHandler = handler.NewDevErrorHandler()
+	}
 
-	// engine template mustache
-	engineView := mustache.New("./view", ".mustache")
+	// engine template mustache dengan layout & partials
+	var viewConfig view.Config
+	if err := config.UnmarshalKey("view", &viewConfig); err != nil {
+		log.Fatalf("error cant load view config : %v", err)
+	}
+	if viewConfig.AppName == "" {
+		viewConfig.AppName = config.GetString("app.name")
+	}
+
+	// default view & asset dari embed, dev mode baca dari disk dengan hot reload
+	engineView := view.NewEngine()
+	var publicFiles fs.FS = public.Files
+	if viewConfig.DevMode {
+		engineView = view.NewDevEngine(viewConfig.Directory)
+		publicFiles = os.DirFS(viewConfig.AssetDir)
+	}
+	assets := view.NewAssetHasher(publicFiles, viewConfig.AssetPrefix)
+
+	// static mount, root "embed" memakai asset yang sama dengan view
+	var staticMounts []handler.StaticMount
+	if err := config.UnmarshalKey("static.mounts", &staticMounts); err != nil {
+		log.Fatalf("error cant load static config : %v", err)
+	}
+	for i, mount := range staticMounts {
+		if mount.Root == "embed" {
+			staticMounts[i].Files = publicFiles
+		} else {
+			staticMounts[i].Files = os.DirFS(mount.Root)
+		}
+	}
 
 	// create instance app fiber
 	app := fiber.New(fiber.Config{
-		IdleTimeout:  3 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		Prefork:      true,
-		Views:        engineView,
-		ErrorHandler: errorHandler.ErrorHandler, // override default error handler
+		IdleTimeout:                  3 * time.Second,
+		ReadTimeout:                  timeoutConfig.Server,
+		WriteTimeout:                 timeoutConfig.Server,
+		StreamRequestBody:            true, // body dibaca sebagai stream oleh handler upload
+		DisablePreParseMultipartForm: true, // multipart tidak di-parse otomatis oleh fasthttp
+		Prefork:                      !tlsConfig.Enabled,
+		Views:                        engineView,
+		PassLocalsToViews:            true,                      // expose locals (cspNonce, view globals) to mustache views
+		ErrorHandler:                 errorHandler.ErrorHandler, // override default error handler
 	})
 
+	// route yang lebih lama dari timeout server (upload, archive) mendapat deadline koneksi sendiri
+	app.Server().HeaderReceived = timeoutConfig.RequestConfig
+
 	if fiber.IsChild() {
 		log.Println("im child process")
 	} else {
 		log.Println("im parent process")
+
+		// cleanup upload tus yang ditinggalkan cukup dijalankan di satu proses
+		tusStore.StartCleanup(context.Background(), func(removed int, err error) {
+			if err != nil {
+				log.Printf("error cleanup tus uploads : %v", err)
+			} else if removed > 0 {
+				log.Printf("removed %v expired tus uploads", removed)
+			}
+		})
 	}
 
-	// use logger to log HTTP request
+	// recover paling awal agar panic di middleware lain juga tertangkap
+	app.Use(recoverMiddleware.Handle)
+
+	// cors harus diawal agar preflight tidak melewati middleware lain
+	app.Use(middleware.NewCorsMiddleware(corsConfig).Handle)
+	app.Use(middleware.NewSecurityHeadersMiddleware(securityConfig).Handle)
+	app.Use(requestid.New(requestid.Config{ContextKey: view.RequestIdKey}))
+	app.Use(middleware.NewViewGlobalsMiddleware(viewConfig, assets).Handle)
+	app.Use(middleware.NewMaintenanceMiddleware(maintenanceConfig).Handle)
+	// batas body sebelum csrf, token csrf form dibaca dari body
+	app.Use(middleware.NewBodyLimitMiddleware(bodyLimitConfig).Handle)
+	app.Use(middleware.NewCsrfMiddleware())
+
+	app.Use(middleware.ClientCertMiddleware)
 	app.Use(middleware.AuthMiddleware)
+	app.Use(middleware.NewUserAuthMiddleware(userStore, usersConfig).Handle)
+	// /debug/vars berisi cmdline & memstats, hanya untuk admin
+	if config.GetBool("metrics.enabled") {
+		app.Use("/debug/vars", middleware.RequireAdmin, expvar.New())
+	}
 	app.Use("/v1", middleware.OnlyV1Middleware)
+	// use logger to log HTTP request
 	app.Use(logger.New())
+	app.Use(middleware.NewTimeoutMiddleware(timeoutConfig).Handle)
+
+	// openapi spec di-generate saat request pertama, setelah semua route terdaftar
+	spec := Routes.NewOpenApiSpec(app, openapi.Info{
+		Title:   config.GetString("app.name"),
+		Version: config.GetString("app.version"),
+	})
+	if validatorConfig.Enabled {
+		app.Use(middleware.NewOpenApiValidatorMiddleware(spec, validatorConfig).Handle)
+	}
 
 	app.Get("/test", func(ctx *fiber.Ctx) error {
 		return fiber.NewError(500, "error internal")
 	})
 
 	// routes
-	Routes.NewTestRoutes(app, validate)
+	Routes.NewStaticRoutes(app, staticMounts...)
+	testHandler := handler.NewTestHandler(validate)
+	testHandler.Storage = uploadStorage
+	testHandler.Pipeline = uploadPipeline
+	testHandler.Quota = quotaChecker
+	testHandler.Users = userStore
+	testHandler.UserConfig = usersConfig
+	Routes.NewTestHandlerRoutes(app, testHandler)
+	tusHandler := handler.NewTusHandler(tusStore)
+	tusHandler.Quota = quotaChecker
+	Routes.NewTusRoutes(app, tusHandler)
+	fileHandler := handler.NewFileHandler(uploadStorage, imageProcessor)
+	fileHandler.Quarantine = quarantine
+	fileHandler.Catalogue = catalogueStore
+	fileHandler.Quota = quotaChecker
+	fileHandler.Validate = validate
+	Routes.NewFileRoutes(app, fileHandler)
+	archiveHandler := handler.NewArchiveHandler(catalogueStore, uploadStorage, archiveConfig, validate)
+	archiveHandler.Quarantine = quarantine
+	Routes.NewArchiveRoutes(app, archiveHandler)
+	shareHandler := handler.NewShareHandler(catalogueStore, shareLinks, shareSigner, sharingConfig, validate)
+	Routes.NewShareRoutes(app, shareHandler, fileHandler, middleware.NewSignedLinkMiddleware(shareSigner, shareLinks))
+	Routes.NewQuotaRoutes(app, handler.NewQuotaHandler(quotaChecker))
+	Routes.NewUserRoutes(app, handler.NewUserHandler(userStore, usersConfig, validate))
+	Routes.NewOrderRoutes(app, handler.NewOrderHandler(orderRepository, userStore, validate))
+
+	// openapi spec & docs
+	Routes.NewOpenApiRoutes(app, spec)
 
 	addr := fmt.Sprintf("%v:%v", config.GetString("app.host"), config.GetString("app.port"))
-	if err := app.Listen(addr); err != nil {
+	if !tlsConfig.Enabled {
+		if err := app.Listen(addr); err != nil {
+			log.Fatalf(err.Error())
+		}
+		return
+	}
+
+	// listen https
+	serverTLSConfig, err := server.NewTLSConfig(tlsConfig)
+	if err != nil {
+		log.Fatalf("error cant create tls config : %v", err)
+	}
+
+	if tlsConfig.RedirectHTTP {
+		redirectAddr := fmt.Sprintf("%v:%v", config.GetString("app.host"), tlsConfig.HTTPPort)
+		go func() {
+			if err := server.NewHTTPSRedirectApp(config.GetString("app.port")).Listen(redirectAddr); err != nil {
+				log.Fatalf("error cant start http redirect listener : %v", err)
+			}
+		}()
+	}
+
+	listener, err := tls.Listen("tcp", addr, serverTLSConfig)
+	if err != nil {
+		log.Fatalf(err.Error())
+	}
+	if err := app.Listener(listener); err != nil {
 		log.Fatalf(err.Error())
 	}
 }
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/upload"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// RouteBodyLimit overrides the default body limit for one route. Stream routes read
+// the body with upload.Body while it arrives; other routes get the body buffered.
+type RouteBodyLimit struct {
+	Method string `mapstructure:"method"`
+	Path   string `mapstructure:"path"`
+	Limit  int64  `mapstructure:"limit"`
+	Stream bool   `mapstructure:"stream"`
+}
+
+// BodyLimitConfig is loaded from the "body_limits" section of config.json, limits are in bytes.
+type BodyLimitConfig struct {
+	Default int64            `mapstructure:"default"`
+	Routes  []RouteBodyLimit `mapstructure:"routes"`
+}
+
+type BodyLimitMiddleware struct {
+	config BodyLimitConfig
+}
+
+// function provider
+func NewBodyLimitMiddleware(config BodyLimitConfig) *BodyLimitMiddleware {
+	return &BodyLimitMiddleware{
+		config: config,
+	}
+}
+
+// method middleware
+func (b *BodyLimitMiddleware) Handle(ctx *fiber.Ctx) error {
+	route := b.routeOf(ctx.Method(), ctx.Path())
+	if route.Limit <= 0 {
+		return ctx.Next()
+	}
+
+	// content-length diketahui, tolak sebelum body dibaca
+	if length := ctx.Request().Header.ContentLength(); int64(length) > route.Limit {
+		return tooLargeResponse(ctx, route.Limit)
+	}
+
+	if route.Stream {
+		body := upload.LimitBody(ctx, route.Limit)
+		err := ctx.Next()
+		if body.Exceeded() {
+			ctx.Response().ResetBody()
+			return tooLargeResponse(ctx, route.Limit)
+		}
+		return err
+	}
+
+	// body chunked tanpa content-length dibaca di sini dengan batas limit
+	if stream := ctx.Context().RequestBodyStream(); stream != nil && ctx.Request().Header.ContentLength() < 0 {
+		buffer := &bytes.Buffer{}
+		if _, err := io.Copy(buffer, io.LimitReader(stream, route.Limit+1)); err != nil {
+			return err
+		}
+		if int64(buffer.Len()) > route.Limit {
+			return tooLargeResponse(ctx, route.Limit)
+		}
+		ctx.Request().SetBodyRaw(buffer.Bytes())
+	}
+
+	return ctx.Next()
+}
+
+// limit untuk request, route pertama yang cocok menang, selain itu default
+func (b *BodyLimitMiddleware) routeOf(method string, path string) RouteBodyLimit {
+	for _, route := range b.config.Routes {
+		if route.Method != "" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if matchRoutePath(route.Path, path) {
+			return route
+		}
+	}
+	return RouteBodyLimit{Path: path, Limit: b.config.Default}
+}
+
+// koneksi ditutup karena sisa body yang belum dibaca tidak boleh dianggap request berikutnya
+func tooLargeResponse(ctx *fiber.Ctx, limit int64) error {
+	ctx.Context().SetConnectionClose()
+	ctx.Status(http.StatusRequestEntityTooLarge)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusRequestEntityTooLarge,
+		Status:     "request entity too large",
+		Message:    fmt.Sprintf("request body exceeds the limit of %d bytes", limit),
+	})
+}
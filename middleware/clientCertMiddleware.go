@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// key locals untuk subject certificate client (mTLS)
+const ClientCertSubjectKey = "clientCertSubject"
+
+// simpan subject client certificate ke locals supaya bisa dibaca handler. hanya
+// certificate yang lolos verifikasi CA, dengan client_auth=request certificate
+// self-signed tetap diterima handshake dan tidak boleh menentukan identitas
+func ClientCertMiddleware(ctx *fiber.Ctx) error {
+	if state := ctx.Context().TLSConnectionState(); state != nil && len(state.VerifiedChains) > 0 {
+		ctx.Locals(ClientCertSubjectKey, state.VerifiedChains[0][0].Subject.String())
+	}
+
+	return ctx.Next()
+}
+
+// ambil subject client certificate, string kosong jika tidak ada
+func GetClientCertSubject(ctx *fiber.Ctx) string {
+	subject, _ := ctx.Locals(ClientCertSubjectKey).(string)
+	return subject
+}
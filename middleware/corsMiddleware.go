@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CorsConfig is the CORS policy, loaded from the "cors" section of config.json
+type CorsConfig struct {
+	AllowOrigins     []string `mapstructure:"allow_origins"`
+	AllowMethods     []string `mapstructure:"allow_methods"`
+	AllowHeaders     []string `mapstructure:"allow_headers"`
+	ExposeHeaders    []string `mapstructure:"expose_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"`
+}
+
+type CorsMiddleware struct {
+	config CorsConfig
+}
+
+// function provider
+func NewCorsMiddleware(config CorsConfig) *CorsMiddleware {
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+		}
+	}
+
+	return &CorsMiddleware{
+		config: config,
+	}
+}
+
+// method middleware
+func (c *CorsMiddleware) Handle(ctx *fiber.Ctx) error {
+	origin := ctx.Get(fiber.HeaderOrigin)
+	ctx.Vary(fiber.HeaderOrigin)
+
+	// bukan request cross origin atau origin tidak diizinkan -> lanjut tanpa header CORS
+	if origin == "" || !c.isAllowedOrigin(origin) {
+		return ctx.Next()
+	}
+
+	// wildcard hanya boleh dikirim jika tidak memakai credentials
+	allowOrigin := origin
+	if !c.config.AllowCredentials && c.allowsAnyOrigin() {
+		allowOrigin = "*"
+	}
+	ctx.Set(fiber.HeaderAccessControlAllowOrigin, allowOrigin)
+	if c.config.AllowCredentials {
+		ctx.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+	}
+
+	// preflight request
+	if ctx.Method() == http.MethodOptions && ctx.Get(fiber.HeaderAccessControlRequestMethod) != "" {
+		ctx.Vary(fiber.HeaderAccessControlRequestMethod, fiber.HeaderAccessControlRequestHeaders)
+		ctx.Set(fiber.HeaderAccessControlAllowMethods, strings.Join(c.config.AllowMethods, ", "))
+
+		// jika allow headers tidak diset, izinkan header yang diminta browser
+		if len(c.config.AllowHeaders) > 0 {
+			ctx.Set(fiber.HeaderAccessControlAllowHeaders, strings.Join(c.config.AllowHeaders, ", "))
+		} else if requestHeaders := ctx.Get(fiber.HeaderAccessControlRequestHeaders); requestHeaders != "" {
+			ctx.Set(fiber.HeaderAccessControlAllowHeaders, requestHeaders)
+		}
+
+		if c.config.MaxAge > 0 {
+			ctx.Set(fiber.HeaderAccessControlMaxAge, strconv.Itoa(c.config.MaxAge))
+		}
+
+		return ctx.SendStatus(http.StatusNoContent)
+	}
+
+	if len(c.config.ExposeHeaders) > 0 {
+		ctx.Set(fiber.HeaderAccessControlExposeHeaders, strings.Join(c.config.ExposeHeaders, ", "))
+	}
+
+	return ctx.Next()
+}
+
+func (c *CorsMiddleware) allowsAnyOrigin() bool {
+	for _, allowed := range c.config.AllowOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// cek origin terhadap daftar allow_origins, mendukung wildcard subdomain seperti https://*.example.com
+func (c *CorsMiddleware) isAllowedOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.config.AllowOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+
+		scheme, host, found := strings.Cut(allowed, "://*.")
+		if !found {
+			continue
+		}
+
+		prefix := scheme + "://"
+		if !strings.HasPrefix(origin, prefix) {
+			continue
+		}
+
+		// subdomain harus berakhiran ".<host>" dan tidak boleh kosong
+		originHost := strings.TrimPrefix(origin, prefix)
+		if len(originHost) > len(host)+1 && strings.HasSuffix(originHost, "."+host) {
+			return true
+		}
+	}
+	return false
+}
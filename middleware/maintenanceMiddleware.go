@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// MaintenanceConfig is loaded from the "maintenance" section of config.json.
+// Requests under AllowPaths (e.g. static assets) are still served during maintenance.
+type MaintenanceConfig struct {
+	Enabled    bool     `mapstructure:"enabled"`
+	RetryAfter int      `mapstructure:"retry_after"`
+	AllowPaths []string `mapstructure:"allow_paths"`
+}
+
+type MaintenanceMiddleware struct {
+	config MaintenanceConfig
+}
+
+// function provider
+func NewMaintenanceMiddleware(config MaintenanceConfig) *MaintenanceMiddleware {
+	return &MaintenanceMiddleware{
+		config: config,
+	}
+}
+
+// method middleware, error 503 dirender error handler sebagai halaman maintenance
+func (m *MaintenanceMiddleware) Handle(ctx *fiber.Ctx) error {
+	if !m.config.Enabled {
+		return ctx.Next()
+	}
+
+	for _, prefix := range m.config.AllowPaths {
+		if strings.HasPrefix(ctx.Path(), prefix) {
+			return ctx.Next()
+		}
+	}
+
+	if m.config.RetryAfter > 0 {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(m.config.RetryAfter))
+	}
+	return fiber.NewError(http.StatusServiceUnavailable, "service under maintenance")
+}
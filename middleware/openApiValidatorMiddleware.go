@@ -0,0 +1,180 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// OpenApiValidatorConfig is loaded from the "openapi_validation" section of config.json
+type OpenApiValidatorConfig struct {
+	Enabled           bool `mapstructure:"enabled"`
+	ValidateResponses bool `mapstructure:"validate_responses"`
+}
+
+type OpenApiValidatorMiddleware struct {
+	spec   *openapi.Spec
+	config OpenApiValidatorConfig
+}
+
+// function provider
+func NewOpenApiValidatorMiddleware(spec *openapi.Spec, config OpenApiValidatorConfig) *OpenApiValidatorMiddleware {
+	return &OpenApiValidatorMiddleware{
+		spec:   spec,
+		config: config,
+	}
+}
+
+// method middleware, request yang tidak sesuai spec langsung dibalas 400 sebelum masuk handler
+func (o *OpenApiValidatorMiddleware) Handle(ctx *fiber.Ctx) error {
+	document := o.spec.Document()
+	operation, pathParams := document.FindOperation(ctx.Method(), ctx.Path())
+
+	// route tidak ada di spec
+	if operation == nil {
+		return ctx.Next()
+	}
+
+	if errors := validateRequest(ctx, document, operation, pathParams); len(errors) > 0 {
+		ctx.Status(http.StatusBadRequest)
+		return ctx.JSON(&dto.ApiResponse{
+			StatusCode: http.StatusBadRequest,
+			Status:     "bad request",
+			Message:    "request does not match api contract",
+			Data:       errors,
+		})
+	}
+
+	err := ctx.Next()
+
+	// pelanggaran kontrak response hanya di-log, response tetap dikirim
+	if o.config.ValidateResponses && err == nil {
+		for _, violation := range validateResponse(ctx, document, operation) {
+			log.Printf("openapi response violation %v %v : %v", ctx.Method(), ctx.Path(), violation)
+		}
+	}
+
+	return err
+}
+
+func validateRequest(ctx *fiber.Ctx, document *openapi.Document, operation *openapi.OperationObject, pathParams map[string]string) []dto.FieldError {
+	var errors []dto.FieldError
+	for _, parameter := range operation.Parameters {
+		raw, present := parameterValue(ctx, parameter, pathParams)
+		if !present {
+			if parameter.Required {
+				errors = append(errors, dto.FieldError{In: parameter.In, Field: parameter.Name, Message: "is required"})
+			}
+			continue
+		}
+
+		value, err := document.CoerceParam(parameter.Schema, raw)
+		if err != nil {
+			errors = append(errors, dto.FieldError{In: parameter.In, Field: parameter.Name, Message: err.Error()})
+			continue
+		}
+		for _, violation := range document.ValidateValue(parameter.Schema, value, "") {
+			errors = append(errors, dto.FieldError{In: parameter.In, Field: parameter.Name, Message: violation.Message})
+		}
+	}
+
+	if operation.RequestBody != nil {
+		errors = append(errors, validateRequestBody(ctx, document, operation.RequestBody)...)
+	}
+
+	return errors
+}
+
+func parameterValue(ctx *fiber.Ctx, parameter openapi.ParameterObject, pathParams map[string]string) (string, bool) {
+	var value string
+	switch parameter.In {
+	case "path":
+		value = pathParams[parameter.Name]
+	case "query":
+		value = ctx.Query(parameter.Name)
+	case "header":
+		value = ctx.Get(parameter.Name)
+	case "cookie":
+		value = ctx.Cookies(parameter.Name)
+	}
+	return value, value != ""
+}
+
+func validateRequestBody(ctx *fiber.Ctx, document *openapi.Document, requestBody *openapi.RequestBodyObject) []dto.FieldError {
+	contentType := strings.ToLower(strings.TrimSpace(strings.Split(ctx.Get(fiber.HeaderContentType), ";")[0]))
+	mediaType, ok := requestBody.Content[contentType]
+	if !ok {
+		// content type lain (multipart, xml, ...) diserahkan ke handler
+		return nil
+	}
+
+	var value any
+	switch contentType {
+	case fiber.MIMEApplicationJSON:
+		if len(ctx.Body()) == 0 {
+			return []dto.FieldError{{In: "body", Message: "is required"}}
+		}
+		if err := json.Unmarshal(ctx.Body(), &value); err != nil {
+			return []dto.FieldError{{In: "body", Message: fmt.Sprintf("invalid json : %v", err)}}
+		}
+	case fiber.MIMEApplicationForm:
+		form := map[string]any{}
+		schema := document.Resolve(mediaType.Schema)
+		var errors []dto.FieldError
+		ctx.Request().PostArgs().VisitAll(func(key, raw []byte) {
+			field := string(key)
+			var property *openapi.Schema
+			if schema != nil {
+				property = schema.Properties[field]
+			}
+			coerced, err := document.CoerceParam(property, string(raw))
+			if err != nil {
+				errors = append(errors, dto.FieldError{In: "body", Field: field, Message: err.Error()})
+				return
+			}
+			form[field] = coerced
+		})
+		if len(errors) > 0 {
+			return errors
+		}
+		value = form
+	default:
+		return nil
+	}
+
+	var errors []dto.FieldError
+	for _, violation := range document.ValidateValue(mediaType.Schema, value, "") {
+		errors = append(errors, dto.FieldError{In: "body", Field: violation.Path, Message: violation.Message})
+	}
+	return errors
+}
+
+func validateResponse(ctx *fiber.Ctx, document *openapi.Document, operation *openapi.OperationObject) []openapi.ValidationError {
+	status := ctx.Response().StatusCode()
+	response, ok := operation.Responses[strconv.Itoa(status)]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return []openapi.ValidationError{{Message: fmt.Sprintf("undocumented status code %d", status)}}
+	}
+
+	// body hanya dicek untuk json, media type hasil negosiasi lain (xml, msgpack, ...) dilewati
+	contentType := strings.Split(string(ctx.Response().Header.ContentType()), ";")[0]
+	mediaType, ok := response.Content[contentType]
+	if !ok || contentType != fiber.MIMEApplicationJSON {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(ctx.Response().Body(), &value); err != nil {
+		return []openapi.ValidationError{{Message: fmt.Sprintf("invalid json body : %v", err)}}
+	}
+	return document.ValidateValue(mediaType.Schema, value, "")
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/monitoring"
+	"go_fiber/view"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// RecoverConfig is loaded from the "recover" section of config.json. When SentryDSN
+// is set, recovered panics are also forwarded to that Sentry-compatible endpoint.
+type RecoverConfig struct {
+	SentryDSN     string       `mapstructure:"sentry_dsn"`
+	SentryTimeout int          `mapstructure:"sentry_timeout"`
+	Environment   string       `mapstructure:"environment"`
+	Logger        *slog.Logger `mapstructure:"-"`
+}
+
+// PanicError wraps a recovered panic, the error handler renders it as a 500.
+// The panic value is kept out of Error() so it never reaches api clients.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (p *PanicError) Error() string {
+	return "internal server error"
+}
+
+// dipakai halaman error dev mode lewat handler.StackTracer
+func (p *PanicError) StackTrace() string {
+	return fmt.Sprintf("panic: %v\n\n%s", p.Value, p.Stack)
+}
+
+type RecoverMiddleware struct {
+	config   RecoverConfig
+	logger   *slog.Logger
+	reporter *monitoring.SentryReporter
+}
+
+// function provider
+func NewRecoverMiddleware(config RecoverConfig) (*RecoverMiddleware, error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+
+	var reporter *monitoring.SentryReporter
+	if config.SentryDSN != "" {
+		var err error
+		reporter, err = monitoring.NewSentryReporter(config.SentryDSN, time.Duration(config.SentryTimeout)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &RecoverMiddleware{
+		config:   config,
+		logger:   logger,
+		reporter: reporter,
+	}, nil
+}
+
+// method middleware, harus didaftarkan paling awal agar semua panic tertangkap
+func (r *RecoverMiddleware) Handle(ctx *fiber.Ctx) (err error) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		panicError := &PanicError{Value: value, Stack: debug.Stack()}
+		monitoring.PanicsTotal.Add(1)
+		r.logPanic(ctx, panicError)
+		if r.reporter != nil {
+			r.report(ctx, panicError)
+		}
+
+		err = panicError
+	}()
+
+	return ctx.Next()
+}
+
+func (r *RecoverMiddleware) logPanic(ctx *fiber.Ctx, panicError *PanicError) {
+	r.logger.Error("panic recovered",
+		slog.String("request_id", requestIdOf(ctx)),
+		slog.String("method", ctx.Method()),
+		slog.String("path", ctx.Path()),
+		slog.String("route", ctx.Route().Path),
+		slog.String("ip", ctx.IP()),
+		slog.String("user_agent", ctx.Get(fiber.HeaderUserAgent)),
+		slog.String("panic", fmt.Sprint(panicError.Value)),
+		slog.String("stack", string(panicError.Stack)),
+	)
+}
+
+// event dikirim di goroutine terpisah, data request disalin karena ctx dipakai ulang fiber
+func (r *RecoverMiddleware) report(ctx *fiber.Ctx, panicError *PanicError) {
+	headers := map[string]string{}
+	for name, values := range ctx.GetReqHeaders() {
+		if name == fiber.HeaderAuthorization || name == fiber.HeaderCookie {
+			continue
+		}
+		headers[strings.Clone(name)] = strings.Join(values, ", ")
+	}
+
+	event := monitoring.Event{
+		Level:       "fatal",
+		Environment: r.config.Environment,
+		Message:     fmt.Sprintf("panic: %v", panicError.Value),
+		Tags: map[string]string{
+			"request_id": requestIdOf(ctx),
+			"route":      strings.Clone(ctx.Route().Path),
+		},
+		Request: monitoring.EventRequest{
+			Method:  strings.Clone(ctx.Method()),
+			URL:     ctx.BaseURL() + ctx.OriginalURL(),
+			Headers: headers,
+		},
+		Extra: map[string]any{
+			"stack": string(panicError.Stack),
+		},
+	}
+
+	go func() {
+		if err := r.reporter.Report(event); err != nil {
+			r.logger.Warn("cant forward panic to sentry", slog.String("error", err.Error()))
+		}
+	}()
+}
+
+func requestIdOf(ctx *fiber.Ctx) string {
+	requestId, _ := ctx.Locals(view.RequestIdKey).(string)
+	return strings.Clone(requestId)
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"strings"
+)
+
+// key locals untuk nonce CSP, dengan PassLocalsToViews bisa dipakai di mustache sebagai {{cspNonce}}
+const CspNonceKey = "cspNonce"
+
+// SecurityHeadersConfig is loaded from the "security" section of config.json.
+// ContentSecurityPolicy may contain the placeholder {nonce}, replaced per request.
+type SecurityHeadersConfig struct {
+	HstsMaxAge            int    `mapstructure:"hsts_max_age"`
+	HstsIncludeSubdomains bool   `mapstructure:"hsts_include_subdomains"`
+	HstsPreload           bool   `mapstructure:"hsts_preload"`
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"`
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`
+	FrameOptions          string `mapstructure:"frame_options"`
+}
+
+type SecurityHeadersMiddleware struct {
+	config SecurityHeadersConfig
+	hsts   string
+}
+
+// function provider
+func NewSecurityHeadersMiddleware(config SecurityHeadersConfig) *SecurityHeadersMiddleware {
+	if config.ReferrerPolicy == "" {
+		config.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	hsts := ""
+	if config.HstsMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", config.HstsMaxAge)
+		if config.HstsIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HstsPreload {
+			hsts += "; preload"
+		}
+	}
+
+	return &SecurityHeadersMiddleware{
+		config: config,
+		hsts:   hsts,
+	}
+}
+
+// method middleware
+func (s *SecurityHeadersMiddleware) Handle(ctx *fiber.Ctx) error {
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	ctx.Set(fiber.HeaderReferrerPolicy, s.config.ReferrerPolicy)
+	if s.config.FrameOptions != "" {
+		ctx.Set(fiber.HeaderXFrameOptions, s.config.FrameOptions)
+	}
+	if s.hsts != "" {
+		ctx.Set(fiber.HeaderStrictTransportSecurity, s.hsts)
+	}
+
+	if s.config.ContentSecurityPolicy != "" {
+		policy := s.config.ContentSecurityPolicy
+		if strings.Contains(policy, "{nonce}") {
+			nonce, err := generateNonce()
+			if err != nil {
+				return err
+			}
+
+			ctx.Locals(CspNonceKey, nonce)
+			policy = strings.ReplaceAll(policy, "{nonce}", "'nonce-"+nonce+"'")
+		}
+		ctx.Set(fiber.HeaderContentSecurityPolicy, policy)
+	}
+
+	return ctx.Next()
+}
+
+// ambil nonce CSP milik request ini, string kosong jika CSP tidak memakai nonce
+func GetCspNonce(ctx *fiber.Ctx) string {
+	nonce, _ := ctx.Locals(CspNonceKey).(string)
+	return nonce
+}
+
+func generateNonce() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buffer), nil
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/sharing"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// key locals untuk link share yang sudah diverifikasi
+const SharedLinkKey = "sharedLink"
+
+// SignedLinkMiddleware verifies share link URLs of the form
+// /shared/:linkId?expires=<unix>&signature=<hmac>[&ip=1] before the file is served.
+// Every accepted request counts as one download of the link.
+type SignedLinkMiddleware struct {
+	signer *sharing.Signer
+	links  *sharing.Store
+}
+
+// function provider
+func NewSignedLinkMiddleware(signer *sharing.Signer, links *sharing.Store) *SignedLinkMiddleware {
+	return &SignedLinkMiddleware{
+		signer: signer,
+		links:  links,
+	}
+}
+
+func (s *SignedLinkMiddleware) Handle(ctx *fiber.Ctx) error {
+	id := ctx.Params("linkId")
+
+	// link yang terikat ip ditandatangani bersama ip client
+	ip := ""
+	if ctx.Query("ip") == "1" {
+		ip = ctx.IP()
+	}
+
+	expires, err := strconv.ParseInt(ctx.Query("expires"), 10, 64)
+	if err != nil || !s.signer.Verify(id, expires, ip, ctx.Query("signature")) {
+		return linkErrorResponse(ctx, http.StatusForbidden, "invalid share link signature")
+	}
+	if time.Now().Unix() > expires {
+		return linkErrorResponse(ctx, http.StatusGone, "share link has expired")
+	}
+
+	link, err := s.links.Use(id)
+	switch {
+	case errors.Is(err, sharing.ErrLinkNotFound):
+		return linkErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, sharing.ErrLinkRevoked), errors.Is(err, sharing.ErrLinkUsed):
+		return linkErrorResponse(ctx, http.StatusGone, err.Error())
+	case err != nil:
+		return err
+	}
+
+	ctx.Locals(SharedLinkKey, link)
+	return ctx.Next()
+}
+
+// ambil link share yang sudah diverifikasi, nil jika tidak lewat middleware
+func GetSharedLink(ctx *fiber.Ctx) *sharing.Link {
+	link, _ := ctx.Locals(SharedLinkKey).(*sharing.Link)
+	return link
+}
+
+func linkErrorResponse(ctx *fiber.Ctx, code int, message string) error {
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	ctx.Status(code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     strings.ToLower(http.StatusText(code)),
+		Message:    message,
+	})
+}
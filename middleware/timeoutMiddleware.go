@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RouteTimeout overrides the default timeout for one route. Path uses fiber route
+// syntax (/user/:userId, /files/*) and an empty Method matches every method.
+type RouteTimeout struct {
+	Method  string        `mapstructure:"method"`
+	Path    string        `mapstructure:"path"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// TimeoutConfig is loaded from the "timeouts" section of config.json, durations are
+// written as strings like "3s" or "500ms". Server is the ReadTimeout and WriteTimeout
+// of the connection; routes with a longer timeout get theirs through RequestConfig.
+type TimeoutConfig struct {
+	Default time.Duration  `mapstructure:"default"`
+	Server  time.Duration  `mapstructure:"server"`
+	Routes  []RouteTimeout `mapstructure:"routes"`
+}
+
+// timeout terpanjang dari default & semua route
+func (t TimeoutConfig) Longest() time.Duration {
+	longest := t.Default
+	for _, route := range t.Routes {
+		if route.Timeout > longest {
+			longest = route.Timeout
+		}
+	}
+	return longest
+}
+
+// RequestConfig is used as fasthttp.Server.HeaderReceived. Routes whose timeout is longer
+// than Server get a read and write deadline of that timeout plus one second, so streamed
+// request bodies and streamed responses are not cut by the short server timeouts.
+func (t TimeoutConfig) RequestConfig(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+	if err := uri.Parse(nil, header.RequestURI()); err != nil {
+		return fasthttp.RequestConfig{}
+	}
+
+	timeout, _ := t.timeoutOf(string(header.Method()), string(uri.Path()))
+	if timeout <= t.Server {
+		return fasthttp.RequestConfig{}
+	}
+	return fasthttp.RequestConfig{
+		ReadTimeout:  timeout + time.Second,
+		WriteTimeout: timeout + time.Second,
+	}
+}
+
+type TimeoutMiddleware struct {
+	config TimeoutConfig
+}
+
+// function provider
+func NewTimeoutMiddleware(config TimeoutConfig) *TimeoutMiddleware {
+	return &TimeoutMiddleware{
+		config: config,
+	}
+}
+
+// method middleware, handler membaca context lewat ctx.UserContext() dan meneruskannya
+// ke pemanggilan downstream (storage, database) agar ikut dibatalkan
+func (t *TimeoutMiddleware) Handle(ctx *fiber.Ctx) error {
+	timeout, route := t.config.timeoutOf(ctx.Method(), ctx.Path())
+	if timeout <= 0 {
+		return ctx.Next()
+	}
+
+	userContext, cancel := context.WithTimeout(ctx.UserContext(), timeout)
+	defer cancel()
+	ctx.SetUserContext(userContext)
+
+	err := ctx.Next()
+
+	// handler yang tidak memeriksa context tetap diganti responsenya setelah selesai
+	contextErr := userContext.Err()
+	if contextErr == nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	log.Printf("route timed out %v %v (path %v) after %v", ctx.Method(), route, ctx.Path(), timeout)
+
+	code, status := http.StatusGatewayTimeout, "gateway timeout"
+	if errors.Is(contextErr, context.Canceled) || (contextErr == nil && errors.Is(err, context.Canceled)) {
+		code, status = http.StatusServiceUnavailable, "service unavailable"
+	}
+
+	ctx.Response().ResetBody()
+	ctx.Status(code)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: code,
+		Status:     status,
+		Message:    "request exceeded the " + timeout.String() + " timeout",
+	})
+}
+
+// timeout untuk request, route pertama yang cocok menang, selain itu default
+func (t TimeoutConfig) timeoutOf(method string, path string) (time.Duration, string) {
+	for _, route := range t.Routes {
+		if route.Method != "" && !strings.EqualFold(route.Method, method) {
+			continue
+		}
+		if matchRoutePath(route.Path, path) {
+			return route.Timeout, route.Path
+		}
+	}
+	return t.Default, path
+}
+
+// cocokkan path dengan pola route fiber, :param untuk satu segmen dan * untuk sisa path
+func matchRoutePath(pattern string, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, segment := range patternSegments {
+		if segment == "*" {
+			return true
+		}
+		if i >= len(pathSegments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return len(patternSegments) == len(pathSegments)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/model/dto"
+	"go_fiber/render"
+	"go_fiber/users"
+	"net/http"
+	"strings"
+)
+
+// key locals untuk user yang sudah login
+const CurrentUserKey = "currentUser"
+
+// UserAuthMiddleware authenticates requests with HTTP Basic credentials of a registered
+// user. Requests without Basic credentials continue anonymously, handlers decide whether
+// they need a user; wrong credentials are rejected with 401.
+type UserAuthMiddleware struct {
+	users users.Store
+	// hash pembanding untuk username yang tidak ada, agar lama response tidak membocorkan username
+	dummy users.User
+}
+
+// function provider
+func NewUserAuthMiddleware(store users.Store, config users.Config) *UserAuthMiddleware {
+	// cost yang tidak valid juga membuat register gagal, hash kosong cukup sebagai pembanding
+	dummy := users.User{}
+	config.SetPassword(&dummy, "dummy password")
+
+	return &UserAuthMiddleware{
+		users: store,
+		dummy: dummy,
+	}
+}
+
+func (u *UserAuthMiddleware) Handle(ctx *fiber.Ctx) error {
+	// skema lain (Bearer, ...) bukan urusan middleware ini
+	scheme, credentials, _ := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if !strings.EqualFold(scheme, "Basic") {
+		return ctx.Next()
+	}
+
+	username, password, ok := parseBasicAuth(credentials)
+	if !ok {
+		return unauthorizedResponse(ctx, "invalid authorization header")
+	}
+
+	user, err := u.users.GetByUsername(ctx.UserContext(), username)
+	if errors.Is(err, users.ErrNotFound) {
+		u.dummy.CheckPassword(password)
+		return unauthorizedResponse(ctx, "invalid username or password")
+	}
+	if err != nil {
+		return err
+	}
+
+	// password selalu dicek dulu, user yang sudah dihapus tidak bisa login sampai di-restore
+	if !user.CheckPassword(password) || user.Deleted() {
+		return unauthorizedResponse(ctx, "invalid username or password")
+	}
+
+	ctx.Locals(CurrentUserKey, &user)
+	return ctx.Next()
+}
+
+// ambil user yang sedang login, nil untuk request tanpa login
+func GetCurrentUser(ctx *fiber.Ctx) *users.User {
+	user, _ := ctx.Locals(CurrentUserKey).(*users.User)
+	return user
+}
+
+// RequireLogin rejects anonymous requests with 401. It must run after UserAuthMiddleware.
+func RequireLogin(ctx *fiber.Ctx) error {
+	if GetCurrentUser(ctx) == nil {
+		return unauthorizedResponse(ctx, "login required")
+	}
+	return ctx.Next()
+}
+
+// RequireAdmin only lets logged in admins through; anonymous requests get 401 and
+// other users 403. It must run after UserAuthMiddleware.
+func RequireAdmin(ctx *fiber.Ctx) error {
+	user := GetCurrentUser(ctx)
+	if user == nil {
+		return unauthorizedResponse(ctx, "login required")
+	}
+	if !user.IsAdmin() {
+		ctx.Status(http.StatusForbidden)
+		return render.Respond(ctx, &dto.ApiResponse{
+			StatusCode: http.StatusForbidden,
+			Status:     "forbidden",
+			Message:    "only admins can access this resource",
+		})
+	}
+	return ctx.Next()
+}
+
+// credential basic "username:password" dalam base64
+func parseBasicAuth(encoded string) (string, string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", "", false
+	}
+	return strings.Cut(string(decoded), ":")
+}
+
+func unauthorizedResponse(ctx *fiber.Ctx, message string) error {
+	ctx.Set(fiber.HeaderWWWAuthenticate, `Basic realm="users", charset="UTF-8"`)
+	ctx.Status(http.StatusUnauthorized)
+	return render.Respond(ctx, &dto.ApiResponse{
+		StatusCode: http.StatusUnauthorized,
+		Status:     "unauthorized",
+		Message:    message,
+	})
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/cbroglie/mustache"
+	"github.com/gofiber/fiber/v2"
+	"go_fiber/view"
+)
+
+// ViewGlobalsMiddleware exposes data every page needs (app name, flash, helper lambdas)
+// as locals, fiber passes them to mustache when PassLocalsToViews is enabled
+type ViewGlobalsMiddleware struct {
+	appName    string
+	asset      mustache.LambdaFunc
+	formatDate mustache.LambdaFunc
+}
+
+// function provider
+func NewViewGlobalsMiddleware(config view.Config, assets *view.AssetHasher) *ViewGlobalsMiddleware {
+	if config.DateFormat == "" {
+		config.DateFormat = "02 Jan 2006 15:04"
+	}
+
+	return &ViewGlobalsMiddleware{
+		appName:    config.AppName,
+		asset:      assets.Lambda(),
+		formatDate: view.FormatDateLambda(config.DateFormat),
+	}
+}
+
+// method middleware
+func (v *ViewGlobalsMiddleware) Handle(ctx *fiber.Ctx) error {
+	ctx.Locals(view.AppNameKey, v.appName)
+	ctx.Locals(view.AssetKey, v.asset)
+	ctx.Locals(view.FormatDateKey, v.formatDate)
+
+	// flash hanya diambil untuk halaman html, supaya tidak hilang oleh request api
+	if ctx.Accepts(fiber.MIMETextHTML) == fiber.MIMETextHTML && ctx.Get(fiber.HeaderAccept) != "" {
+		if flashes := view.ConsumeFlashes(ctx); len(flashes) > 0 {
+			ctx.Locals(view.FlashKey, flashes)
+		}
+	}
+
+	return ctx.Next()
+}
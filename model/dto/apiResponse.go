@@ -1,8 +1,42 @@
 package dto
 
+import (
+	"encoding/xml"
+)
+
 type ApiResponse struct {
-	StatusCode int    `json:"status_code"`
-	Status     string `json:"status"`
-	Message    string `json:"message"`
-	Data       any    `json:"data,omitempty"`
+	StatusCode int    `json:"status_code" xml:"status_code"`
+	Status     string `json:"status" xml:"status"`
+	Message    string `json:"message" xml:"message"`
+	Data       any    `json:"data,omitempty" xml:"data,omitempty"`
+}
+
+// encoding/xml tidak bisa encode map, jadi Data di-encode manual
+func (a ApiResponse) MarshalXML(encoder *xml.Encoder, start xml.StartElement) error {
+	start.Name.Local = "response"
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name  string
+		value any
+	}{
+		{"status_code", a.StatusCode},
+		{"status", a.Status},
+		{"message", a.Message},
+	}
+	for _, field := range fields {
+		if err := encoder.EncodeElement(field.value, xml.StartElement{Name: xml.Name{Local: field.name}}); err != nil {
+			return err
+		}
+	}
+
+	if a.Data != nil {
+		if err := encodeXMLValue(encoder, "data", a.Data); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
 }
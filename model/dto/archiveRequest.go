@@ -0,0 +1,12 @@
+package dto
+
+// body POST /files/archive, path opsional dan default ke nama file asli
+type ArchiveRequest struct {
+	Format string        `json:"format" xml:"format" validate:"omitempty,oneof=zip tar.gz"`
+	Files  []ArchiveFile `json:"files" xml:"files" validate:"required,min=1,max=1000,dive"`
+}
+
+type ArchiveFile struct {
+	ID   string `json:"id" xml:"id" validate:"required"`
+	Path string `json:"path" xml:"path" validate:"omitempty,max=255"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+// FieldError describes one invalid request value, In is path, query, header, cookie or body
+type FieldError struct {
+	In      string `json:"in" xml:"in"`
+	Field   string `json:"field" xml:"field"`
+	Message string `json:"message" xml:"message"`
+}
@@ -0,0 +1,16 @@
+package dto
+
+import (
+	"time"
+)
+
+// query parameter GET /files, tanggal from & to inklusif
+type FileQuery struct {
+	Owner   string    `json:"owner" query:"owner" description:"only files uploaded by this user id, users other than admins only see their own files"`
+	Type    string    `json:"type" query:"type" description:"content type (image/png) or top-level type (image)"`
+	From    time.Time `json:"from" query:"from" layout:"2006-01-02" description:"uploaded on or after this date"`
+	To      time.Time `json:"to" query:"to" layout:"2006-01-02" description:"uploaded on or before this date"`
+	Sort    string    `json:"sort" query:"sort" default:"-created_at" validate:"oneof=created_at -created_at name -name size -size" description:"sort field, prefix - for descending"`
+	Page    int       `json:"page" query:"page" default:"1" validate:"min=1"`
+	PerPage int       `json:"per_page" query:"per_page" default:"20" validate:"min=1,max=100"`
+}
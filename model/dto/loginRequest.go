@@ -1,6 +1,6 @@
 package dto
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Email    string `json:"email" xml:"email" form:"email" validate:"required,email"`
+	Password string `json:"password" xml:"password" form:"password" validate:"required,min=6"`
 }
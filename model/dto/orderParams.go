@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"time"
+)
+
+type OrderParams struct {
+	UserId  string `json:"user" param:"userId" validate:"required" description:"id of the user"`
+	OrderId int    `json:"order" param:"orderId" validate:"gt=0"`
+}
+
+// query parameter GET /user/:userId/order
+type OrderQuery struct {
+	UserId  string `json:"user" param:"userId" validate:"required" description:"id of the user"`
+	Status  string `json:"status" query:"status" validate:"omitempty,oneof=pending paid shipped delivered cancelled"`
+	Page    int    `json:"page" query:"page" default:"1" validate:"min=1"`
+	PerPage int    `json:"per_page" query:"per_page" default:"20" validate:"min=1,max=100"`
+}
+
+// batas quantity & price menjaga total 100 item tetap muat di int64
+type OrderItem struct {
+	Product  string `json:"product" xml:"product" validate:"required,max=200"`
+	Quantity int    `json:"quantity" xml:"quantity" validate:"min=1,max=10000"`
+	Price    int64  `json:"price" xml:"price" validate:"min=0,max=1000000000000"`
+}
+
+// body POST /user/:userId/order
+type CreateOrder struct {
+	Items []OrderItem `json:"items" xml:"items" validate:"required,min=1,max=100,dive"`
+}
+
+// body PATCH /user/:userId/order/:orderId, hanya admin
+type UpdateOrderStatus struct {
+	Status string `json:"status" xml:"status" form:"status" validate:"required,oneof=pending paid shipped delivered cancelled"`
+}
+
+// Order is an order in responses. Total and prices are in the smallest currency unit.
+type Order struct {
+	ID        int         `json:"id" xml:"id"`
+	UserID    string      `json:"user_id" xml:"user_id"`
+	Status    string      `json:"status" xml:"status"`
+	Items     []OrderItem `json:"items" xml:"items"`
+	Total     int64       `json:"total" xml:"total"`
+	CreatedAt time.Time   `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at" xml:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// Page is the Data of a paginated ApiResponse.
+type Page struct {
+	Items      any `json:"items" xml:"items"`
+	Page       int `json:"page" xml:"page"`
+	PerPage    int `json:"per_page" xml:"per_page"`
+	Total      int `json:"total" xml:"total"`
+	TotalPages int `json:"total_pages" xml:"total_pages"`
+}
+
+// function provider
+func NewPage(items any, page int, perPage int, total int) Page {
+	return Page{
+		Items:      items,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: (total + perPage - 1) / perPage,
+	}
+}
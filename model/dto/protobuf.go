@@ -0,0 +1,156 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// encoding protobuf ditulis manual dengan protowire mengikuti proto/api.proto,
+// supaya handler tetap memakai struct dto yang sama untuk json dan protobuf
+
+func (l *LoginRequest) MarshalProto() ([]byte, error) {
+	var buffer []byte
+	buffer = appendString(buffer, 1, l.Email)
+	buffer = appendString(buffer, 2, l.Password)
+	return buffer, nil
+}
+
+func (l *LoginRequest) UnmarshalProto(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1:
+			l.Email = string(value)
+		case 2:
+			l.Password = string(value)
+		}
+	}, nil)
+}
+
+func (r *RegisterUser) MarshalProto() ([]byte, error) {
+	var buffer []byte
+	buffer = appendString(buffer, 1, r.Username)
+	buffer = appendString(buffer, 2, r.Password)
+	buffer = appendString(buffer, 3, r.Name)
+	return buffer, nil
+}
+
+func (r *RegisterUser) UnmarshalProto(data []byte) error {
+	return consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 1:
+			r.Username = string(value)
+		case 2:
+			r.Password = string(value)
+		case 3:
+			r.Name = string(value)
+		}
+	}, nil)
+}
+
+func (a *ApiResponse) MarshalProto() ([]byte, error) {
+	var buffer []byte
+	if a.StatusCode != 0 {
+		buffer = protowire.AppendTag(buffer, 1, protowire.VarintType)
+		buffer = protowire.AppendVarint(buffer, uint64(int64(int32(a.StatusCode))))
+	}
+	buffer = appendString(buffer, 2, a.Status)
+	buffer = appendString(buffer, 3, a.Message)
+
+	if a.Data != nil {
+		// Data di-normalisasi lewat json supaya struct dan map jadi google.protobuf.Value
+		encoded, err := json.Marshal(a.Data)
+		if err != nil {
+			return nil, err
+		}
+		var normalized any
+		if err := json.Unmarshal(encoded, &normalized); err != nil {
+			return nil, err
+		}
+
+		value, err := structpb.NewValue(normalized)
+		if err != nil {
+			return nil, err
+		}
+		data, err := proto.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		buffer = protowire.AppendTag(buffer, 4, protowire.BytesType)
+		buffer = protowire.AppendBytes(buffer, data)
+	}
+	return buffer, nil
+}
+
+func (a *ApiResponse) UnmarshalProto(data []byte) error {
+	var dataErr error
+	err := consumeFields(data, func(number protowire.Number, value []byte) {
+		switch number {
+		case 2:
+			a.Status = string(value)
+		case 3:
+			a.Message = string(value)
+		case 4:
+			message := &structpb.Value{}
+			if dataErr = proto.Unmarshal(value, message); dataErr == nil {
+				a.Data = message.AsInterface()
+			}
+		}
+	}, func(number protowire.Number, value uint64) {
+		if number == 1 {
+			a.StatusCode = int(int32(value))
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return dataErr
+}
+
+// proto3 tidak mengirim field string kosong
+func appendString(buffer []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return buffer
+	}
+	buffer = protowire.AppendTag(buffer, number, protowire.BytesType)
+	return protowire.AppendString(buffer, value)
+}
+
+// baca semua field, field yang tidak dikenal dilewati sesuai aturan protobuf
+func consumeFields(data []byte, onBytes func(protowire.Number, []byte), onVarint func(protowire.Number, uint64)) error {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("invalid protobuf tag : %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+
+		switch wireType {
+		case protowire.BytesType:
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return fmt.Errorf("invalid protobuf field %d : %w", number, protowire.ParseError(n))
+			}
+			onBytes(number, value)
+			data = data[n:]
+		case protowire.VarintType:
+			value, n := protowire.ConsumeVarint(data)
+			if n < 0 {
+				return fmt.Errorf("invalid protobuf field %d : %w", number, protowire.ParseError(n))
+			}
+			if onVarint != nil {
+				onVarint(number, value)
+			}
+			data = data[n:]
+		default:
+			n := protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				return fmt.Errorf("invalid protobuf field %d : %w", number, protowire.ParseError(n))
+			}
+			data = data[n:]
+		}
+	}
+	return nil
+}
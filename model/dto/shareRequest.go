@@ -0,0 +1,8 @@
+package dto
+
+// body POST /files/:id/share, semua field opsional
+type ShareRequest struct {
+	ExpiresIn int    `json:"expires_in" xml:"expires_in" form:"expires_in" validate:"omitempty,min=1"`
+	SingleUse bool   `json:"single_use" xml:"single_use" form:"single_use"`
+	IP        string `json:"ip" xml:"ip" form:"ip" validate:"omitempty,ip"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+)
+
+// User is a user in responses, the password hash is never part of it.
+type User struct {
+	ID        string     `json:"id" xml:"id"`
+	Username  string     `json:"username" xml:"username"`
+	Name      string     `json:"name" xml:"name"`
+	Role      string     `json:"role" xml:"role"`
+	CreatedAt time.Time  `json:"created_at" xml:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" xml:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" xml:"deleted_at,omitempty"`
+}
+
+// query parameter GET /users, name & email cocok dengan sebagian nilai
+type UserQuery struct {
+	Name    string `json:"name" query:"name" description:"part of the name, case-insensitive"`
+	Email   string `json:"email" query:"email" description:"part of the email, case-insensitive"`
+	Deleted bool   `json:"deleted" query:"deleted" description:"also list soft deleted users"`
+	Page    int    `json:"page" query:"page" default:"1" validate:"min=1"`
+	PerPage int    `json:"per_page" query:"per_page" default:"20" validate:"min=1,max=100"`
+}
+
+// body PATCH /users/:id, field yang tidak dikirim tidak diubah. role hanya bisa diubah admin
+type UpdateUser struct {
+	Username *string `json:"username" xml:"username" form:"username" validate:"omitempty,email"`
+	Password *string `json:"password" xml:"password" form:"password" validate:"omitempty,min=6"`
+	Name     *string `json:"name" xml:"name" form:"name" validate:"omitempty,min=1"`
+	Role     *string `json:"role" xml:"role" form:"role" validate:"omitempty,oneof=user admin"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"encoding/xml"
+	"reflect"
+	"sort"
+)
+
+// encode value bebas (map, slice, struct) menjadi element xml bernama name.
+// key map menjadi nama element, item slice menjadi element <item>.
+func encodeXMLValue(encoder *xml.Encoder, name string, value any) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Pointer || reflected.Kind() == reflect.Interface {
+		if reflected.IsNil() {
+			return nil
+		}
+		reflected = reflected.Elem()
+	}
+
+	switch reflected.Kind() {
+	case reflect.Map:
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+
+		// urutkan key supaya output stabil
+		keys := reflected.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		for _, key := range keys {
+			if err := encodeXMLValue(encoder, key.String(), reflected.MapIndex(key).Interface()); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	case reflect.Slice, reflect.Array:
+		if reflected.Type().Elem().Kind() == reflect.Uint8 {
+			return encoder.EncodeElement(value, start)
+		}
+		if err := encoder.EncodeToken(start); err != nil {
+			return err
+		}
+		for i := 0; i < reflected.Len(); i++ {
+			if err := encodeXMLValue(encoder, "item", reflected.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return encoder.EncodeToken(start.End())
+	}
+
+	return encoder.EncodeElement(reflected.Interface(), start)
+}
@@ -0,0 +1,10 @@
+package monitoring
+
+import (
+	"expvar"
+)
+
+// metric aplikasi, tersedia di /debug/vars untuk admin jika metrics.enabled
+var (
+	PanicsTotal = expvar.NewInt("panics_total")
+)
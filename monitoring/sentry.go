@@ -0,0 +1,108 @@
+package monitoring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Event is the subset of the Sentry event payload sent for a recovered panic.
+type Event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Message     string            `json:"message"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Request     EventRequest      `json:"request"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+}
+
+type EventRequest struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// SentryReporter sends events to a Sentry-compatible store endpoint derived from a DSN
+// of the form scheme://key@host[/path]/project_id.
+type SentryReporter struct {
+	endpoint string
+	auth     string
+	client   *http.Client
+}
+
+// function provider
+func NewSentryReporter(dsn string, timeout time.Duration) (*SentryReporter, error) {
+	parsed, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry dsn : %w", err)
+	}
+
+	key := parsed.User.Username()
+	path := strings.TrimSuffix(parsed.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	if key == "" || slash < 0 || slash == len(path)-1 {
+		return nil, fmt.Errorf("invalid sentry dsn : missing key or project id")
+	}
+	project := path[slash+1:]
+
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &SentryReporter{
+		endpoint: fmt.Sprintf("%v://%v%v/api/%v/store/", parsed.Scheme, parsed.Host, path[:slash], project),
+		auth:     fmt.Sprintf("Sentry sentry_version=7, sentry_client=go-fiber/1.0, sentry_key=%v", key),
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// kirim event ke endpoint sentry, event id & timestamp diisi jika kosong
+func (s *SentryReporter) Report(event Event) error {
+	if event.EventID == "" {
+		event.EventID = newEventID()
+	}
+	if event.Timestamp == "" {
+		event.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if event.Platform == "" {
+		event.Platform = "go"
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Sentry-Auth", s.auth)
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("sentry responded with status %v", response.StatusCode)
+	}
+	return nil
+}
+
+func newEventID() string {
+	buffer := make([]byte, 16)
+	rand.Read(buffer)
+	return hex.EncodeToString(buffer)
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Generate builds the document from the routes registered on app, enriched by the registry
+func Generate(info Info, routes []fiber.Route, registry *Registry) *Document {
+	schemas := NewSchemaGenerator()
+	document := &Document{
+		OpenAPI: "3.1.0",
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+	}
+
+	for _, route := range routes {
+		if !isDocumentedMethod(route.Method) || strings.Contains(route.Path, "*") {
+			continue
+		}
+
+		operation, _ := registry.Lookup(route.Method, route.Path)
+		if operation.Hidden {
+			continue
+		}
+
+		path, pathParams := convertPath(route.Path)
+		item, ok := document.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			document.Paths[path] = item
+		}
+		setOperation(item, route.Method, buildOperation(schemas, route.Method, path, pathParams, operation))
+	}
+
+	document.Components.Schemas = schemas.Schemas()
+	return document
+}
+
+func buildOperation(schemas *SchemaGenerator, method, path string, pathParams []string, operation Operation) *OperationObject {
+	object := &OperationObject{
+		OperationID: operation.OperationID,
+		Summary:     operation.Summary,
+		Description: operation.Description,
+		Tags:        operation.Tags,
+		Responses:   map[string]*ResponseObject{},
+	}
+	if object.OperationID == "" {
+		object.OperationID = operationID(method, path)
+	}
+
+	// path parameter selalu ada, walaupun tidak didokumentasikan
+	documented := map[string]bool{}
+	for _, parameter := range operation.Parameters {
+		schema := schemas.SchemaOf(parameter.Type)
+		if parameter.Type == nil {
+			schema = &Schema{Type: "string"}
+		}
+		if parameter.Format != "" {
+			schema.Format = parameter.Format
+		}
+		required := ApplyValidateRules(schema, parameter.Validate) || parameter.Required || parameter.In == "path"
+
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name:        parameter.Name,
+			In:          parameter.In,
+			Description: parameter.Description,
+			Required:    required,
+			Schema:      schema,
+		})
+		if parameter.In == "path" {
+			documented[parameter.Name] = true
+		}
+	}
+	for _, name := range pathParams {
+		if !documented[name] {
+			object.Parameters = append(object.Parameters, ParameterObject{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+
+	if operation.Request != nil {
+		contentTypes := operation.RequestContentTypes
+		if len(contentTypes) == 0 {
+			contentTypes = []string{fiber.MIMEApplicationJSON}
+		}
+
+		schema := schemas.SchemaOf(operation.Request)
+		object.RequestBody = &RequestBodyObject{Required: true, Content: map[string]*MediaType{}}
+		for _, contentType := range contentTypes {
+			object.RequestBody.Content[contentType] = &MediaType{Schema: schema}
+		}
+	}
+
+	for _, response := range operation.Responses {
+		responseObject := &ResponseObject{Description: response.Description}
+		if responseObject.Description == "" {
+			responseObject.Description = http.StatusText(response.Status)
+		}
+		if response.Body != nil || response.ContentType != "" {
+			contentType := response.ContentType
+			if contentType == "" {
+				contentType = fiber.MIMEApplicationJSON
+			}
+			responseObject.Content = map[string]*MediaType{contentType: {Schema: schemas.SchemaOf(response.Body)}}
+		}
+		object.Responses[strconv.Itoa(response.Status)] = responseObject
+	}
+
+	if len(object.Responses) == 0 {
+		object.Responses["default"] = &ResponseObject{Description: "undocumented response"}
+	}
+
+	return object
+}
+
+// ubah /user/:userId/order/:orderId menjadi /user/{userId}/order/{orderId}
+func convertPath(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if !strings.HasPrefix(segment, ":") {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(segment, ":"), "?")
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// getUserUserIdOrderOrderId
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	if path == "/" {
+		id += "Root"
+	}
+	return id
+}
+
+func isDocumentedMethod(method string) bool {
+	for _, m := range documentedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func setOperation(item *PathItem, method string, operation *OperationObject) {
+	switch method {
+	case http.MethodGet:
+		item.Get = operation
+	case http.MethodPut:
+		item.Put = operation
+	case http.MethodPost:
+		item.Post = operation
+	case http.MethodDelete:
+		item.Delete = operation
+	case http.MethodPatch:
+		item.Patch = operation
+	}
+}
@@ -0,0 +1,72 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// FindOperation matches a concrete request path like /user/1/order/2 against the
+// document paths and returns the operation with its path parameter values
+func (d *Document) FindOperation(method, path string) (*OperationObject, map[string]string) {
+	segments := splitPath(path)
+
+	var best *OperationObject
+	var bestParams map[string]string
+	bestStatic := -1
+	for template, item := range d.Paths {
+		operation := item.operation(method)
+		if operation == nil {
+			continue
+		}
+
+		params, static, ok := matchPath(splitPath(template), segments)
+		// path statis lebih diutamakan dari path dengan parameter
+		if ok && static > bestStatic {
+			best, bestParams, bestStatic = operation, params, static
+		}
+	}
+	return best, bestParams
+}
+
+func matchPath(template, segments []string) (map[string]string, int, bool) {
+	if len(template) != len(segments) {
+		return nil, 0, false
+	}
+
+	params := map[string]string{}
+	static := 0
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			params[part[1:len(part)-1]] = segments[i]
+			continue
+		}
+		if part != segments[i] {
+			return nil, 0, false
+		}
+		static++
+	}
+	return params, static, true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func (p *PathItem) operation(method string) *OperationObject {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return p.Get
+	case http.MethodPut:
+		return p.Put
+	case http.MethodPost:
+		return p.Post
+	case http.MethodDelete:
+		return p.Delete
+	case http.MethodPatch:
+		return p.Patch
+	}
+	return nil
+}
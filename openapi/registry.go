@@ -0,0 +1,118 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operation documents one route, schemas are inferred from the Go values in Request/Body/Type
+type Operation struct {
+	OperationID         string
+	Summary             string
+	Description         string
+	Tags                []string
+	Parameters          []Parameter
+	Request             any
+	RequestContentTypes []string
+	Responses           []Response
+	Hidden              bool
+}
+
+// Parameter is a path, query, header or cookie parameter. Type is a zero value
+// of the Go type and Validate uses the same rules as the validate struct tag.
+type Parameter struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Type        any
+	Validate    string
+	Format      string // menimpa format dari Type, contoh "date" untuk time.Time dengan layout tanggal
+}
+
+type Response struct {
+	Status      int
+	Description string
+	ContentType string
+	Body        any
+}
+
+// Registry menyimpan dokumentasi route, key-nya method + path dengan syntax fiber
+type Registry struct {
+	mu         sync.RWMutex
+	operations map[string]Operation
+}
+
+// function provider
+func NewRegistry() *Registry {
+	return &Registry{
+		operations: map[string]Operation{},
+	}
+}
+
+// daftarkan dokumentasi untuk route, path memakai syntax fiber seperti /user/:userId
+func (r *Registry) Describe(method, path string, operation Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.operations[operationKey(method, path)] = operation
+}
+
+func (r *Registry) Lookup(method, path string) (Operation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	operation, ok := r.operations[operationKey(method, path)]
+	return operation, ok
+}
+
+func operationKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+// helper response standar dto.ApiResponse
+func JSONResponse(status int, description string, body any) Response {
+	return Response{Status: status, Description: description, ContentType: "application/json", Body: body}
+}
+
+// helper parameter
+func QueryParam(name, description string, typ any) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Type: typ}
+}
+
+// parameter dari struct binding, tag param/query/header/cookie sama seperti binding.Bind
+func ParamsOf(value any) []Parameter {
+	locations := []struct{ tag, in string }{{"param", "path"}, {"query", "query"}, {"header", "header"}, {"cookie", "cookie"}}
+
+	var parameters []Parameter
+	t := reflect.TypeOf(value)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		for _, location := range locations {
+			name := field.Tag.Get(location.tag)
+			if name == "" {
+				continue
+			}
+			parameters = append(parameters, Parameter{
+				Name:        name,
+				In:          location.in,
+				Description: field.Tag.Get("description"),
+				Required:    location.in == "path",
+				Type:        reflect.Zero(field.Type).Interface(),
+				Validate:    field.Tag.Get("validate"),
+			})
+			// time dengan layout selain RFC 3339
+			switch layout := field.Tag.Get("layout"); {
+			case layout == "2006-01-02":
+				parameters[len(parameters)-1].Format = "date"
+			case layout != "" && layout != time.RFC3339:
+				parameters[len(parameters)-1].Type = ""
+			}
+			break
+		}
+	}
+	return parameters
+}
+
+var documentedMethods = []string{http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete, http.MethodPatch}
@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// SchemaGenerator builds schemas from Go types, named structs are collected into components
+type SchemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+// function provider
+func NewSchemaGenerator() *SchemaGenerator {
+	return &SchemaGenerator{
+		schemas: map[string]*Schema{},
+	}
+}
+
+// schema yang sudah terkumpul, dipakai untuk components.schemas
+func (g *SchemaGenerator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// schema untuk value, struct bernama dikembalikan sebagai $ref
+func (g *SchemaGenerator) SchemaOf(value any) *Schema {
+	if value == nil {
+		return &Schema{}
+	}
+	return g.schemaOfType(reflect.TypeOf(value))
+}
+
+func (g *SchemaGenerator) schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		// []byte di-encode base64 oleh encoding/json
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOfType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.schemas[t.Name()]; !ok {
+			// daftarkan dulu sebelum isi field, untuk struct rekursif
+			g.schemas[t.Name()] = &Schema{}
+			*g.schemas[t.Name()] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	}
+
+	// interface / any
+	return &Schema{}
+}
+
+func (g *SchemaGenerator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		if name == "-" {
+			continue
+		}
+
+		property := g.schemaOfType(field.Type)
+		if property.Ref != "" {
+			// $ref tidak boleh dicampur constraint, cukup required
+			if hasRule(field.Tag.Get("validate"), "required") {
+				schema.Required = append(schema.Required, name)
+			}
+			schema.Properties[name] = property
+			continue
+		}
+
+		if ApplyValidateRules(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+	return schema
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
+
+func hasRule(validateTag, rule string) bool {
+	for _, r := range strings.Split(validateTag, ",") {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// terjemahkan rule validator (go-playground) ke constraint schema, return true jika required
+func ApplyValidateRules(schema *Schema, validateTag string) bool {
+	required := false
+	if validateTag == "" {
+		return required
+	}
+
+	for _, rule := range strings.Split(validateTag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "email":
+			schema.Format = "email"
+		case "uuid", "uuid4":
+			schema.Format = "uuid"
+		case "url", "uri":
+			schema.Format = "uri"
+		case "datetime":
+			schema.Format = "date-time"
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema.Type, value))
+			}
+		case "min", "max", "len":
+			applyLength(schema, name, param)
+		case "gt", "gte", "lt", "lte":
+			value, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			switch name {
+			case "gt":
+				schema.ExclusiveMinimum = &value
+			case "gte":
+				schema.Minimum = &value
+			case "lt":
+				schema.ExclusiveMaximum = &value
+			case "lte":
+				schema.Maximum = &value
+			}
+		}
+	}
+	return required
+}
+
+// min/max/len berarti panjang untuk string & array, nilai untuk angka
+func applyLength(schema *Schema, rule, param string) {
+	switch schema.Type {
+	case "string", "array":
+		length, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		minimum, maximum := &schema.MinLength, &schema.MaxLength
+		if schema.Type == "array" {
+			minimum, maximum = &schema.MinItems, &schema.MaxItems
+		}
+		if rule == "min" || rule == "len" {
+			*minimum = &length
+		}
+		if rule == "max" || rule == "len" {
+			*maximum = &length
+		}
+	case "integer", "number":
+		value, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if rule == "min" || rule == "len" {
+			schema.Minimum = &value
+		}
+		if rule == "max" || rule == "len" {
+			schema.Maximum = &value
+		}
+	}
+}
+
+func enumValue(schemaType, value string) any {
+	switch schemaType {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
@@ -0,0 +1,45 @@
+package openapi
+
+import (
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"sync"
+)
+
+// Spec generates the document lazily on first use, after every route is registered on app
+type Spec struct {
+	app      *fiber.App
+	registry *Registry
+	info     Info
+
+	once     sync.Once
+	document *Document
+	json     []byte
+	err      error
+}
+
+// function provider
+func NewSpec(app *fiber.App, registry *Registry, info Info) *Spec {
+	return &Spec{
+		app:      app,
+		registry: registry,
+		info:     info,
+	}
+}
+
+func (s *Spec) generate() {
+	s.once.Do(func() {
+		s.document = Generate(s.info, s.app.GetRoutes(true), s.registry)
+		s.json, s.err = json.MarshalIndent(s.document, "", "  ")
+	})
+}
+
+func (s *Spec) Document() *Document {
+	s.generate()
+	return s.document
+}
+
+func (s *Spec) JSON() ([]byte, error) {
+	s.generate()
+	return s.json, s.err
+}
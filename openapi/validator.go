@@ -0,0 +1,220 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError is one schema violation, Path is a dotted path like data.email or items[2]
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// resolve $ref ke components.schemas
+func (d *Document) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = d.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	return schema
+}
+
+// ValidateValue checks a decoded JSON value (string, float64, bool, nil, []any, map[string]any)
+func (d *Document) ValidateValue(schema *Schema, value any, path string) []ValidationError {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return nil
+	}
+
+	var errors []ValidationError
+	fail := func(format string, args ...any) {
+		errors = append(errors, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(schema.Enum) > 0 && !containsEnum(schema.Enum, value) {
+		fail("must be one of %v", schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		// schema kosong menerima semua value
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			break
+		}
+		length := len([]rune(text))
+		if schema.MinLength != nil && length < *schema.MinLength {
+			fail("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && length > *schema.MaxLength {
+			fail("must be at most %d characters", *schema.MaxLength)
+		}
+		if message := checkFormat(schema.Format, text); message != "" {
+			fail(message)
+		}
+	case "integer", "number":
+		number, ok := value.(float64)
+		if !ok {
+			fail("must be a %v", schema.Type)
+			break
+		}
+		if schema.Type == "integer" && number != math.Trunc(number) {
+			fail("must be an integer")
+		}
+		if schema.Minimum != nil && number < *schema.Minimum {
+			fail("must be greater than or equal to %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			fail("must be less than or equal to %v", *schema.Maximum)
+		}
+		if schema.ExclusiveMinimum != nil && number <= *schema.ExclusiveMinimum {
+			fail("must be greater than %v", *schema.ExclusiveMinimum)
+		}
+		if schema.ExclusiveMaximum != nil && number >= *schema.ExclusiveMaximum {
+			fail("must be less than %v", *schema.ExclusiveMaximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			break
+		}
+		if schema.MinItems != nil && len(items) < *schema.MinItems {
+			fail("must contain at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+			fail("must contain at most %d items", *schema.MaxItems)
+		}
+		for i, item := range items {
+			errors = append(errors, d.ValidateValue(schema.Items, item, fmt.Sprintf("%v[%d]", path, i))...)
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			break
+		}
+		for _, name := range schema.Required {
+			if field, ok := object[name]; !ok || field == nil || field == "" {
+				errors = append(errors, ValidationError{Path: joinPath(path, name), Message: "is required"})
+			}
+		}
+		for name, field := range object {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			// field kosong yang tidak required tidak divalidasi, sama seperti omitempty
+			if property == nil || field == nil {
+				continue
+			}
+			errors = append(errors, d.ValidateValue(property, field, joinPath(path, name))...)
+		}
+	}
+
+	return errors
+}
+
+// CoerceParam converts a raw path/query/header/form value to the JSON type the schema expects
+func (d *Document) CoerceParam(schema *Schema, raw string) (any, error) {
+	schema = d.Resolve(schema)
+	if schema == nil {
+		return raw, nil
+	}
+
+	switch schema.Type {
+	case "integer":
+		number, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+		return float64(number), nil
+	case "number":
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return number, nil
+	case "boolean":
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return value, nil
+	case "array":
+		var items []any
+		for _, part := range strings.Split(raw, ",") {
+			item, err := d.CoerceParam(schema.Items, part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return raw, nil
+}
+
+func checkFormat(format, value string) string {
+	switch format {
+	case "email":
+		if address, err := mail.ParseAddress(value); err != nil || address.Address != value {
+			return "must be a valid email"
+		}
+	case "uuid":
+		if !uuidPattern.MatchString(value) {
+			return "must be a valid uuid"
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "must be a date in format 2006-01-02"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "uri":
+		if _, err := url.ParseRequestURI(value); err != nil {
+			return "must be a valid uri"
+		}
+	}
+	return ""
+}
+
+func containsEnum(enum []any, value any) bool {
+	for _, allowed := range enum {
+		// enum integer hasil generate bertipe int64, value json bertipe float64
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,85 @@
+package orders
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryRepository keeps the orders in memory of one process, for tests and development.
+type MemoryRepository struct {
+	mutex  sync.Mutex
+	orders []Order
+}
+
+// function provider
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+func (m *MemoryRepository) Create(ctx context.Context, order Order) (Order, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now().UTC()
+	order.ID = len(m.orders) + 1
+	order.Items = slices.Clone(order.Items)
+	order.CreatedAt, order.UpdatedAt = now, now
+	m.orders = append(m.orders, order)
+	return clone(order), nil
+}
+
+func (m *MemoryRepository) Get(ctx context.Context, id int) (Order, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if id < 1 || id > len(m.orders) {
+		return Order{}, ErrNotFound
+	}
+	return clone(m.orders[id-1]), nil
+}
+
+func (m *MemoryRepository) List(ctx context.Context, query Query) ([]Order, int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// id terbesar paling baru
+	matched := []Order{}
+	for i := len(m.orders) - 1; i >= 0; i-- {
+		order := m.orders[i]
+		if (query.UserID == "" || order.UserID == query.UserID) && (query.Status == "" || order.Status == query.Status) {
+			matched = append(matched, clone(order))
+		}
+	}
+
+	if query.PerPage <= 0 {
+		return matched, len(matched), nil
+	}
+	start := (max(query.Page, 1) - 1) * query.PerPage
+	if start >= len(matched) {
+		return []Order{}, len(matched), nil
+	}
+	return matched[start:min(start+query.PerPage, len(matched))], len(matched), nil
+}
+
+func (m *MemoryRepository) UpdateStatus(ctx context.Context, id int, status Status) (Order, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if id < 1 || id > len(m.orders) {
+		return Order{}, ErrNotFound
+	}
+	order, err := m.orders[id-1].Transition(status)
+	if err != nil {
+		return Order{}, err
+	}
+	m.orders[id-1] = order
+	return clone(order), nil
+}
+
+// items di-copy supaya order yang dikembalikan tidak berbagi slice dengan repository
+func clone(order Order) Order {
+	order.Items = slices.Clone(order.Items)
+	return order
+}
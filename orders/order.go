@@ -0,0 +1,134 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+var (
+	ErrNotFound          = errors.New("order not found")
+	ErrInvalidTransition = errors.New("invalid order status transition")
+	ErrInvalidTotal      = errors.New("order total is out of range")
+	// ErrSQLiteUnavailable is returned by Config.Open for the sqlite driver in a build
+	// without cgo, which the sqlite3 driver needs
+	ErrSQLiteUnavailable = errors.New("orders sqlite driver requires a build with CGO_ENABLED=1")
+)
+
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusShipped   Status = "shipped"
+	StatusDelivered Status = "delivered"
+	StatusCancelled Status = "cancelled"
+)
+
+// status yang boleh dituju dari setiap status, delivered & cancelled adalah status akhir
+var transitions = map[Status][]Status{
+	StatusPending: {StatusPaid, StatusCancelled},
+	StatusPaid:    {StatusShipped, StatusCancelled},
+	StatusShipped: {StatusDelivered},
+}
+
+// CanTransition reports whether an order with status s may move to status to.
+func (s Status) CanTransition(to Status) bool {
+	return slices.Contains(transitions[s], to)
+}
+
+// Item is one line of an order. Price is per unit in the smallest currency unit.
+type Item struct {
+	Product  string `json:"product"`
+	Quantity int    `json:"quantity"`
+	Price    int64  `json:"price"`
+}
+
+// Order is placed by a user and starts as pending. Its status only changes along the
+// allowed transitions, see Status.CanTransition.
+type Order struct {
+	ID        int       `json:"id"`
+	UserID    string    `json:"user_id"`
+	Status    Status    `json:"status"`
+	Items     []Item    `json:"items"`
+	Total     int64     `json:"total"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// order baru dengan status pending, belum disimpan ke repository. ErrInvalidTotal jika
+// ada quantity / price negatif atau total tidak muat di int64
+func NewOrder(userID string, items []Item) (Order, error) {
+	order := Order{
+		UserID: userID,
+		Status: StatusPending,
+		Items:  items,
+	}
+	for _, item := range items {
+		if item.Quantity < 0 || item.Price < 0 {
+			return Order{}, fmt.Errorf("%w : negative quantity or price", ErrInvalidTotal)
+		}
+		if item.Quantity > 0 && item.Price > (math.MaxInt64-order.Total)/int64(item.Quantity) {
+			return Order{}, ErrInvalidTotal
+		}
+		order.Total += int64(item.Quantity) * item.Price
+	}
+	return order, nil
+}
+
+// Transition returns the order with the new status, or ErrInvalidTransition when the
+// current status does not allow it.
+func (o Order) Transition(to Status) (Order, error) {
+	if !o.Status.CanTransition(to) {
+		return o, fmt.Errorf("%w from %v to %v", ErrInvalidTransition, o.Status, to)
+	}
+	o.Status = to
+	o.UpdatedAt = time.Now().UTC()
+	return o, nil
+}
+
+// Query filters and paginates List. Zero values do not filter.
+type Query struct {
+	UserID  string
+	Status  Status
+	Page    int
+	PerPage int
+}
+
+// Repository keeps the orders. Create assigns the id, starting at 1. Orders are listed
+// newest first.
+type Repository interface {
+	Create(ctx context.Context, order Order) (Order, error)
+	Get(ctx context.Context, id int) (Order, error)
+	// List returns one page of the matching orders and the number of all matching orders
+	List(ctx context.Context, query Query) ([]Order, int, error)
+	// UpdateStatus moves the order to status, ErrInvalidTransition when it is not allowed
+	UpdateStatus(ctx context.Context, id int, status Status) (Order, error)
+}
+
+// Config is loaded from the "orders" section of config.json. Driver is "sqlite" or
+// "memory"; the memory repository is not shared between prefork processes.
+type Config struct {
+	Driver string `mapstructure:"driver"`
+	Path   string `mapstructure:"path"`
+}
+
+// buka repository sesuai driver
+func (c Config) Open() (Repository, error) {
+	switch c.Driver {
+	case "memory":
+		return NewMemoryRepository(), nil
+	case "sqlite", "":
+		if err := os.MkdirAll(filepath.Dir(c.Path), 0o755); err != nil {
+			return nil, err
+		}
+		return openSQLite(c.Path)
+	default:
+		return nil, fmt.Errorf("unknown orders driver %q", c.Driver)
+	}
+}
@@ -0,0 +1,172 @@
+//go:build cgo
+
+package orders
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	_ "github.com/mattn/go-sqlite3"
+	"net/url"
+	"time"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS orders (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id    TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	items      TEXT NOT NULL,
+	total      INTEGER NOT NULL,
+	created_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS orders_user_id ON orders (user_id, id);
+`
+
+// SQLiteRepository keeps the orders in a SQLite database, shared by every prefork
+// process. Items are stored as JSON in the order row.
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// function provider, tabel dibuat kalau belum ada
+func NewSQLiteRepository(path string) (*SQLiteRepository, error) {
+	// wal & busy timeout supaya proses prefork bisa menulis bergantian, transaksi langsung
+	// mengambil lock tulis supaya cek status & update tidak balapan
+	options := url.Values{}
+	options.Set("_busy_timeout", "5000")
+	options.Set("_journal_mode", "WAL")
+	options.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?"+options.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteRepository{db: db}, nil
+}
+
+func (s *SQLiteRepository) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteRepository) Create(ctx context.Context, order Order) (Order, error) {
+	items, err := json.Marshal(order.Items)
+	if err != nil {
+		return Order{}, err
+	}
+
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO orders (user_id, status, items, total, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		order.UserID, order.Status, string(items), order.Total, now, now,
+	)
+	if err != nil {
+		return Order{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return Order{}, err
+	}
+
+	order.ID = int(id)
+	order.CreatedAt, order.UpdatedAt = now, now
+	return order, nil
+}
+
+func (s *SQLiteRepository) Get(ctx context.Context, id int) (Order, error) {
+	return s.get(ctx, s.db, id)
+}
+
+func (s *SQLiteRepository) List(ctx context.Context, query Query) ([]Order, int, error) {
+	where := " WHERE (? = '' OR user_id = ?) AND (? = '' OR status = ?)"
+	args := []any{query.UserID, query.UserID, query.Status, query.Status}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// limit -1 berarti tanpa batas
+	limit, offset := -1, 0
+	if query.PerPage > 0 {
+		limit, offset = query.PerPage, (max(query.Page, 1)-1)*query.PerPage
+	}
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, user_id, status, items, total, created_at, updated_at FROM orders"+where+" ORDER BY id DESC LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	found := []Order{}
+	for rows.Next() {
+		order, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		found = append(found, order)
+	}
+	return found, total, rows.Err()
+}
+
+// cek transisi & update dalam satu transaksi, proses lain menunggu lock tulis
+func (s *SQLiteRepository) UpdateStatus(ctx context.Context, id int, status Status) (Order, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, err
+	}
+	defer tx.Rollback()
+
+	order, err := s.get(ctx, tx, id)
+	if err != nil {
+		return Order{}, err
+	}
+	order, err = order.Transition(status)
+	if err != nil {
+		return Order{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE orders SET status = ?, updated_at = ? WHERE id = ?", order.Status, order.UpdatedAt, order.ID); err != nil {
+		return Order{}, err
+	}
+	return order, tx.Commit()
+}
+
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (s *SQLiteRepository) get(ctx context.Context, db queryer, id int) (Order, error) {
+	row := db.QueryRowContext(ctx, "SELECT id, user_id, status, items, total, created_at, updated_at FROM orders WHERE id = ?", id)
+	order, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Order{}, ErrNotFound
+	}
+	return order, err
+}
+
+func scan(row interface{ Scan(dest ...any) error }) (Order, error) {
+	var order Order
+	var items string
+	if err := row.Scan(&order.ID, &order.UserID, &order.Status, &items, &order.Total, &order.CreatedAt, &order.UpdatedAt); err != nil {
+		return Order{}, err
+	}
+	if err := json.Unmarshal([]byte(items), &order.Items); err != nil {
+		return Order{}, err
+	}
+	order.CreatedAt, order.UpdatedAt = order.CreatedAt.UTC(), order.UpdatedAt.UTC()
+	return order, nil
+}
+
+// dipakai Config.Open, versi tanpa cgo ada di sqlite_nocgo.go
+func openSQLite(path string) (Repository, error) {
+	return NewSQLiteRepository(path)
+}
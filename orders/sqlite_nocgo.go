@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package orders
+
+// driver sqlite3 (github.com/mattn/go-sqlite3) butuh cgo. tanpa cgo binary tetap bisa
+// di-build dengan driver memory, driver sqlite ditolak saat repository dibuka
+func openSQLite(path string) (Repository, error) {
+	return nil, ErrSQLiteUnavailable
+}
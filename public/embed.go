@@ -0,0 +1,13 @@
+package public
+
+import (
+	"embed"
+)
+
+// bundle redoc untuk halaman /docs, versi & checksum tercatat di vendor/redoc, lihat fetch_redoc.go
+//go:generate go run fetch_redoc.go
+
+// static assets yang di-embed ke binary, dilayani di /public
+//
+//go:embed css vendor *.txt
+var Files embed.FS
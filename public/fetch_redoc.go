@@ -0,0 +1,73 @@
+//go:build ignore
+
+// download bundle redoc yang di-pin, dijalankan lewat `go generate ./public`. bundle hanya
+// ditulis jika sha-256 sama dengan vendor/redoc/redoc.standalone.js.sha256
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	redocURL     = "https://cdn.redoc.ly/redoc/v2.1.3/bundles/redoc.standalone.js"
+	redocFile    = "vendor/redoc/redoc.standalone.js"
+	checksumFile = redocFile + ".sha256"
+)
+
+func main() {
+	if err := fetch(); err != nil {
+		log.Fatalf("error cant fetch redoc : %v", err)
+	}
+}
+
+func fetch() error {
+	pinned, err := os.ReadFile(checksumFile)
+	if err != nil {
+		return fmt.Errorf("checksum of the pinned bundle is required : %w", err)
+	}
+	fields := strings.Fields(string(pinned))
+	if len(fields) == 0 {
+		return fmt.Errorf("%v is empty", checksumFile)
+	}
+	expected := strings.ToLower(fields[0])
+
+	response, err := http.Get(redocURL)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%v : %v", redocURL, response.Status)
+	}
+
+	// tulis ke file sementara, baru di-rename setelah checksum cocok
+	temp, err := os.CreateTemp(filepath.Dir(redocFile), ".redoc-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(temp, hash), response.Body)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != expected {
+		return fmt.Errorf("checksum mismatch for %v : got %v, pinned %v", redocURL, sum, expected)
+	}
+	if err := os.Chmod(temp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), redocFile)
+}
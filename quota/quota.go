@@ -0,0 +1,270 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_fiber/catalogue"
+	"go_fiber/filelock"
+	"go_fiber/upload"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrUserQuota   = fmt.Errorf("%w : user storage quota exceeded", upload.ErrQuotaExceeded)
+	ErrGlobalQuota = fmt.Errorf("%w : server storage quota exceeded", upload.ErrInsufficientStorage)
+)
+
+// Config is loaded from the "quota" section of config.json, limits are in bytes and
+// 0 means unlimited. Directory holds the usage counter of every owner.
+type Config struct {
+	User      int64  `mapstructure:"user"`
+	Global    int64  `mapstructure:"global"`
+	Directory string `mapstructure:"directory"`
+}
+
+// UsageReporter reports the bytes used on disk, e.g. storage.ContentStorage.
+type UsageReporter interface {
+	Usage(ctx context.Context) (int64, error)
+}
+
+// Usage is the storage used by one owner. Limit and Remaining are 0 when unlimited.
+type Usage struct {
+	Owner     string `json:"owner"`
+	Files     int    `json:"files"`
+	Used      int64  `json:"used"`
+	Limit     int64  `json:"limit"`
+	Remaining int64  `json:"remaining"`
+}
+
+// Checker enforces the quotas. The usage of a user is the size of their files and
+// their image variants, so duplicate content still counts for every owner; the global
+// usage is what the storage really uses on disk.
+//
+// The usage of every owner is kept in a counter file under Config.Directory, updated
+// under a file lock so the prefork processes share it. A missing counter is rebuilt
+// once from the catalogue.
+type Checker struct {
+	config    Config
+	catalogue catalogue.Store
+	storage   UsageReporter
+}
+
+// function provider
+func NewChecker(config Config, store catalogue.Store, storage UsageReporter) *Checker {
+	return &Checker{
+		config:    config,
+		catalogue: store,
+		storage:   storage,
+	}
+}
+
+// counter pemakaian satu owner. ukuran disimpan per id file, supaya charge & release
+// yang diulang tidak terhitung dua kali
+type counter struct {
+	Files map[string]int64 `json:"files"`
+	Used  int64            `json:"used"`
+}
+
+func (c *Checker) Usage(ctx context.Context, owner string) (Usage, error) {
+	counter, err := c.update(ctx, owner, nil)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage := Usage{Owner: owner, Files: len(counter.Files), Used: counter.Used, Limit: c.config.User}
+	if usage.Limit > 0 {
+		usage.Remaining = max(usage.Limit-usage.Used, 0)
+	}
+	return usage, nil
+}
+
+// Check reports whether owner can store size more bytes, before the upload is read.
+func (c *Checker) Check(ctx context.Context, owner string, size int64) error {
+	if err := c.checkUser(ctx, owner, "", size); err != nil {
+		return err
+	}
+	return c.checkGlobal(ctx, size)
+}
+
+// file id yang sudah dihitung tidak dihitung lagi
+func (c *Checker) checkUser(ctx context.Context, owner string, id string, size int64) error {
+	if c.config.User <= 0 {
+		return nil
+	}
+	usage, err := c.update(ctx, owner, nil)
+	if err != nil {
+		return err
+	}
+	if usage.Used-usage.Files[id]+size > c.config.User {
+		return ErrUserQuota
+	}
+	return nil
+}
+
+func (c *Checker) checkGlobal(ctx context.Context, size int64) error {
+	if c.config.Global <= 0 {
+		return nil
+	}
+	used, err := c.storage.Usage(ctx)
+	if err != nil {
+		return err
+	}
+	if used+size > c.config.Global {
+		return ErrGlobalQuota
+	}
+	return nil
+}
+
+// Process checks a stored upload before its image variants are generated, so an upload
+// over the quota is refused without writing them. The storage already holds the file
+// (once, if the content was a duplicate) so nothing is added to the global usage.
+func (c *Checker) Process(ctx context.Context, file *upload.File) error {
+	if err := c.checkUser(ctx, file.Owner, file.ID, file.Size); err != nil {
+		return err
+	}
+	return c.checkGlobal(ctx, 0)
+}
+
+// Release removes a deleted file from the usage of its owner.
+func (c *Checker) Release(ctx context.Context, owner string, id string) error {
+	_, err := c.update(ctx, owner, func(counter *counter) error {
+		counter.Used -= counter.Files[id]
+		delete(counter.Files, id)
+		return nil
+	})
+	return err
+}
+
+// Recorder returns the processor that charges an upload and its variants to the usage
+// of its owner. It runs after imaging.Processor; when the variants bring the owner
+// over the quota the upload is refused and the pipeline removes them again.
+func (c *Checker) Recorder() *Recorder {
+	return &Recorder{checker: c}
+}
+
+// Recorder charges completed uploads to their owner, see Checker.Recorder.
+type Recorder struct {
+	checker *Checker
+}
+
+func (r *Recorder) Process(ctx context.Context, file *upload.File) error {
+	// variant sudah tersimpan, global dicek dengan yang sudah ada di disk
+	if err := r.checker.checkGlobal(ctx, 0); err != nil {
+		return err
+	}
+
+	size := file.Size + file.VariantSize
+	_, err := r.checker.update(ctx, file.Owner, func(counter *counter) error {
+		used := counter.Used - counter.Files[file.ID] + size
+		if r.checker.config.User > 0 && used > r.checker.config.User {
+			return ErrUserQuota
+		}
+		counter.Files[file.ID] = size
+		counter.Used = used
+		return nil
+	})
+	return err
+}
+
+func (r *Recorder) Revert(ctx context.Context, file *upload.File) error {
+	return r.checker.Release(ctx, file.Owner, file.ID)
+}
+
+// baca counter owner di bawah lock lalu jalankan change jika ada. counter yang belum ada
+// dihitung ulang dari catalogue dan disimpan
+func (c *Checker) update(ctx context.Context, owner string, change func(*counter) error) (*counter, error) {
+	if err := os.MkdirAll(c.config.Directory, 0o755); err != nil {
+		return nil, err
+	}
+	path := c.path(owner)
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	counter, changed, err := c.load(ctx, owner, path)
+	if err != nil {
+		return nil, err
+	}
+	if change != nil {
+		if err := change(counter); err != nil {
+			return nil, err
+		}
+		changed = true
+	}
+	if !changed {
+		return counter, nil
+	}
+	return counter, c.save(path, counter)
+}
+
+// counter dari file, changed true jika baru dihitung ulang dari catalogue
+func (c *Checker) load(ctx context.Context, owner string, path string) (*counter, bool, error) {
+	encoded, err := os.ReadFile(path)
+	if err == nil {
+		usage := &counter{}
+		if err := json.Unmarshal(encoded, usage); err != nil {
+			return nil, false, fmt.Errorf("invalid quota counter %v : %w", path, err)
+		}
+		if usage.Files == nil {
+			usage.Files = map[string]int64{}
+		}
+		return usage, false, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, false, err
+	}
+
+	// Query.Owner kosong berarti semua owner, jadi owner tetap difilter di sini
+	usage := &counter{Files: map[string]int64{}}
+	entries, _, err := c.catalogue.List(ctx, catalogue.Query{Owner: owner})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, entry := range entries {
+		if entry.Owner == owner {
+			usage.Files[entry.ID] = entry.Size + entry.VariantSize
+		}
+	}
+	for _, size := range usage.Files {
+		usage.Used += size
+	}
+	return usage, true, nil
+}
+
+// tulis ke file sementara lalu rename, supaya proses lain tidak membaca counter setengah jadi
+func (c *Checker) save(path string, usage *counter) error {
+	encoded, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(c.config.Directory, ".counter-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(encoded)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// file counter per owner, id dibersihkan agar tidak bisa keluar dari directory. file
+// tanpa owner dicatat di "_unowned", id user selalu uuid
+func (c *Checker) path(owner string) string {
+	if owner == "" {
+		owner = "_unowned"
+	}
+	return filepath.Join(c.config.Directory, filepath.Base(filepath.Clean("/"+owner))+".json")
+}
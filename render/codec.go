@@ -0,0 +1,142 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	MIMEApplicationMsgPack = "application/msgpack"
+	MIMEApplicationCBOR    = "application/cbor"
+)
+
+// Codec encodes response bodies and decodes request bodies for one media type
+type Codec interface {
+	ContentType() string
+	Marshal(value any) ([]byte, error)
+	Unmarshal(data []byte, value any) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string                    { return "application/json" }
+func (jsonCodec) Marshal(value any) ([]byte, error)      { return json.Marshal(value) }
+func (jsonCodec) Unmarshal(data []byte, value any) error { return json.Unmarshal(data, value) }
+
+type xmlCodec struct{}
+
+func (xmlCodec) ContentType() string { return "application/xml" }
+
+func (xmlCodec) Marshal(value any) ([]byte, error) {
+	body, err := xml.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func (xmlCodec) Unmarshal(data []byte, value any) error { return xml.Unmarshal(data, value) }
+
+// msgpack & cbor memakai tag json, supaya dto tidak perlu tag tambahan
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string { return MIMEApplicationMsgPack }
+
+func (msgpackCodec) Marshal(value any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := msgpack.NewEncoder(&buffer)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, value any) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(value)
+}
+
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string                    { return MIMEApplicationCBOR }
+func (cborCodec) Marshal(value any) ([]byte, error)      { return cbor.Marshal(value) }
+func (cborCodec) Unmarshal(data []byte, value any) error { return cbor.Unmarshal(data, value) }
+
+// urutan codec = urutan preferensi server jika q-value client sama
+var codecs = []Codec{jsonCodec{}, xmlCodec{}, msgpackCodec{}, cborCodec{}, protobufCodec{}}
+
+// codec yang hanya bisa encode tipe tertentu, misal protobuf
+type selectiveCodec interface {
+	Supports(value any) bool
+}
+
+// daftarkan codec tambahan
+func RegisterCodec(codec Codec) {
+	for i, existing := range codecs {
+		if existing.ContentType() == codec.ContentType() {
+			codecs[i] = codec
+			return
+		}
+	}
+	codecs = append(codecs, codec)
+}
+
+// alias media type yang umum dipakai client
+var aliases = map[string]string{
+	"text/xml":                "application/xml",
+	"application/x-msgpack":   MIMEApplicationMsgPack,
+	"application/vnd.msgpack": MIMEApplicationMsgPack,
+	"application/protobuf":    MIMEApplicationProtobuf,
+}
+
+func codecFor(mediaType string) Codec {
+	if alias, ok := aliases[mediaType]; ok {
+		mediaType = alias
+	}
+	for _, codec := range codecs {
+		if codec.ContentType() == mediaType {
+			return codec
+		}
+	}
+	return nil
+}
+
+const MIMEApplicationProtobuf = "application/x-protobuf"
+
+// ProtoMessage is implemented by dto types that have a protobuf definition in proto/api.proto
+type ProtoMessage interface {
+	MarshalProto() ([]byte, error)
+	UnmarshalProto(data []byte) error
+}
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return MIMEApplicationProtobuf }
+
+// hanya dto dengan definisi proto yang bisa di-encode
+func (protobufCodec) Supports(value any) bool {
+	_, ok := value.(ProtoMessage)
+	return ok
+}
+
+func (protobufCodec) Marshal(value any) ([]byte, error) {
+	message, ok := value.(ProtoMessage)
+	if !ok {
+		return nil, fmt.Errorf("type %T has no protobuf definition", value)
+	}
+	return message.MarshalProto()
+}
+
+func (protobufCodec) Unmarshal(data []byte, value any) error {
+	message, ok := value.(ProtoMessage)
+	if !ok {
+		return fmt.Errorf("type %T has no protobuf definition", value)
+	}
+	return message.UnmarshalProto(data)
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// media type yang bisa dipilih client lewat Accept untuk body ini
+func offers(body any) []string {
+	var result []string
+	for _, codec := range codecs {
+		if selective, ok := codec.(selectiveCodec); ok && !selective.Supports(body) {
+			continue
+		}
+		result = append(result, codec.ContentType())
+	}
+	// alias diurutkan supaya pilihan di antara media type yang sama disukai selalu sama
+	names := make([]string, 0, len(aliases))
+	for alias := range aliases {
+		names = append(names, alias)
+	}
+	sort.Strings(names)
+	for _, alias := range names {
+		if codec := codecFor(aliases[alias]); codec != nil {
+			if selective, ok := codec.(selectiveCodec); ok && !selective.Supports(body) {
+				continue
+			}
+		}
+		result = append(result, alias)
+	}
+	return result
+}
+
+// Respond writes body with the current status, encoded in the media type picked from Accept.
+// When nothing acceptable is offered the client gets 406 as JSON.
+func Respond(ctx *fiber.Ctx, body any) error {
+	ctx.Vary(fiber.HeaderAccept)
+
+	available := offers(body)
+	mediaType, ok := Negotiate(ctx.Get(fiber.HeaderAccept), available)
+	if !ok {
+		ctx.Status(http.StatusNotAcceptable)
+		return ctx.JSON(map[string]any{
+			"status_code": http.StatusNotAcceptable,
+			"status":      "not acceptable",
+			"message":     fmt.Sprintf("supported media types are [%v]", strings.Join(available, ", ")),
+		})
+	}
+
+	encoded, err := codecFor(mediaType).Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderContentType, mediaType)
+	return ctx.Send(encoded)
+}
+
+// Decode parses the request body by Content-Type. JSON, XML and form go through
+// BodyParser, other media types through the registered codecs. The returned error
+// is a *fiber.Error, 415 for unsupported media types and 400 for malformed bodies.
+// A request without Content-Type is decoded as JSON.
+func Decode(ctx *fiber.Ctx, dst any) error {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(ctx.Get(fiber.HeaderContentType), ";")[0]))
+
+	switch {
+	case mediaType == "":
+		// BodyParser menolak request tanpa Content-Type, langsung lewat codec json
+		if err := codecFor(fiber.MIMEApplicationJSON).Unmarshal(ctx.Body(), dst); err != nil {
+			return fiber.NewError(http.StatusBadRequest, err.Error())
+		}
+		return nil
+	case mediaType == fiber.MIMEApplicationJSON, mediaType == fiber.MIMEApplicationXML, mediaType == fiber.MIMETextXML,
+		mediaType == fiber.MIMEApplicationForm, mediaType == fiber.MIMEMultipartForm:
+		if err := ctx.BodyParser(dst); err != nil {
+			return fiber.NewError(http.StatusBadRequest, err.Error())
+		}
+		return nil
+	}
+
+	codec := codecFor(mediaType)
+	if codec == nil {
+		return fiber.NewError(http.StatusUnsupportedMediaType, fmt.Sprintf("unsupported content type [%v]", mediaType))
+	}
+	if err := codec.Unmarshal(ctx.Body(), dst); err != nil {
+		return fiber.NewError(http.StatusBadRequest, err.Error())
+	}
+	return nil
+}
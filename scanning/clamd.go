@@ -0,0 +1,150 @@
+package scanning
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ClamdConfig is loaded from the "scanning" section of config.json.
+type ClamdConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Network   string        `mapstructure:"network"` // tcp atau unix
+	Address   string        `mapstructure:"address"`
+	Timeout   time.Duration `mapstructure:"timeout"`
+	ChunkSize int           `mapstructure:"chunk_size"`
+}
+
+// ClamdScanner talks to a clamd daemon using the INSTREAM command, so the file is
+// streamed over the socket and clamd does not need access to the upload directory.
+type ClamdScanner struct {
+	config ClamdConfig
+	dialer net.Dialer
+}
+
+// function provider
+func NewClamdScanner(config ClamdConfig) *ClamdScanner {
+	if config.Network == "" {
+		config.Network = "tcp"
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.ChunkSize <= 0 {
+		config.ChunkSize = 64 * 1024
+	}
+
+	return &ClamdScanner{
+		config: config,
+	}
+}
+
+// cek koneksi ke clamd, balasan yang diharapkan PONG
+func (c *ClamdScanner) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING", nil)
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected clamd reply %q", reply)
+	}
+	return nil
+}
+
+func (c *ClamdScanner) Scan(ctx context.Context, reader io.Reader) (Result, error) {
+	reply, err := c.command(ctx, "INSTREAM", func(conn net.Conn) error {
+		return c.stream(conn, reader)
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return parseReply(reply)
+}
+
+// kirim satu command dengan prefix z (reply diakhiri NUL), body ditulis oleh write jika ada
+func (c *ClamdScanner) command(parent context.Context, name string, write func(conn net.Conn) error) (string, error) {
+	ctx, cancel := context.WithTimeout(parent, c.config.Timeout)
+	defer cancel()
+	contextError := func(err error) error {
+		return c.contextError(parent, ctx, err)
+	}
+
+	conn, err := c.dialer.DialContext(ctx, c.config.Network, c.config.Address)
+	if err != nil {
+		return "", contextError(err)
+	}
+	defer conn.Close()
+
+	// koneksi ditutup ketika context selesai agar read/write yang sedang berjalan berhenti
+	stop := context.AfterFunc(ctx, func() {
+		conn.Close()
+	})
+	defer stop()
+
+	if _, err := io.WriteString(conn, "z"+name+"\x00"); err != nil {
+		return "", contextError(err)
+	}
+	if write != nil {
+		if err := write(conn); err != nil {
+			return "", contextError(err)
+		}
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil {
+		return "", contextError(err)
+	}
+	return strings.TrimSuffix(reply, "\x00"), nil
+}
+
+// body INSTREAM berupa chunk dengan panjang 4 byte big endian, diakhiri chunk kosong
+func (c *ClamdScanner) stream(conn net.Conn, reader io.Reader) error {
+	chunk := make([]byte, 4+c.config.ChunkSize)
+	for {
+		n, err := reader.Read(chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk, uint32(n))
+			if _, err := conn.Write(chunk[:4+n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := conn.Write([]byte{0, 0, 0, 0})
+	return err
+}
+
+// contoh balasan: "stream: OK", "stream: Eicar-Signature FOUND", "INSTREAM size limit exceeded. ERROR"
+func parseReply(reply string) (Result, error) {
+	status := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case status == "OK":
+		return Result{}, nil
+	case strings.HasSuffix(status, " FOUND"):
+		return Result{Infected: true, Signature: strings.TrimSuffix(status, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("clamd : %v", reply)
+}
+
+// error jaringan karena koneksi ditutup oleh context diganti dengan error context. timeout
+// request tetap context error, timeout clamd sendiri bukan, supaya tidak dianggap timeout route
+func (c *ClamdScanner) contextError(parent context.Context, ctx context.Context, err error) error {
+	if parentErr := parent.Err(); parentErr != nil {
+		return parentErr
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("clamd did not reply within %v", c.config.Timeout)
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package scanning
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go_fiber/storage"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	StatusPending = "pending"
+	StatusClean   = "clean"
+)
+
+// Record is the scan state of a stored file.
+type Record struct {
+	Status    string    `json:"status"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+// Quarantine keeps the scan state of every uploaded file next to it in storage, so all
+// prefork processes see the same state. A file without a clean record is quarantined.
+type Quarantine struct {
+	storage storage.Storage
+}
+
+// function provider
+func NewQuarantine(store storage.Storage) *Quarantine {
+	return &Quarantine{
+		storage: store,
+	}
+}
+
+// nama record di storage, disimpan di samping file
+func RecordName(name string) string {
+	return name + ".scan"
+}
+
+func (q *Quarantine) Mark(ctx context.Context, name string, record Record) error {
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	_, err = q.storage.Save(ctx, RecordName(name), strings.NewReader(string(encoded)))
+	return err
+}
+
+// status file, pending jika belum pernah di-scan
+func (q *Quarantine) Status(ctx context.Context, name string) (string, error) {
+	reader, err := q.storage.Open(ctx, RecordName(name))
+	if errors.Is(err, storage.ErrNotFound) {
+		return StatusPending, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+
+	encoded, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	var record Record
+	if err := json.Unmarshal(encoded, &record); err != nil {
+		return "", err
+	}
+	return record.Status, nil
+}
+
+func (q *Quarantine) Released(ctx context.Context, name string) (bool, error) {
+	status, err := q.Status(ctx, name)
+	return status == StatusClean, err
+}
+
+func (q *Quarantine) Remove(ctx context.Context, name string) error {
+	err := q.storage.Remove(ctx, RecordName(name))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	return err
+}
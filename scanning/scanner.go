@@ -0,0 +1,85 @@
+package scanning
+
+import (
+	"context"
+	"fmt"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"io"
+	"time"
+)
+
+var (
+	ErrInfected    = fmt.Errorf("%w : file is infected", upload.ErrRejected)
+	ErrUnavailable = fmt.Errorf("%w : malware scanner unavailable", upload.ErrUnavailable)
+)
+
+// Result is the verdict of a scanner for one file. Signature names the malware found.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner inspects file content for malware.
+type Scanner interface {
+	Scan(ctx context.Context, reader io.Reader) (Result, error)
+}
+
+// SkipScanner reports every file as clean without reading it. It is only meant for
+// development without clamd, so uploads are released from the quarantine.
+type SkipScanner struct{}
+
+func (SkipScanner) Scan(ctx context.Context, reader io.Reader) (Result, error) {
+	return Result{}, nil
+}
+
+// Processor scans every completed upload before any other processor touches it. The
+// file stays quarantined while it is scanned; infected files fail the upload with
+// ErrInfected and a scanner that cannot be reached fails it with ErrUnavailable.
+type Processor struct {
+	scanner    Scanner
+	quarantine *Quarantine
+	storage    storage.Storage
+}
+
+// function provider
+func NewProcessor(scanner Scanner, quarantine *Quarantine, store storage.Storage) *Processor {
+	return &Processor{
+		scanner:    scanner,
+		quarantine: quarantine,
+		storage:    store,
+	}
+}
+
+func (p *Processor) Process(ctx context.Context, file *upload.File) error {
+	// status pending ditulis ulang, status clean dari upload sebelumnya dengan nama sama tidak berlaku lagi
+	if err := p.quarantine.Mark(ctx, file.ID, Record{Status: StatusPending}); err != nil {
+		return err
+	}
+
+	result, err := p.scan(ctx, file.ID)
+	if err != nil {
+		p.quarantine.Remove(ctx, file.ID)
+		return fmt.Errorf("%w : %w", ErrUnavailable, err)
+	}
+	if result.Infected {
+		p.quarantine.Remove(ctx, file.ID)
+		return fmt.Errorf("%w (%v)", ErrInfected, result.Signature)
+	}
+
+	return p.quarantine.Mark(ctx, file.ID, Record{Status: StatusClean, ScannedAt: time.Now().UTC()})
+}
+
+func (p *Processor) Revert(ctx context.Context, file *upload.File) error {
+	return p.quarantine.Remove(ctx, file.ID)
+}
+
+func (p *Processor) scan(ctx context.Context, name string) (Result, error) {
+	reader, err := p.storage.Open(ctx, name)
+	if err != nil {
+		return Result{}, err
+	}
+	defer reader.Close()
+
+	return p.scanner.Scan(ctx, reader)
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"net"
+	"net/http"
+)
+
+// app fiber kecil yang hanya me-redirect request http ke https
+func NewHTTPSRedirectApp(httpsPort string) *fiber.App {
+	app := fiber.New(fiber.Config{
+		DisableStartupMessage: true,
+	})
+
+	app.Use(func(ctx *fiber.Ctx) error {
+		host := ctx.Hostname()
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+
+		return ctx.Redirect("https://"+host+string(ctx.Request().URI().RequestURI()), http.StatusPermanentRedirect)
+	})
+
+	return app
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// TLSConfig is loaded from the "tls" section of config.json
+type TLSConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	CertFile       string   `mapstructure:"cert_file"`
+	KeyFile        string   `mapstructure:"key_file"`
+	MinVersion     string   `mapstructure:"min_version"`
+	CipherSuites   []string `mapstructure:"cipher_suites"`
+	ReloadInterval int      `mapstructure:"reload_interval"` // detik
+
+	// mutual TLS: none, request, verify_if_given, require
+	ClientAuth   string `mapstructure:"client_auth"`
+	ClientCAFile string `mapstructure:"client_ca_file"`
+
+	// listener http yang redirect semua request ke https
+	RedirectHTTP bool   `mapstructure:"redirect_http"`
+	HTTPPort     string `mapstructure:"http_port"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                tls.NoClientCert,
+	"none":            tls.NoClientCert,
+	"request":         tls.RequestClientCert,
+	"verify_if_given": tls.VerifyClientCertIfGiven,
+	"require":         tls.RequireAndVerifyClientCert,
+}
+
+// build *tls.Config dari config, certificate di-reload otomatis ketika file berubah
+func NewTLSConfig(config TLSConfig) (*tls.Config, error) {
+	reloadInterval := time.Duration(config.ReloadInterval) * time.Second
+	if reloadInterval <= 0 {
+		reloadInterval = time.Minute
+	}
+
+	reloader, err := NewCertReloader(config.CertFile, config.KeyFile, reloadInterval)
+	if err != nil {
+		return nil, fmt.Errorf("load certificate : %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	if config.MinVersion != "" {
+		version, ok := tlsVersions[config.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unknown tls min_version [%v]", config.MinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+
+	if len(config.CipherSuites) > 0 {
+		suites, err := parseCipherSuites(config.CipherSuites)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.CipherSuites = suites
+	}
+
+	clientAuth, ok := clientAuthTypes[strings.ToLower(config.ClientAuth)]
+	if !ok {
+		return nil, fmt.Errorf("unknown tls client_auth [%v]", config.ClientAuth)
+	}
+	tlsConfig.ClientAuth = clientAuth
+
+	if config.ClientCAFile != "" {
+		pem, err := os.ReadFile(config.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client ca : %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in client ca [%v]", config.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+	} else if clientAuth == tls.VerifyClientCertIfGiven || clientAuth == tls.RequireAndVerifyClientCert {
+		return nil, fmt.Errorf("tls client_auth [%v] requires client_ca_file", config.ClientAuth)
+	}
+
+	return tlsConfig, nil
+}
+
+// nama cipher suite mengikuti penamaan crypto/tls, misal TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+func parseCipherSuites(names []string) ([]uint16, error) {
+	available := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		available[suite.Name] = suite.ID
+	}
+
+	var suites []uint16
+	for _, name := range names {
+		id, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite [%v]", name)
+		}
+		suites = append(suites, id)
+	}
+	return suites, nil
+}
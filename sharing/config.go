@@ -0,0 +1,32 @@
+package sharing
+
+import (
+	"bytes"
+	"os"
+	"time"
+)
+
+// secret yang dipakai di development jika secret tidak diisi, tidak boleh dipakai di luar development
+const DevelopmentSecret = "development-only-share-secret-change-me"
+
+// Config is loaded from the "sharing" section of config.json. SecretFile takes
+// precedence over Secret so the secret does not have to live in the config file.
+type Config struct {
+	Secret        string        `mapstructure:"secret"`
+	SecretFile    string        `mapstructure:"secret_file"`
+	Directory     string        `mapstructure:"directory"`
+	DefaultExpiry time.Duration `mapstructure:"default_expiry"`
+	MaxExpiry     time.Duration `mapstructure:"max_expiry"`
+}
+
+// secret untuk signer, dari file jika SecretFile diisi
+func (c Config) LoadSecret() ([]byte, error) {
+	if c.SecretFile == "" {
+		return []byte(c.Secret), nil
+	}
+	secret, err := os.ReadFile(c.SecretFile)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSpace(secret), nil
+}
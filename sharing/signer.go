@@ -0,0 +1,41 @@
+package sharing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+)
+
+var ErrWeakSecret = errors.New("sharing secret must be at least 32 bytes")
+
+// Signer signs share links with HMAC-SHA256. The signature covers the link id, the
+// expiry and, for IP-bound links, the client IP, so none of them can be changed in the URL.
+type Signer struct {
+	secret []byte
+}
+
+// function provider
+func NewSigner(secret []byte) (*Signer, error) {
+	if len(secret) < 32 {
+		return nil, ErrWeakSecret
+	}
+	return &Signer{secret: secret}, nil
+}
+
+func (s *Signer) Sign(id string, expires int64, ip string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id + "\n" + strconv.FormatInt(expires, 10) + "\n" + ip))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// bandingkan signature dengan waktu konstan
+func (s *Signer) Verify(id string, expires int64, ip string, signature string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	expected, _ := base64.RawURLEncoding.DecodeString(s.Sign(id, expires, ip))
+	return hmac.Equal(decoded, expected)
+}
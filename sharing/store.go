@@ -0,0 +1,209 @@
+package sharing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrLinkNotFound = errors.New("share link not found")
+	ErrLinkRevoked  = errors.New("share link has been revoked")
+	ErrLinkUsed     = errors.New("share link has already been used")
+)
+
+var linkIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// Link is a share link of one file. IP is only set for links bound to a client IP.
+type Link struct {
+	ID        string     `json:"id"`
+	FileID    string     `json:"file_id"`
+	Owner     string     `json:"owner"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	SingleUse bool       `json:"single_use"`
+	IP        string     `json:"ip,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	Downloads int        `json:"downloads"`
+}
+
+// Store keeps share links as files in a directory, shared by all prefork processes.
+// Next to {id}.json a download appends one byte to {id}.downloads and a single-use
+// link is claimed by creating {id}.used exclusively, so neither needs a lock.
+type Store struct {
+	directory string
+}
+
+// function provider
+func NewStore(directory string) *Store {
+	return &Store{
+		directory: directory,
+	}
+}
+
+func (s *Store) Create(link Link) (*Link, error) {
+	if err := os.MkdirAll(s.directory, 0o755); err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return nil, err
+	}
+	link.ID = hex.EncodeToString(buffer)
+	link.Downloads = 0
+	return &link, s.save(&link)
+}
+
+func (s *Store) Get(id string) (*Link, error) {
+	if !linkIdPattern.MatchString(id) {
+		return nil, ErrLinkNotFound
+	}
+
+	encoded, err := os.ReadFile(s.path(id, ".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrLinkNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var link Link
+	if err := json.Unmarshal(encoded, &link); err != nil {
+		return nil, err
+	}
+	link.Downloads, err = s.downloads(id)
+	return &link, err
+}
+
+// semua link untuk satu file, terbaru dulu
+func (s *Store) List(fileID string) ([]Link, error) {
+	files, err := os.ReadDir(s.directory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []Link{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	links := []Link{}
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), ".json")
+		if !ok {
+			continue
+		}
+		link, err := s.Get(id)
+		if errors.Is(err, ErrLinkNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if link.FileID == fileID {
+			links = append(links, *link)
+		}
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+func (s *Store) Revoke(id string) (*Link, error) {
+	link, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt == nil {
+		now := time.Now().UTC()
+		link.RevokedAt = &now
+		if err := s.save(link); err != nil {
+			return nil, err
+		}
+	}
+	return link, nil
+}
+
+// Use records one download of the link. A single-use link can be used only once,
+// also when two processes try at the same time.
+func (s *Store) Use(id string) (*Link, error) {
+	link, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if link.RevokedAt != nil {
+		return nil, ErrLinkRevoked
+	}
+
+	if link.SingleUse {
+		used, err := os.OpenFile(s.path(id, ".used"), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			return nil, ErrLinkUsed
+		}
+		if err != nil {
+			return nil, err
+		}
+		used.Close()
+	}
+
+	counter, err := os.OpenFile(s.path(id, ".downloads"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	_, err = counter.Write([]byte{1})
+	if closeErr := counter.Close(); err == nil {
+		err = closeErr
+	}
+	link.Downloads++
+	return link, err
+}
+
+// jumlah download adalah ukuran file counter
+func (s *Store) downloads(id string) (int, error) {
+	info, err := os.Stat(s.path(id, ".downloads"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int(info.Size()), nil
+}
+
+// tulis ke file sementara lalu rename supaya Get tidak membaca json setengah jadi
+func (s *Store) save(link *Link) error {
+	stored := *link
+	stored.Downloads = 0
+
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(s.directory, ".link-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(encoded)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), s.path(link.ID, ".json"))
+}
+
+func (s *Store) path(id string, extension string) string {
+	return filepath.Join(s.directory, id+extension)
+}
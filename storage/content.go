@@ -0,0 +1,484 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go_fiber/encryption"
+	"go_fiber/filelock"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ContentStorage stores every file once under the SHA-256 hash of its content, so
+// uploads with the same content share one blob on disk.
+//
+// Layout under root:
+//
+//	blobs/ab/ab12...   content, named by hash
+//	names/<name>       hard link to the blob of name
+//	hashes/<name>      hash of name, to find the blob again on remove
+//	usage              bytes used by all blobs
+//	rewrap/<hash>      new header of a blob while Rewrap replaces it
+//
+// The link count of a blob is its reference count: a blob is deleted when the last
+// name pointing at it is removed. Everything is done with links and renames so
+// several processes (prefork) can share the directory without a lock, except the
+// usage counter which is updated under usage.lock when a blob is created or deleted.
+//
+// When Cipher is set blobs are encrypted at rest and decrypted transparently on Open.
+// Blobs are still named by the hash of the plain content, so duplicates are found.
+type ContentStorage struct {
+	Cipher *encryption.Keyring
+
+	root string
+}
+
+// function provider
+func NewContentStorage(root string) *ContentStorage {
+	return &ContentStorage{
+		root: root,
+	}
+}
+
+// konten ditulis ke file sementara sambil di-hash, lalu di-link ke blob. Jika blob sudah
+// ada file sementara dibuang dan name menunjuk ke blob yang sudah ada
+func (c *ContentStorage) Save(ctx context.Context, name string, reader io.Reader) (int64, error) {
+	for _, directory := range []string{"tmp", "blobs", "names", "hashes"} {
+		if err := os.MkdirAll(filepath.Join(c.root, directory), 0o755); err != nil {
+			return 0, err
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Join(c.root, "tmp"), "upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(temp.Name())
+
+	var destination io.WriteCloser = temp
+	if c.Cipher != nil {
+		if destination, err = c.Cipher.Encrypt(temp); err != nil {
+			temp.Close()
+			return 0, err
+		}
+	}
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(destination, hash), &contextReader{ctx: ctx, reader: reader})
+	if c.Cipher != nil {
+		if closeErr := destination.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	link := temp.Name() + ".link"
+	defer os.Remove(link)
+	created, err := c.linkBlob(temp.Name(), sum, link)
+	if err != nil {
+		return written, err
+	}
+	// blob baru menambah usage dengan ukurannya di disk (setelah enkripsi)
+	if created {
+		info, err := os.Stat(c.blobPath(sum))
+		if err == nil {
+			_, err = c.updateUsage(ctx, info.Size())
+		}
+		if err != nil {
+			return written, err
+		}
+	}
+
+	previous, _ := c.hashOf(name)
+	if err := c.writeHash(name, sum); err != nil {
+		return written, err
+	}
+	if err := os.Rename(link, c.namePath(name)); err != nil {
+		return written, err
+	}
+
+	// name yang ditimpa dengan konten lain melepas blob lamanya
+	if previous != "" && previous != sum {
+		c.collect(previous)
+	}
+	return written, nil
+}
+
+// link file sementara ke blob sum lalu ke link, created true jika blob baru dibuat. Blob
+// yang sudah ada bisa dihapus proses lain di antara dua langkah itu (name terakhirnya
+// dihapus), jadi dicoba ulang
+func (c *ContentStorage) linkBlob(temp string, sum string, link string) (bool, error) {
+	blob := c.blobPath(sum)
+	if err := os.MkdirAll(filepath.Dir(blob), 0o755); err != nil {
+		return false, err
+	}
+
+	created := false
+	for attempt := 0; ; attempt++ {
+		err := os.Link(temp, blob)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return created, err
+		}
+		created = created || err == nil
+
+		err = os.Link(blob, link)
+		if err == nil || !errors.Is(err, fs.ErrNotExist) || attempt == 2 {
+			return created, err
+		}
+	}
+}
+
+func (c *ContentStorage) Open(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(c.namePath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil || c.Cipher == nil {
+		return file, err
+	}
+
+	// blob yang disimpan sebelum enkripsi diaktifkan dikirim apa adanya
+	reader, err := c.Cipher.Decrypt(file)
+	if errors.Is(err, encryption.ErrCorrupted) {
+		// header bisa sedang ditulis ulang Rewrap atau terputus karena crash, dicoba sekali lagi
+		if sum, _ := c.hashOf(name); sum != "" && c.recoverRewrap(sum) == nil {
+			reader, err = c.Cipher.Decrypt(file)
+		}
+	}
+	if errors.Is(err, encryption.ErrNotEncrypted) {
+		_, err = file.Seek(0, io.SeekStart)
+		return file, err
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &decryptedFile{ReadSeeker: reader, file: file}, nil
+}
+
+// Rewrap wraps the data key of every encrypted blob with the current master key of
+// Cipher, the content is not re-encrypted. The header is rewritten in place rather
+// than renamed so the hard links of the blob stay intact; the new header is first
+// written and synced to rewrap/<hash>, so a header torn by a crash is completed by the
+// next Rewrap or Open. It returns the number of blobs rewrapped.
+func (c *ContentStorage) Rewrap(ctx context.Context) (int, error) {
+	if c.Cipher == nil {
+		return 0, errors.New("encryption is not enabled")
+	}
+	for _, directory := range []string{"tmp", "rewrap"} {
+		if err := os.MkdirAll(filepath.Join(c.root, directory), 0o755); err != nil {
+			return 0, err
+		}
+	}
+
+	// selesaikan rotasi sebelumnya yang terputus
+	journals, err := os.ReadDir(filepath.Join(c.root, "rewrap"))
+	if err != nil {
+		return 0, err
+	}
+	for _, journal := range journals {
+		if err := c.recoverRewrap(journal.Name()); err != nil {
+			return 0, err
+		}
+	}
+
+	rewrapped := 0
+	err = filepath.WalkDir(filepath.Join(c.root, "blobs"), func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		changed, err := c.rewrapBlob(path)
+		if errors.Is(err, encryption.ErrNotEncrypted) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%v : %w", path, err)
+		}
+		if changed {
+			rewrapped++
+		}
+		return nil
+	})
+	return rewrapped, err
+}
+
+// header baru ditulis ke journal (fsync lalu rename) sebelum menimpa header blob, lalu
+// blob di-fsync dan journal dihapus
+func (c *ContentStorage) rewrapBlob(path string) (bool, error) {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	header := make([]byte, encryption.HeaderSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return false, encryption.ErrNotEncrypted
+		}
+		return false, err
+	}
+	rewrapped, err := c.Cipher.Rewrap(header)
+	if err != nil || rewrapped == nil {
+		return false, err
+	}
+
+	journal := c.journalPath(filepath.Base(path))
+	if err := writeSynced(filepath.Join(c.root, "tmp"), journal, rewrapped); err != nil {
+		return false, err
+	}
+	if err := writeHeader(file, rewrapped); err != nil {
+		return false, err
+	}
+	return true, os.Remove(journal)
+}
+
+// tulis ulang header blob sum dari journal jika ada. Header yang sama boleh ditulis dua
+// kali, jadi aman berjalan bersamaan dengan Rewrap
+func (c *ContentStorage) recoverRewrap(sum string) error {
+	journal := c.journalPath(sum)
+	header, err := os.ReadFile(journal)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(c.blobPath(sum), os.O_RDWR, 0)
+	if err == nil {
+		err = writeHeader(file, header)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	// blob sudah dihapus, journal tidak dibutuhkan lagi
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(journal); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// tulis header di awal file dan pastikan sampai ke disk
+func writeHeader(file *os.File, header []byte) error {
+	if _, err := file.WriteAt(header, 0); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+func (c *ContentStorage) Remove(ctx context.Context, name string) error {
+	sum, _ := c.hashOf(name)
+	err := os.Remove(c.namePath(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(c.hashPath(name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if sum != "" {
+		c.collect(sum)
+	}
+	return nil
+}
+
+// Usage returns the bytes used on disk, counting content shared by several names once.
+// It reads the usage counter, which is rebuilt from the blobs when it does not exist yet.
+func (c *ContentStorage) Usage(ctx context.Context) (int64, error) {
+	return c.updateUsage(ctx, 0)
+}
+
+// tambah delta ke counter usage dan kembalikan nilainya. Counter yang belum ada dihitung
+// ulang dari blobs, hasilnya sudah termasuk blob yang baru dibuat / dihapus sehingga
+// delta tidak ditambahkan lagi
+func (c *ContentStorage) updateUsage(ctx context.Context, delta int64) (int64, error) {
+	if err := os.MkdirAll(c.root, 0o755); err != nil {
+		return 0, err
+	}
+	unlock, err := filelock.Lock(filepath.Join(c.root, "usage.lock"))
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	path := filepath.Join(c.root, "usage")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		used, err := c.walkUsage(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return used, writeFile(c.root, path, strconv.FormatInt(used, 10))
+	}
+	if err != nil {
+		return 0, err
+	}
+	used, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid usage counter : %w", err)
+	}
+	if delta == 0 {
+		return used, nil
+	}
+
+	used = max(used+delta, 0)
+	return used, writeFile(c.root, path, strconv.FormatInt(used, 10))
+}
+
+// jumlah ukuran semua blob
+func (c *ContentStorage) walkUsage(ctx context.Context) (int64, error) {
+	var used int64
+	err := filepath.WalkDir(filepath.Join(c.root, "blobs"), func(path string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		used += info.Size()
+		return ctx.Err()
+	})
+	return used, err
+}
+
+// reader hasil dekripsi, Close menutup file blob
+type decryptedFile struct {
+	io.ReadSeeker
+	file *os.File
+}
+
+func (d *decryptedFile) Close() error {
+	return d.file.Close()
+}
+
+// hapus blob yang tidak lagi punya name, link yang tersisa hanya blob itu sendiri
+func (c *ContentStorage) collect(sum string) {
+	info, err := os.Stat(c.blobPath(sum))
+	if err != nil {
+		return
+	}
+	if links, ok := linkCount(info); ok && links <= 1 {
+		// hanya proses yang berhasil menghapus blob yang mengurangi usage
+		if os.Remove(c.blobPath(sum)) == nil {
+			c.updateUsage(context.Background(), -info.Size())
+		}
+	}
+}
+
+func (c *ContentStorage) hashOf(name string) (string, error) {
+	data, err := os.ReadFile(c.hashPath(name))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c *ContentStorage) writeHash(name string, sum string) error {
+	return writeFile(filepath.Join(c.root, "tmp"), c.hashPath(name), sum)
+}
+
+// tulis ke file sementara di directory lalu rename, pembaca tidak melihat isi setengah jadi
+func writeFile(directory string, path string, content string) error {
+	temp, err := os.CreateTemp(directory, ".write-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.WriteString(content)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// seperti writeFile, isi dan rename di-fsync supaya tetap ada setelah crash
+func writeSynced(directory string, path string, content []byte) error {
+	temp, err := os.CreateTemp(directory, ".write-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return err
+	}
+
+	parent, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	err = parent.Sync()
+	if closeErr := parent.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// blob dikelompokkan per dua karakter awal hash supaya satu directory tidak terlalu besar
+func (c *ContentStorage) blobPath(sum string) string {
+	return filepath.Join(c.root, "blobs", sum[:2], sum)
+}
+
+// nama file dibersihkan agar tidak bisa keluar dari root
+func (c *ContentStorage) namePath(name string) string {
+	return filepath.Join(c.root, "names", filepath.Base(filepath.Clean("/"+name)))
+}
+
+func (c *ContentStorage) journalPath(sum string) string {
+	return filepath.Join(c.root, "rewrap", filepath.Base(filepath.Clean("/"+sum)))
+}
+
+func (c *ContentStorage) hashPath(name string) string {
+	return filepath.Join(c.root, "hashes", filepath.Base(filepath.Clean("/"+name)))
+}
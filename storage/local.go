@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage stores files in a directory on disk.
+type LocalStorage struct {
+	root string
+}
+
+// function provider
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{
+		root: root,
+	}
+}
+
+// file ditulis ke file sementara lalu di-rename, upload yang gagal tidak meninggalkan file setengah jadi
+func (l *LocalStorage) Save(ctx context.Context, name string, reader io.Reader) (int64, error) {
+	if err := os.MkdirAll(l.root, 0o755); err != nil {
+		return 0, err
+	}
+
+	temp, err := os.CreateTemp(l.root, ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(temp.Name())
+
+	written, err := io.Copy(temp, &contextReader{ctx: ctx, reader: reader})
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return written, err
+	}
+
+	return written, os.Rename(temp.Name(), l.path(name))
+}
+
+func (l *LocalStorage) Open(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	file, err := os.Open(l.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+func (l *LocalStorage) Remove(ctx context.Context, name string) error {
+	err := os.Remove(l.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// nama file dibersihkan agar tidak bisa keluar dari root
+func (l *LocalStorage) path(name string) string {
+	return filepath.Join(l.root, filepath.Base(filepath.Clean("/"+name)))
+}
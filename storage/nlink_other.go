@@ -0,0 +1,10 @@
+//go:build !unix
+
+package storage
+
+import "io/fs"
+
+// jumlah link tidak tersedia, blob tidak pernah dihapus daripada menghapus blob yang masih dipakai
+func linkCount(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// Storage is where uploaded files end up. Save streams the reader to name without
+// buffering it in memory and returns the number of bytes written.
+type Storage interface {
+	Save(ctx context.Context, name string, reader io.Reader) (int64, error)
+	Open(ctx context.Context, name string) (io.ReadSeekCloser, error)
+	Remove(ctx context.Context, name string) error
+}
+
+// reader yang berhenti ketika context dibatalkan, supaya upload lambat ikut timeout route
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.reader.Read(p)
+}
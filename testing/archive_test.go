@@ -0,0 +1,204 @@
+package testing
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/archive"
+	"go_fiber/catalogue"
+	"go_fiber/handler"
+	"go_fiber/storage"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// storage yang bisa membuat file hilang atau gagal dibaca setelah archive dimulai
+type flakyStorage struct {
+	storage.Storage
+	gone   map[string]bool
+	broken map[string]bool
+}
+
+func (f *flakyStorage) Open(ctx context.Context, name string) (io.ReadSeekCloser, error) {
+	if f.gone[name] {
+		return nil, storage.ErrNotFound
+	}
+	reader, err := f.Storage.Open(ctx, name)
+	if err == nil && f.broken[name] {
+		return &brokenReader{ReadSeekCloser: reader}, nil
+	}
+	return reader, err
+}
+
+// reader yang gagal setelah 3 byte
+type brokenReader struct {
+	io.ReadSeekCloser
+	read int
+}
+
+func (b *brokenReader) Read(p []byte) (int, error) {
+	if b.read >= 3 {
+		return 0, errors.New("disk error")
+	}
+	n, err := b.ReadSeekCloser.Read(p[:min(len(p), 3-b.read)])
+	b.read += n
+	return n, err
+}
+
+func newArchiveApp(t *testing.T, maxSize int64) (*fiber.App, *flakyStorage) {
+	store := &flakyStorage{Storage: storage.NewLocalStorage(t.TempDir()), gone: map[string]bool{}, broken: map[string]bool{}}
+	fixture := newFileFixture(t, store)
+
+	// semua file milik reo
+	files := map[string][2]string{
+		"id-1": {"laporan.txt", "isi laporan pertama"},
+		"id-2": {"laporan.txt", "isi laporan kedua"},
+		"id-3": {"foto.png", "bukan png sungguhan"},
+	}
+	for id, file := range files {
+		size, err := store.Save(context.Background(), id, strings.NewReader(file[1]))
+		assert.Nil(t, err)
+		assert.Nil(t, fixture.catalogue.Save(context.Background(), catalogue.Entry{ID: id, Name: file[0], Owner: fixture.ids["reo@example.com"], Size: size, ContentType: "text/plain"}))
+	}
+
+	Routes.NewArchiveRoutes(fixture.app, handler.NewArchiveHandler(fixture.catalogue, store, archive.Config{MaxSize: maxSize}, validator.New()))
+	return fixture.app, store
+}
+
+// request archive sebagai reo
+func requestArchive(t *testing.T, app *fiber.App, body string) (*http.Response, []byte) {
+	request := loginAs(httptest.NewRequest(http.MethodPost, "/files/archive", strings.NewReader(body)), "reo@example.com")
+	request.Header.Set("Content-Type", "application/json")
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	content, _ := io.ReadAll(response.Body)
+	return response, content
+}
+
+// isi zip sebagai map nama ke isi
+func readZip(t *testing.T, content []byte) map[string]string {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.Nil(t, err)
+
+	files := map[string]string{}
+	for _, file := range reader.File {
+		opened, err := file.Open()
+		assert.Nil(t, err)
+		data, err := io.ReadAll(opened)
+		assert.Nil(t, err)
+		files[file.Name] = string(data)
+	}
+	return files
+}
+
+func TestArchiveDownload(t *testing.T) {
+	app, store := newArchiveApp(t, 1024)
+
+	// test zip dengan nama file unik & path yang dibersihkan
+	t.Run("test zip archive", func(t *testing.T) {
+		response, content := requestArchive(t, app, `{"files": [{"id": "id-1"}, {"id": "id-2"}, {"id": "id-3", "path": "../../gambar/foto.png"}]}`)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/zip", response.Header.Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="files.zip"`, response.Header.Get("Content-Disposition"))
+
+		assert.Equal(t, map[string]string{
+			"laporan.txt":     "isi laporan pertama",
+			"laporan (1).txt": "isi laporan kedua",
+			"gambar/foto.png": "bukan png sungguhan",
+		}, readZip(t, content))
+	})
+
+	// test tar.gz
+	t.Run("test tar.gz archive", func(t *testing.T) {
+		response, content := requestArchive(t, app, `{"format": "tar.gz", "files": [{"id": "id-1", "path": "a/laporan.txt"}, {"id": "id-2"}]}`)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/gzip", response.Header.Get("Content-Type"))
+
+		gzipReader, err := gzip.NewReader(bytes.NewReader(content))
+		assert.Nil(t, err)
+		reader := tar.NewReader(gzipReader)
+		files := map[string]string{}
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.Nil(t, err)
+			data, _ := io.ReadAll(reader)
+			files[header.Name] = string(data)
+		}
+		assert.Equal(t, map[string]string{"a/laporan.txt": "isi laporan pertama", "laporan.txt": "isi laporan kedua"}, files)
+	})
+
+	// test file yang hilang setelah archive dimulai dicatat di MISSING.txt
+	t.Run("test file disappears", func(t *testing.T) {
+		store.gone["id-2"] = true
+		defer delete(store.gone, "id-2")
+
+		response, content := requestArchive(t, app, `{"files": [{"id": "id-1"}, {"id": "id-2", "path": "kedua.txt"}]}`)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		files := readZip(t, content)
+		assert.Equal(t, "isi laporan pertama", files["laporan.txt"])
+		assert.NotContains(t, files, "kedua.txt")
+		assert.Contains(t, files["MISSING.txt"], "kedua.txt")
+	})
+
+	// test file gagal dibaca di tengah stream menghasilkan archive rusak, bukan file terpotong
+	t.Run("test file fails mid-stream", func(t *testing.T) {
+		store.broken["id-2"] = true
+		defer delete(store.broken, "id-2")
+
+		response, content := requestArchive(t, app, `{"files": [{"id": "id-1"}, {"id": "id-2"}]}`)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		_, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		assert.NotNil(t, err)
+	})
+
+	// test file user lain tidak bisa diarsip, kecuali oleh admin
+	t.Run("test archive other user", func(t *testing.T) {
+		body := `{"files": [{"id": "id-1"}]}`
+		for username, status := range map[string]int{"": http.StatusUnauthorized, "budi@example.com": http.StatusForbidden, "admin@example.com": http.StatusOK} {
+			request := httptest.NewRequest(http.MethodPost, "/files/archive", strings.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			if username != "" {
+				loginAs(request, username)
+			}
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, status, response.StatusCode, username)
+		}
+	})
+
+	// test request tidak valid
+	t.Run("test invalid archive request", func(t *testing.T) {
+		response, _ := requestArchive(t, app, `{"files": [{"id": "tidak-ada"}]}`)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+
+		response, _ = requestArchive(t, app, `{"format": "rar", "files": [{"id": "id-1"}]}`)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		response, _ = requestArchive(t, app, `{"files": []}`)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+func TestArchiveSizeLimit(t *testing.T) {
+	app, _ := newArchiveApp(t, 30)
+
+	response, content := requestArchive(t, app, `{"files": [{"id": "id-1"}, {"id": "id-2"}]}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	assert.Contains(t, string(content), "archive exceeds the limit of 30 bytes")
+}
@@ -0,0 +1,185 @@
+package testing
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/middleware"
+	"go_fiber/public"
+	"go_fiber/view"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const browserAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+var csrfInputPattern = regexp.MustCompile(`name="_csrf" value="([^"]+)"`)
+
+func newAuthPageApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		Views:             view.NewEngine(),
+		PassLocalsToViews: true,
+		StreamRequestBody: true,
+	})
+	app.Use(middleware.NewViewGlobalsMiddleware(view.Config{AppName: "go-fiber"}, view.NewAssetHasher(public.Files, "/public")).Handle)
+	app.Use(middleware.NewBodyLimitMiddleware(middleware.BodyLimitConfig{Default: 1024}).Handle)
+	app.Use(middleware.NewCsrfMiddleware())
+	Routes.NewTestRoutes(app, validator.New())
+	return app
+}
+
+// ambil halaman form, return token csrf dan cookie-nya
+func openForm(t *testing.T, app *fiber.App, path string) (string, []*http.Cookie) {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	request.Header.Add("Accept", browserAccept)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	match := csrfInputPattern.FindStringSubmatch(string(body))
+	assert.Len(t, match, 2)
+	return match[1], response.Cookies()
+}
+
+func submitForm(t *testing.T, app *fiber.App, path string, form url.Values, cookies []*http.Cookie) (*http.Response, string) {
+	request := httptest.NewRequest(http.MethodPost, path, strings.NewReader(form.Encode()))
+	request.Header.Add("content-type", "application/x-www-form-urlencoded")
+	request.Header.Add("Accept", browserAccept)
+	for _, cookie := range cookies {
+		request.AddCookie(cookie)
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func TestLoginPage(t *testing.T) {
+	app := newAuthPageApp()
+
+	// test form invalid dirender ulang
+	t.Run("test login form validation error", func(t *testing.T) {
+		token, cookies := openForm(t, app, "/login")
+
+		response, body := submitForm(t, app, "/login", url.Values{
+			"_csrf": {token}, "email": {"reoshby"}, "password": {"123"},
+		}, cookies)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+		assert.Contains(t, body, `value="reoshby"`)
+		assert.Contains(t, body, `<p class="field-error">must be a valid email</p>`)
+		assert.Contains(t, body, `<p class="field-error">must be at least 6 characters</p>`)
+		assert.NotContains(t, body, `value="123"`)
+	})
+
+	// test form valid redirect dengan flash
+	t.Run("test login form success", func(t *testing.T) {
+		token, cookies := openForm(t, app, "/login")
+
+		response, _ := submitForm(t, app, "/login", url.Values{
+			"_csrf": {token}, "email": {"reoshby@gmail.com"}, "password": {"123456"},
+		}, cookies)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "/v1/view", response.Header.Get("Location"))
+
+		hasFlash := false
+		for _, cookie := range response.Cookies() {
+			hasFlash = hasFlash || cookie.Name == "flash"
+		}
+		assert.True(t, hasFlash)
+	})
+
+	// test form tanpa token csrf ditolak
+	t.Run("test login form without csrf", func(t *testing.T) {
+		response, _ := submitForm(t, app, "/login", url.Values{
+			"email": {"reoshby@gmail.com"}, "password": {"123456"},
+		}, nil)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	// test body form yang terlalu besar ditolak sebelum token csrf dibaca dari body
+	t.Run("test oversized form before csrf", func(t *testing.T) {
+		response, _ := submitForm(t, app, "/login", url.Values{
+			"email": {"reoshby@gmail.com"}, "password": {strings.Repeat("a", 2048)},
+		}, nil)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+
+	// test body simple lain (multipart, text/plain, tanpa content type) juga butuh token
+	t.Run("test simple cross-site bodies without csrf", func(t *testing.T) {
+		for _, contentType := range []string{"multipart/form-data; boundary=reo", "text/plain", ""} {
+			request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader("--reo--\r\n"))
+			if contentType != "" {
+				request.Header.Add("content-type", contentType)
+			}
+
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusForbidden, response.StatusCode, contentType)
+		}
+	})
+
+	// test token lewat header untuk body multipart
+	t.Run("test multipart with csrf header", func(t *testing.T) {
+		token, cookies := openForm(t, app, "/login")
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(
+			"--reo\r\nContent-Disposition: form-data; name=\"email\"\r\n\r\nreoshby@gmail.com\r\n"+
+				"--reo\r\nContent-Disposition: form-data; name=\"password\"\r\n\r\n123456\r\n--reo--\r\n"))
+		request.Header.Add("content-type", "multipart/form-data; boundary=reo")
+		request.Header.Add(middleware.CsrfHeader, token)
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	// test client json tetap mendapat json
+	t.Run("test login json client", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"reoshby@gmail.com","password":"123456"}`))
+		request.Header.Add("content-type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	})
+}
+
+func TestRegisterPage(t *testing.T) {
+	app := newAuthPageApp()
+
+	// test form invalid dirender ulang
+	t.Run("test register form validation error", func(t *testing.T) {
+		token, cookies := openForm(t, app, "/register")
+
+		response, body := submitForm(t, app, "/register", url.Values{
+			"_csrf": {token}, "username": {"reoshby@gmail.com"}, "password": {"123456"},
+		}, cookies)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, body, `value="reoshby@gmail.com"`)
+		assert.Contains(t, body, `<p class="field-error">is required</p>`)
+	})
+
+	// test form valid redirect ke login
+	t.Run("test register form success", func(t *testing.T) {
+		token, cookies := openForm(t, app, "/register")
+
+		response, _ := submitForm(t, app, "/register", url.Values{
+			"_csrf": {token}, "username": {"reoshby@gmail.com"}, "password": {"123456"}, "name": {"Reo"},
+		}, cookies)
+		assert.Equal(t, http.StatusSeeOther, response.StatusCode)
+		assert.Equal(t, "/login", response.Header.Get("Location"))
+	})
+}
@@ -0,0 +1,96 @@
+package testing
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/binding"
+	"go_fiber/model/dto"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type orderStatus string
+
+func (o *orderStatus) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "pending", "paid":
+		*o = orderStatus(text)
+		return nil
+	}
+	return fmt.Errorf("must be one of [pending paid]")
+}
+
+type searchParams struct {
+	ShopId    uuid.UUID   `param:"shopId"`
+	Page      int         `query:"page" default:"1" validate:"gte=1"`
+	Ids       []int       `query:"id"`
+	Since     time.Time   `query:"since" layout:"2006-01-02"`
+	Status    orderStatus `query:"status"`
+	Limit     *int        `query:"limit"`
+	RequestId string      `header:"X-Request-Id" validate:"required"`
+	Session   string      `cookie:"session"`
+}
+
+func TestBindingParams(t *testing.T) {
+	app := fiber.New()
+	validate := validator.New()
+	app.Get("/shop/:shopId", func(ctx *fiber.Ctx) error {
+		params := searchParams{}
+		if err := binding.Bind(ctx, validate, &params); err != nil {
+			return ctx.Status(http.StatusBadRequest).JSON(err.(*binding.Errors).Fields)
+		}
+		return ctx.JSON(params)
+	})
+
+	// test semua tipe berhasil di-parse
+	t.Run("test bind success", func(t *testing.T) {
+		shopId := uuid.New()
+		request := httptest.NewRequest(http.MethodGet, "/shop/"+shopId.String()+"?id=1,2&id=3&since=2024-01-31&status=paid&limit=5", nil)
+		request.Header.Add("X-Request-Id", "abc")
+		request.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		params := searchParams{}
+		body, _ := io.ReadAll(response.Body)
+		assert.Nil(t, json.Unmarshal(body, &params))
+		assert.Equal(t, shopId, params.ShopId)
+		assert.Equal(t, 1, params.Page)
+		assert.Equal(t, []int{1, 2, 3}, params.Ids)
+		assert.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), params.Since)
+		assert.Equal(t, orderStatus("paid"), params.Status)
+		assert.Equal(t, 5, *params.Limit)
+		assert.Equal(t, "abc", params.RequestId)
+		assert.Equal(t, "s1", params.Session)
+	})
+
+	// test semua parameter salah dilaporkan sekaligus
+	t.Run("test bind every error", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/shop/abc?page=0&id=1,x&since=yesterday&status=done", nil)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		var fields []dto.FieldError
+		body, _ := io.ReadAll(response.Body)
+		assert.Nil(t, json.Unmarshal(body, &fields))
+		assert.ElementsMatch(t, []dto.FieldError{
+			{In: "path", Field: "shopId", Message: "must be a valid uuid"},
+			{In: "query", Field: "id", Message: "item 1 must be an integer"},
+			{In: "query", Field: "since", Message: "must be a time in format 2006-01-02"},
+			{In: "query", Field: "status", Message: "must be one of [pending paid]"},
+			{In: "query", Field: "page", Message: "must be at least 1"},
+			{In: "header", Field: "X-Request-Id", Message: "is required"},
+		}, fields)
+	})
+}
@@ -0,0 +1,321 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/catalogue"
+	"go_fiber/imaging"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newCatalogueApp(t *testing.T) (*fiber.App, *catalogue.FileStore, string) {
+	directory := t.TempDir()
+	fixture := newFileFixture(t, storage.NewLocalStorage(directory))
+	return fixture.routes(), fixture.catalogue, directory
+}
+
+// daftar file sebagai username
+func listFiles(t *testing.T, app *fiber.App, username string, query string) (int, map[string]any) {
+	response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files"+query, nil), username))
+	assert.Nil(t, err)
+	return response.StatusCode, decodeApiResponse(t, response)
+}
+
+// id item dari data page, sesuai urutan
+func pageIds(page map[string]any) []string {
+	ids := []string{}
+	for _, item := range page["data"].(map[string]any)["items"].([]any) {
+		ids = append(ids, item.(map[string]any)["id"].(string))
+	}
+	return ids
+}
+
+func TestFileCatalogue(t *testing.T) {
+	app, catalogueStore, directory := newCatalogueApp(t)
+	var uploadedId string
+
+	// test upload tercatat di catalogue dengan tag
+	t.Run("test upload recorded", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "laporan.txt")
+		assert.Nil(t, err)
+		part.Write([]byte("isi laporan"))
+		assert.Nil(t, writer.WriteField("tags", "keuangan, 2024,keuangan"))
+		assert.Nil(t, writer.Close())
+
+		// upload tanpa login ditolak sebelum body dibaca
+		request := httptest.NewRequest(http.MethodPost, "/upload-file", bytes.NewReader(body.Bytes()))
+		request.Header.Add("Content-Type", writer.FormDataContentType())
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+		request = loginAs(httptest.NewRequest(http.MethodPost, "/upload-file", body), "reo@example.com")
+		request.Header.Add("Content-Type", writer.FormDataContentType())
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		uploadedId = decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+
+		code, page := listFiles(t, app, "reo@example.com", "")
+		assert.Equal(t, http.StatusOK, code)
+		data := page["data"].(map[string]any)
+		assert.Equal(t, float64(1), data["total"])
+
+		item := data["items"].([]any)[0].(map[string]any)
+		assert.Equal(t, uploadedId, item["id"])
+		assert.Equal(t, "laporan.txt", item["name"])
+		assert.Equal(t, float64(11), item["size"])
+		assert.Len(t, item["checksum"], 64)
+		assert.Equal(t, []any{"keuangan", "2024"}, item["tags"])
+		assert.NotEmpty(t, item["created_at"])
+	})
+
+	// test download memakai nama file asli
+	t.Run("test download original name", func(t *testing.T) {
+		response := downloadFile(t, app, "reo@example.com", uploadedId)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, `attachment; filename="laporan.txt"`, response.Header.Get("Content-Disposition"))
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, "isi laporan", string(body))
+
+		// tanpa link share hanya pemilik atau admin yang bisa download
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/files/"+uploadedId, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		assert.Equal(t, http.StatusForbidden, downloadFile(t, app, "budi@example.com", uploadedId).StatusCode)
+		assert.Equal(t, http.StatusOK, downloadFile(t, app, "admin@example.com", uploadedId).StatusCode)
+
+		// file di storage tanpa entry catalogue tidak bisa didownload
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, "liar"), []byte("x"), 0644))
+		assert.Equal(t, http.StatusNotFound, downloadFile(t, app, "admin@example.com", "liar").StatusCode)
+	})
+
+	// test hapus file milik orang lain ditolak
+	t.Run("test delete other owner", func(t *testing.T) {
+		assert.Nil(t, catalogueStore.Save(context.Background(), catalogue.Entry{ID: "milik-alice", Name: "a.txt", Owner: "CN=alice"}))
+
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/milik-alice", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		assert.Nil(t, catalogueStore.Delete(context.Background(), "milik-alice"))
+
+		// file tanpa pemilik hanya bisa dihapus admin
+		assert.Nil(t, catalogueStore.Save(context.Background(), catalogue.Entry{ID: "tanpa-pemilik", Name: "b.txt"}))
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/tanpa-pemilik", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/tanpa-pemilik", nil), "admin@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	// test hapus file
+	t.Run("test delete file", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodDelete, "/files/"+uploadedId, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/"+uploadedId, nil), "budi@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		assertStoredFiles(t, directory, uploadedId, "liar")
+
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/"+uploadedId, nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "laporan.txt", decodeApiResponse(t, response)["data"].(map[string]any)["name"])
+		assert.NoFileExists(t, filepath.Join(directory, uploadedId))
+
+		code, page := listFiles(t, app, "reo@example.com", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, float64(0), page["data"].(map[string]any)["total"])
+
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/"+uploadedId, nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func TestFileCatalogueQuery(t *testing.T) {
+	app, catalogueStore, _ := newCatalogueApp(t)
+
+	day := func(date string, hour int) time.Time {
+		parsed, _ := time.Parse("2006-01-02", date)
+		return parsed.Add(time.Duration(hour) * time.Hour)
+	}
+	for _, entry := range []catalogue.Entry{
+		{ID: "a", Name: "foto.png", Owner: "alice", Size: 300, ContentType: "image/png", CreatedAt: day("2024-01-01", 10)},
+		{ID: "b", Name: "Catatan.txt", Owner: "bob", Size: 100, ContentType: "text/plain", CreatedAt: day("2024-01-02", 10)},
+		{ID: "c", Name: "avatar.jpg", Owner: "alice", Size: 200, ContentType: "image/jpeg", CreatedAt: day("2024-01-03", 23)},
+		{ID: "d", Name: "data.csv", Owner: "alice", Size: 400, ContentType: "text/csv", CreatedAt: day("2024-01-04", 10)},
+	} {
+		assert.Nil(t, catalogueStore.Save(context.Background(), entry))
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		ids   []string
+	}{
+		{"default newest first", "", []string{"d", "c", "b", "a"}},
+		{"filter owner", "?owner=alice", []string{"d", "c", "a"}},
+		{"filter top-level type", "?type=image", []string{"c", "a"}},
+		{"filter full type", "?type=image/png", []string{"a"}},
+		{"filter date range inclusive", "?from=2024-01-02&to=2024-01-03", []string{"c", "b"}},
+		{"sort size ascending", "?sort=size", []string{"b", "c", "a", "d"}},
+		{"sort name descending", "?sort=-name", []string{"a", "d", "b", "c"}},
+		{"pagination", "?sort=created_at&per_page=3&page=2", []string{"d"}},
+		{"page past the end", "?page=5", []string{}},
+	}
+	for _, test := range tests {
+		t.Run("test "+test.name, func(t *testing.T) {
+			code, page := listFiles(t, app, "admin@example.com", test.query)
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, test.ids, pageIds(page))
+		})
+	}
+
+	// test user selain admin hanya melihat file miliknya
+	t.Run("test list own files", func(t *testing.T) {
+		code, page := listFiles(t, app, "reo@example.com", "")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{}, pageIds(page))
+
+		code, _ = listFiles(t, app, "reo@example.com", "?owner=alice")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/files", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+
+	// test informasi halaman
+	t.Run("test page info", func(t *testing.T) {
+		_, page := listFiles(t, app, "admin@example.com", "?owner=alice&per_page=2")
+		data := page["data"].(map[string]any)
+		assert.Equal(t, float64(1), data["page"])
+		assert.Equal(t, float64(2), data["per_page"])
+		assert.Equal(t, float64(3), data["total"])
+		assert.Equal(t, float64(2), data["total_pages"])
+	})
+
+	// test query tidak valid
+	t.Run("test invalid query", func(t *testing.T) {
+		for _, query := range []string{"?sort=owner", "?per_page=1000", "?from=kemarin"} {
+			code, body := listFiles(t, app, "admin@example.com", query)
+			assert.Equal(t, http.StatusBadRequest, code, query)
+			assert.Equal(t, "bad request", body["status"])
+		}
+	})
+}
+
+// processor yang menolak file dengan nama tertentu
+type rejectProcessor struct {
+	name string
+}
+
+func (r rejectProcessor) Process(ctx context.Context, file *upload.File) error {
+	if file.Name == r.name {
+		return fmt.Errorf("%w : %v", upload.ErrRejected, file.Name)
+	}
+	return nil
+}
+
+// test upload yang gagal tidak meninggalkan file, variant maupun entry catalogue
+func TestUploadRollback(t *testing.T) {
+	directory := t.TempDir()
+	store := storage.NewLocalStorage(directory)
+	images := imaging.NewProcessor(imaging.Config{Variants: []imaging.Variant{{Name: "thumb", Width: 4, Height: 4, Mode: imaging.ModeFill}}}, store)
+
+	fixture := newFileFixture(t, store)
+	catalogueStore := fixture.catalogue
+	fixture.uploads.Pipeline = upload.Pipeline{images, catalogue.NewProcessor(catalogueStore), rejectProcessor{name: "tolak.txt"}}
+	app := fixture.routes()
+
+	photo := &bytes.Buffer{}
+	assert.Nil(t, png.Encode(photo, twoColorImage()))
+
+	post := func(parts map[string]string, contents map[string][]byte) *http.Response {
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		for _, field := range []string{"file", "lampiran"} {
+			if name, ok := parts[field]; ok {
+				part, err := writer.CreateFormFile(field, name)
+				assert.Nil(t, err)
+				part.Write(contents[name])
+			}
+		}
+		assert.Nil(t, writer.Close())
+
+		request := loginAs(httptest.NewRequest(http.MethodPost, "/upload-file", body), "reo@example.com")
+		request.Header.Add("Content-Type", writer.FormDataContentType())
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		return response
+	}
+	contents := map[string][]byte{"foto.png": photo.Bytes(), "tolak.txt": []byte("ditolak")}
+
+	// test file kedua ditolak, file pertama yang sudah diproses ikut dibatalkan
+	response := post(map[string]string{"file": "foto.png", "lampiran": "tolak.txt"}, contents)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assertStoredFiles(t, directory)
+	_, total, err := catalogueStore.List(context.Background(), catalogue.Query{})
+	assert.Nil(t, err)
+	assert.Equal(t, 0, total)
+
+	// test tanpa field file, part lain tidak diproses
+	response = post(map[string]string{"lampiran": "foto.png"}, contents)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	assertStoredFiles(t, directory)
+	_, total, _ = catalogueStore.List(context.Background(), catalogue.Query{})
+	assert.Equal(t, 0, total)
+
+	response = post(map[string]string{"file": "foto.png"}, contents)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	id := decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+	assertStoredFiles(t, directory, id, imaging.VariantName(id, "thumb"))
+}
+
+// test list per owner lewat index, termasuk entry dari sebelum index ada
+func TestCatalogueOwnerIndex(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "lama.json"), []byte(`{"id":"lama","name":"lama.txt","owner":"reo"}`), 0o644))
+	store := catalogue.NewFileStore(directory)
+
+	assert.Nil(t, store.Save(ctx, catalogue.Entry{ID: "baru", Name: "baru.txt", Owner: "reo", CreatedAt: time.Now()}))
+	assert.Nil(t, store.Save(ctx, catalogue.Entry{ID: "budi", Name: "budi.txt", Owner: "budi", CreatedAt: time.Now()}))
+
+	ids := func(query catalogue.Query) []string {
+		entries, total, err := store.List(ctx, query)
+		assert.Nil(t, err)
+		assert.Len(t, entries, total)
+		result := []string{}
+		for _, entry := range entries {
+			result = append(result, entry.ID)
+		}
+		return result
+	}
+	assert.ElementsMatch(t, []string{"lama", "baru"}, ids(catalogue.Query{Owner: "reo"}))
+	assert.ElementsMatch(t, []string{"budi"}, ids(catalogue.Query{Owner: "budi"}))
+	assert.ElementsMatch(t, []string{"lama", "baru", "budi"}, ids(catalogue.Query{}))
+	assert.Equal(t, []string{}, ids(catalogue.Query{Owner: "alice"}))
+
+	assert.Nil(t, store.Delete(ctx, "lama"))
+	assert.Equal(t, []string{"baru"}, ids(catalogue.Query{Owner: "reo"}))
+	assert.ErrorIs(t, store.Delete(ctx, "lama"), catalogue.ErrNotFound)
+}
@@ -0,0 +1,299 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/encryption"
+	"go_fiber/handler"
+	"go_fiber/storage"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newMasterKey(t *testing.T, id string) encryption.Key {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	assert.Nil(t, err)
+	return encryption.Key{ID: id, Secret: secret}
+}
+
+func encrypt(t *testing.T, keyring *encryption.Keyring, content []byte) []byte {
+	sealed := &bytes.Buffer{}
+	writer, err := keyring.Encrypt(sealed)
+	assert.Nil(t, err)
+	_, err = writer.Write(content)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	return sealed.Bytes()
+}
+
+// path satu-satunya blob di storage
+func blobPath(t *testing.T, root string) string {
+	var blobs []string
+	filepath.WalkDir(filepath.Join(root, "blobs"), func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			blobs = append(blobs, path)
+		}
+		return err
+	})
+	assert.Len(t, blobs, 1)
+	return blobs[0]
+}
+
+func TestEnvelopeEncryption(t *testing.T) {
+	keyring, err := encryption.NewKeyring(newMasterKey(t, "2026-10"))
+	assert.Nil(t, err)
+
+	// test isi sama setelah didekripsi, termasuk batas segment
+	t.Run("test round trip", func(t *testing.T) {
+		for _, size := range []int{0, 1, 64 * 1024, 64*1024 + 1, 200 * 1024} {
+			content := make([]byte, size)
+			rand.Read(content)
+
+			reader, err := keyring.Decrypt(bytes.NewReader(encrypt(t, keyring, content)))
+			assert.Nil(t, err)
+			decrypted, err := io.ReadAll(reader)
+			assert.Nil(t, err)
+			assert.Equal(t, content, decrypted, "size %v", size)
+		}
+	})
+
+	// test seek ke tengah segment
+	t.Run("test seek", func(t *testing.T) {
+		content := make([]byte, 200*1024)
+		rand.Read(content)
+		reader, err := keyring.Decrypt(bytes.NewReader(encrypt(t, keyring, content)))
+		assert.Nil(t, err)
+
+		size, err := reader.Seek(0, io.SeekEnd)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(content)), size)
+
+		_, err = reader.Seek(65530, io.SeekStart)
+		assert.Nil(t, err)
+		part := make([]byte, 20)
+		_, err = io.ReadFull(reader, part)
+		assert.Nil(t, err)
+		assert.Equal(t, content[65530:65550], part)
+	})
+
+	// test file yang diubah atau dipotong ditolak
+	t.Run("test tampered content", func(t *testing.T) {
+		sealed := encrypt(t, keyring, bytes.Repeat([]byte("a"), 150*1024))
+
+		tampered := bytes.Clone(sealed)
+		tampered[len(tampered)-100] ^= 1
+		reader, err := keyring.Decrypt(bytes.NewReader(tampered))
+		assert.Nil(t, err)
+		_, err = io.ReadAll(reader)
+		assert.ErrorIs(t, err, encryption.ErrCorrupted)
+
+		// dipotong tepat di batas segment, segment terakhir tidak bertanda final
+		truncated := sealed[:len(sealed)-(len(sealed)-102)%(64*1024+16)]
+		reader, err = keyring.Decrypt(bytes.NewReader(truncated))
+		assert.Nil(t, err)
+		_, err = io.ReadAll(reader)
+		assert.ErrorIs(t, err, encryption.ErrCorrupted)
+
+		_, err = keyring.Decrypt(bytes.NewReader([]byte("plain text file")))
+		assert.ErrorIs(t, err, encryption.ErrNotEncrypted)
+	})
+
+	// test ukuran segment di header diautentikasi dan dibatasi
+	t.Run("test tampered segment size", func(t *testing.T) {
+		sealed := encrypt(t, keyring, bytes.Repeat([]byte("a"), 1024))
+		for _, segmentSize := range []uint32{128 * 1024, math.MaxUint32} {
+			tampered := bytes.Clone(sealed)
+			binary.BigEndian.PutUint32(tampered[38:], segmentSize)
+			_, err := keyring.Decrypt(bytes.NewReader(tampered))
+			assert.ErrorIs(t, err, encryption.ErrCorrupted, "segment size %v", segmentSize)
+		}
+	})
+
+	// test header versi 1 (ukuran segment setelah data key) tetap dibaca dan ditulis ulang ke versi 2
+	t.Run("test legacy header", func(t *testing.T) {
+		key := newMasterKey(t, "2025-01")
+		legacyKeyring, err := encryption.NewKeyring(key)
+		assert.Nil(t, err)
+		content := bytes.Repeat([]byte("b"), 100*1024)
+		sealed := encrypt(t, legacyKeyring, content)
+
+		// ambil data key dari header versi 2 lalu susun header versi 1
+		block, err := aes.NewCipher(key.Secret)
+		assert.Nil(t, err)
+		aead, err := cipher.NewGCM(block)
+		assert.Nil(t, err)
+		wrapped := sealed[42:encryption.HeaderSize]
+		dataKey, err := aead.Open(nil, wrapped[:12], wrapped[12:], sealed[:42])
+		assert.Nil(t, err)
+
+		legacy := append([]byte{}, sealed[:38]...)
+		legacy[4] = 1
+		nonce := make([]byte, 12)
+		rand.Read(nonce)
+		legacy = append(legacy, aead.Seal(nonce, nonce, dataKey, legacy)...)
+		legacy = append(legacy, sealed[38:42]...)
+		legacy = append(legacy, sealed[encryption.HeaderSize:]...)
+
+		reader, err := legacyKeyring.Decrypt(bytes.NewReader(legacy))
+		assert.Nil(t, err)
+		decrypted, err := io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, content, decrypted)
+
+		header, err := legacyKeyring.Rewrap(legacy)
+		assert.Nil(t, err)
+		assert.Equal(t, byte(2), header[4])
+		rewrapped := append(header, legacy[encryption.HeaderSize:]...)
+		reader, err = legacyKeyring.Decrypt(bytes.NewReader(rewrapped))
+		assert.Nil(t, err)
+		decrypted, err = io.ReadAll(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, content, decrypted)
+
+		header, err = legacyKeyring.Rewrap(rewrapped)
+		assert.Nil(t, err)
+		assert.Nil(t, header)
+	})
+}
+
+func TestEncryptedStorage(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey := newMasterKey(t, "lama"), newMasterKey(t, "baru")
+	root := t.TempDir()
+	store := storage.NewContentStorage(root)
+	store.Cipher, _ = encryption.NewKeyring(oldKey)
+	content := bytes.Repeat([]byte("rahasia "), 20*1024)
+
+	// test blob di disk terenkripsi, duplikat tetap disimpan sekali
+	t.Run("test encrypted at rest", func(t *testing.T) {
+		for _, name := range []string{"pertama", "kedua"} {
+			written, err := store.Save(ctx, name, bytes.NewReader(content))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(len(content)), written)
+		}
+
+		sealed, err := os.ReadFile(blobPath(t, root))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(sealed), "rahasia")
+		assert.Equal(t, string(content), readStored(t, store, "kedua"))
+	})
+
+	// test rotasi master key hanya mengganti header
+	t.Run("test rotate master key", func(t *testing.T) {
+		before, _ := os.ReadFile(blobPath(t, root))
+
+		store.Cipher, _ = encryption.NewKeyring(newKey, oldKey)
+		rewrapped, err := store.Rewrap(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, rewrapped)
+
+		after, _ := os.ReadFile(blobPath(t, root))
+		assert.Equal(t, len(before), len(after))
+		assert.Equal(t, before[102:], after[102:])
+
+		// master key lama sudah tidak dibutuhkan
+		store.Cipher, _ = encryption.NewKeyring(newKey)
+		assert.Equal(t, string(content), readStored(t, store, "pertama"))
+		rewrapped, err = store.Rewrap(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, rewrapped)
+	})
+
+	// test header yang terpotong karena crash saat rotasi diselesaikan dari journal
+	t.Run("test interrupted rotation", func(t *testing.T) {
+		path := blobPath(t, root)
+		sealed, _ := os.ReadFile(path)
+		journal := filepath.Join(root, "rewrap", filepath.Base(path))
+		assert.Nil(t, os.WriteFile(journal, sealed[:encryption.HeaderSize], 0o644))
+
+		// magic & versi sama di header lama dan baru, yang rusak data key yang di-wrap
+		torn := bytes.Clone(sealed)
+		copy(torn[50:90], make([]byte, 40))
+		assert.Nil(t, os.WriteFile(path, torn, 0o644))
+
+		assert.Equal(t, string(content), readStored(t, store, "pertama"))
+		_, err := os.Stat(journal)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+		repaired, _ := os.ReadFile(path)
+		assert.Equal(t, sealed, repaired)
+	})
+
+	// test master key yang tidak dikenal
+	t.Run("test unknown master key", func(t *testing.T) {
+		store.Cipher, _ = encryption.NewKeyring(oldKey)
+		_, err := store.Open(ctx, "pertama")
+		assert.ErrorIs(t, err, encryption.ErrUnknownKey)
+	})
+}
+
+func TestEncryptedRangeDownload(t *testing.T) {
+	store := storage.NewContentStorage(t.TempDir())
+	store.Cipher, _ = encryption.NewKeyring(newMasterKey(t, "primary"))
+	testHandler := handler.NewTestHandler(validator.New())
+	testHandler.Storage = store
+
+	app := fiber.New()
+	loginUsers(t, app)
+	Routes.NewTestHandlerRoutes(app, testHandler)
+	Routes.NewFileRoutes(app, handler.NewFileHandler(store, nil))
+
+	content := make([]byte, 150*1024)
+	rand.Read(content)
+	response := uploadFile(t, app, "data.bin", content)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	id := decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+
+	download := func(rangeHeader string) (*http.Response, []byte) {
+		// tanpa catalogue pemilik file tidak diketahui, hanya admin yang boleh download
+		request := loginAs(httptest.NewRequest(http.MethodGet, "/files/"+id, nil), "admin@example.com")
+		if rangeHeader != "" {
+			request.Header.Set("Range", rangeHeader)
+		}
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(response.Body)
+		return response, body
+	}
+
+	// test download utuh
+	t.Run("test full download", func(t *testing.T) {
+		response, body := download("")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "bytes", response.Header.Get("Accept-Ranges"))
+		assert.Equal(t, content, body)
+	})
+
+	// test range melewati batas segment
+	t.Run("test range", func(t *testing.T) {
+		response, body := download("bytes=65530-65549")
+		assert.Equal(t, http.StatusPartialContent, response.StatusCode)
+		assert.Equal(t, "bytes 65530-65549/153600", response.Header.Get("Content-Range"))
+		assert.Equal(t, content[65530:65550], body)
+
+		response, body = download("bytes=-10")
+		assert.Equal(t, http.StatusPartialContent, response.StatusCode)
+		assert.Equal(t, content[len(content)-10:], body)
+	})
+
+	// test range di luar ukuran file
+	t.Run("test range not satisfiable", func(t *testing.T) {
+		response, _ := download("bytes=200000-")
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, response.StatusCode)
+		assert.Equal(t, "bytes */153600", response.Header.Get("Content-Range"))
+	})
+}
@@ -0,0 +1,138 @@
+package testing
+
+import (
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/view"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newErrorPageApp(errorHandler *handler.ErrorHandler, maintenance middleware.MaintenanceConfig) *fiber.App {
+	app := fiber.New(fiber.Config{
+		Views:             view.NewEngine(),
+		PassLocalsToViews: true,
+		ErrorHandler:      errorHandler.ErrorHandler,
+	})
+	app.Use(requestid.New(requestid.Config{ContextKey: view.RequestIdKey}))
+	app.Use(middleware.NewMaintenanceMiddleware(maintenance).Handle)
+	app.Get("/forbidden", func(ctx *fiber.Ctx) error {
+		return fiber.ErrForbidden
+	})
+	app.Get("/broken", func(ctx *fiber.Ctx) error {
+		return fiber.NewError(http.StatusInternalServerError, "database password leaked")
+	})
+	Routes.NewTestRoutes(app, validator.New())
+	return app
+}
+
+func requestErrorPage(t *testing.T, app *fiber.App, path string, accept string) (*http.Response, string) {
+	request := httptest.NewRequest(http.MethodGet, path, nil)
+	if accept != "" {
+		request.Header.Add("Accept", accept)
+	}
+	request.Header.Add("Authorization", "Bearer secret-token")
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(response.Body)
+	return response, string(body)
+}
+
+func TestErrorPages(t *testing.T) {
+	app := newErrorPageApp(handler.NewErrorHandler(), middleware.MaintenanceConfig{})
+
+	// test browser mendapat halaman 404
+	t.Run("test error page not found", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/tidak-ada", browserAccept)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		assert.Contains(t, response.Header.Get("Content-Type"), "text/html")
+		assert.Contains(t, body, "Page not found")
+		assert.Contains(t, body, response.Header.Get(fiber.HeaderXRequestID))
+	})
+
+	// test browser mendapat halaman 403
+	t.Run("test error page forbidden", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/forbidden", browserAccept)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		assert.Contains(t, body, "Access denied")
+	})
+
+	// test pesan error internal tidak ditampilkan di production
+	t.Run("test error page internal server error", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/broken", browserAccept)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+		assert.Contains(t, body, "Internal Server Error")
+		assert.NotContains(t, body, "database password leaked")
+		assert.NotContains(t, body, "Stack trace")
+	})
+
+	// test client api tetap mendapat json
+	t.Run("test error json client", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/tidak-ada", fiber.MIMEApplicationJSON)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		assert.Equal(t, fiber.MIMEApplicationJSON, response.Header.Get("Content-Type"))
+
+		responseBody := map[string]any{}
+		assert.Nil(t, json.Unmarshal([]byte(body), &responseBody))
+		assert.Equal(t, "not found", responseBody["status"])
+		assert.Contains(t, responseBody["message"].(string), "Cannot GET")
+
+		// pesan error internal juga disembunyikan dari client api
+		response, body = requestErrorPage(t, app, "/broken", fiber.MIMEApplicationJSON)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+		assert.NotContains(t, body, "database password leaked")
+	})
+}
+
+func TestDevErrorPage(t *testing.T) {
+	app := newErrorPageApp(handler.NewDevErrorHandler(), middleware.MaintenanceConfig{})
+
+	// test halaman error dev menampilkan detail request & stack trace
+	t.Run("test dev error page", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/broken?debug=1", browserAccept)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+		assert.Contains(t, body, "database password leaked")
+		assert.Contains(t, body, "/broken?debug=1")
+		assert.Contains(t, body, "Stack trace")
+		assert.Contains(t, body, response.Header.Get(fiber.HeaderXRequestID))
+		assert.NotContains(t, body, "secret-token")
+	})
+}
+
+func TestMaintenancePage(t *testing.T) {
+	app := newErrorPageApp(handler.NewErrorHandler(), middleware.MaintenanceConfig{
+		Enabled:    true,
+		RetryAfter: 120,
+		AllowPaths: []string{"/public"},
+	})
+
+	// test semua halaman diganti halaman maintenance
+	t.Run("test maintenance page", func(t *testing.T) {
+		response, body := requestErrorPage(t, app, "/v1/view", browserAccept)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		assert.Equal(t, "120", response.Header.Get(fiber.HeaderRetryAfter))
+		assert.Contains(t, body, "Under maintenance")
+	})
+
+	// test client api mendapat 503 json
+	t.Run("test maintenance json client", func(t *testing.T) {
+		response, _ := requestErrorPage(t, app, "/v1/view", fiber.MIMEApplicationJSON)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		assert.Equal(t, fiber.MIMEApplicationJSON, response.Header.Get("Content-Type"))
+	})
+
+	// test path yang diizinkan tetap dilayani
+	t.Run("test maintenance allow path", func(t *testing.T) {
+		response, _ := requestErrorPage(t, app, "/public/contoh.txt", browserAccept)
+		assert.NotEqual(t, http.StatusServiceUnavailable, response.StatusCode)
+	})
+}
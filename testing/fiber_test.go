@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/template/mustache/v2"
 	"github.com/stretchr/testify/assert"
 	"go_fiber/Routes"
 	handler "go_fiber/handler"
 	"go_fiber/model/dto"
+	"go_fiber/orders"
+	"go_fiber/public"
+	"go_fiber/view"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -170,26 +172,44 @@ func TestGetHttpRequest(t *testing.T) {
 // test with URL parameter
 func TestGetValueURLParams(t *testing.T) {
 	app := fiber.New()
-	validate := validator.New()
-	Routes.NewTestRoutes(app, validate)
+	ids := registerOrderRoutes(t, app, orders.NewMemoryRepository())
+	userId := ids["reo@example.com"]
+	userRequest(t, app, http.MethodPost, "/user/"+userId+"/order", `{"items":[{"product":"kopi","quantity":1,"price":15000}]}`, "reo@example.com", "rahasia")
 
 	t.Run("test with url parameter", func(t *testing.T) {
-		// create request
-		request := httptest.NewRequest(http.MethodGet, "/user/1/order/2", nil)
-
 		// hit and receive response
-		response, err := app.Test(request)
-		assert.Nil(t, err)
-		assert.NotNil(t, response)
-		assert.Equal(t, http.StatusOK, response.StatusCode)
+		code, bodyJson := userRequest(t, app, http.MethodGet, "/user/"+userId+"/order/1", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
 
-		// get response body
-		body, _ := io.ReadAll(response.Body)
-		bodyJson := map[string]any{}
-		json.Unmarshal(body, &bodyJson)
+		data := bodyJson["data"].(map[string]any)
+		assert.Equal(t, userId, data["user_id"])
+		assert.Equal(t, 1, int(data["id"].(float64)))
+	})
 
-		assert.Equal(t, 1, int(bodyJson["user"].(float64)))
-		assert.Equal(t, 2, int(bodyJson["order"].(float64)))
+	// test url parameter bukan angka -> bad request
+	t.Run("test with invalid url parameter", func(t *testing.T) {
+		for target, expected := range map[string][]dto.FieldError{
+			"/user/abc/order/0":    {{In: "path", Field: "orderId", Message: "must be greater than 0"}},
+			"/user/abc/order/satu": {{In: "path", Field: "orderId", Message: "must be an integer"}},
+		} {
+			// create request
+			request := httptest.NewRequest(http.MethodGet, target, nil)
+
+			// hit and receive response
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.NotNil(t, response)
+			assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+			// get response body
+			body, _ := io.ReadAll(response.Body)
+			responseBody := struct {
+				Data []dto.FieldError `json:"data"`
+			}{}
+			json.Unmarshal(body, &responseBody)
+
+			assert.Equal(t, expected, responseBody.Data)
+		}
 	})
 }
 
@@ -547,6 +567,7 @@ func TestRoutingGroup(t *testing.T) {
 func TestEndpointStatic(t *testing.T) {
 	app := fiber.New()
 	validate := validator.New()
+	Routes.NewStaticRoutes(app, handler.StaticMount{Prefix: "/public", Files: public.Files})
 	Routes.NewTestRoutes(app, validate)
 
 	// test access static file
@@ -590,8 +611,8 @@ func TestDefaultErrorHandler(t *testing.T) {
 	validate := validator.New()
 	Routes.NewTestRoutes(app, validate)
 
-	// test internal server error
-	t.Run("test error handler internal server error", func(t *testing.T) {
+	// test route tidak ada -> status dari fiber.Error
+	t.Run("test error handler not found", func(t *testing.T) {
 		// create request
 		request := httptest.NewRequest(http.MethodGet, "/v1/wasd", nil)
 
@@ -599,21 +620,21 @@ func TestDefaultErrorHandler(t *testing.T) {
 		respoonse, err := app.Test(request)
 		assert.Nil(t, err)
 		assert.NotNil(t, respoonse)
-		assert.Equal(t, http.StatusInternalServerError, respoonse.StatusCode)
+		assert.Equal(t, http.StatusNotFound, respoonse.StatusCode)
 
 		// get response body
 		body, _ := io.ReadAll(respoonse.Body)
 		responseBody := map[string]any{}
 		json.Unmarshal(body, &responseBody)
 
-		assert.Equal(t, http.StatusInternalServerError, int(responseBody["status_code"].(float64)))
+		assert.Equal(t, http.StatusNotFound, int(responseBody["status_code"].(float64)))
 		assert.Contains(t, responseBody["message"].(string), "Cannot")
 	})
 }
 
 // test render template mustache
 func TestRenderTemplateView(t *testing.T) {
-	engine := mustache.New("C:/Users/HP/Documents/go/src/go_fiber/view", ".mustache")
+	engine := view.NewEngine()
 	app := fiber.New(fiber.Config{Prefork: true, Views: engine})
 	validate := validator.New()
 	Routes.NewTestRoutes(app, validate)
@@ -0,0 +1,158 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/catalogue"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"go_fiber/users"
+	"golang.org/x/crypto/bcrypt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setup yang dipakai bersama oleh test di package ini
+
+// bcrypt cost minimum supaya test cepat
+var testUserConfig = users.Config{BcryptCost: bcrypt.MinCost}
+
+// user reo, budi & admin (password "rahasia") dengan middleware login di app, id user per username
+func loginUsers(t *testing.T, app *fiber.App) (users.Store, map[string]string) {
+	store := users.NewFileStore(t.TempDir())
+
+	ids := map[string]string{}
+	for _, username := range []string{"reo@example.com", "budi@example.com", "admin@example.com"} {
+		user, err := testUserConfig.NewUser(username, "rahasia", username)
+		assert.Nil(t, err)
+		user, err = store.Create(context.Background(), user)
+		assert.Nil(t, err)
+		ids[username] = user.ID
+	}
+	_, err := users.PromoteAdmin(context.Background(), store, "admin@example.com")
+	assert.Nil(t, err)
+
+	app.Use(middleware.NewUserAuthMiddleware(store, testUserConfig).Handle)
+	return store, ids
+}
+
+// login http basic sebagai username dengan password "rahasia"
+func loginAs(request *http.Request, username string) *http.Request {
+	request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":rahasia")))
+	return request
+}
+
+// request dengan login http basic, username kosong tanpa login
+func userRequest(t *testing.T, app *fiber.App, method string, target string, body string, username string, password string) (int, map[string]any) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+	}
+	if username != "" {
+		request.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	return response.StatusCode, decodeApiResponse(t, response)
+}
+
+// app upload & download file dengan user dari loginUsers: handler upload (TestHandler)
+// menyimpan ke storage lalu mencatat ke catalogue, handler file melayani download.
+// factory test mengubah pipeline & handler sebelum memanggil routes
+type fileFixture struct {
+	app       *fiber.App
+	catalogue *catalogue.FileStore
+	uploads   *handler.TestHandler
+	files     *handler.FileHandler
+	ids       map[string]string
+}
+
+func newFileFixture(t *testing.T, store storage.Storage) *fileFixture {
+	catalogueStore := catalogue.NewFileStore(t.TempDir())
+
+	uploads := handler.NewTestHandler(validator.New())
+	uploads.Storage = store
+	uploads.Pipeline = upload.Pipeline{catalogue.NewProcessor(catalogueStore)}
+
+	files := handler.NewFileHandler(store, nil)
+	files.Catalogue = catalogueStore
+
+	app := fiber.New()
+	_, ids := loginUsers(t, app)
+	return &fileFixture{
+		app:       app,
+		catalogue: catalogueStore,
+		uploads:   uploads,
+		files:     files,
+		ids:       ids,
+	}
+}
+
+// daftarkan route upload & file setelah handler selesai diubah
+func (f *fileFixture) routes() *fiber.App {
+	Routes.NewTestHandlerRoutes(f.app, f.uploads)
+	Routes.NewFileRoutes(f.app, f.files)
+	return f.app
+}
+
+// body multipart dengan satu file
+func multipartBody(t *testing.T, field string, name string, content []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	assert.Nil(t, writer.WriteField("description", "contoh upload"))
+	part, err := writer.CreateFormFile(field, name)
+	assert.Nil(t, err)
+	part.Write(content)
+	assert.Nil(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func decodeApiResponse(t *testing.T, response *http.Response) map[string]any {
+	body, _ := io.ReadAll(response.Body)
+	responseBody := map[string]any{}
+	assert.Nil(t, json.Unmarshal(body, &responseBody))
+	return responseBody
+}
+
+// isi directory storage harus persis names
+func assertStoredFiles(t *testing.T, directory string, names ...string) {
+	entries, err := os.ReadDir(directory)
+	assert.Nil(t, err)
+
+	stored := []string{}
+	for _, entry := range entries {
+		stored = append(stored, entry.Name())
+	}
+	assert.ElementsMatch(t, names, stored)
+}
+
+func uploadFile(t *testing.T, app *fiber.App, name string, content []byte) *http.Response {
+	body, contentType := multipartBody(t, "file", name, content)
+	request := loginAs(httptest.NewRequest(http.MethodPost, "/upload-file", body), "reo@example.com")
+	request.Header.Add("Content-Type", contentType)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	return response
+}
+
+// download file sebagai username
+func downloadFile(t *testing.T, app *fiber.App, username string, name string) *http.Response {
+	response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/"+name, nil), username))
+	assert.Nil(t, err)
+	return response
+}
@@ -0,0 +1,224 @@
+package testing
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/catalogue"
+	"go_fiber/imaging"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// gambar 40x20, setengah kiri merah dan setengah kanan biru
+func twoColorImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			if x < 20 {
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+// segment jpeg dengan marker dan isi
+func jpegSegment(marker byte, content []byte) []byte {
+	segment := []byte{0xFF, marker}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(content)+2))
+	return append(segment, content...)
+}
+
+// jpeg dengan exif orientation 6 (diputar 90 derajat), xmp dan komentar berisi data pribadi
+func jpegWithExif(t *testing.T) []byte {
+	encoded := &bytes.Buffer{}
+	assert.Nil(t, jpeg.Encode(encoded, twoColorImage(), &jpeg.Options{Quality: 95}))
+
+	exif := []byte("Exif\x00\x00II*\x00\x08\x00\x00\x00\x02\x00" +
+		"\x12\x01\x03\x00\x01\x00\x00\x00\x06\x00\x00\x00" + // orientation 6
+		"\x0f\x01\x02\x00\x0b\x00\x00\x00\x26\x00\x00\x00" + // make, menunjuk ke string di bawah
+		"\x00\x00\x00\x00SECRET-CAM\x00")
+
+	data := []byte{0xFF, 0xD8}
+	data = append(data, jpegSegment(0xE1, exif)...)
+	data = append(data, jpegSegment(0xE1, []byte("http://ns.adobe.com/xap/1.0/\x00SECRET-GPS"))...)
+	data = append(data, jpegSegment(0xFE, []byte("SECRET-COMMENT"))...)
+	return append(data, encoded.Bytes()[2:]...)
+}
+
+func newImagingApp(t *testing.T, config imaging.Config) (*fiber.App, string) {
+	directory := t.TempDir()
+	store := storage.NewLocalStorage(directory)
+	processor := imaging.NewProcessor(config, store)
+
+	fixture := newFileFixture(t, store)
+	fixture.uploads.Pipeline = upload.Pipeline{processor, catalogue.NewProcessor(fixture.catalogue)}
+	fixture.files.Images = processor
+	return fixture.routes(), directory
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xC000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xC000 && r < 0x4000 && g < 0x4000
+}
+
+func TestImageVariants(t *testing.T) {
+	app, directory := newImagingApp(t, imaging.Config{
+		Variants: []imaging.Variant{
+			{Name: "thumb", Width: 10, Height: 10, Mode: imaging.ModeFill},
+			{Name: "medium", Width: 16, Height: 16, Mode: imaging.ModeFit},
+		},
+		StripMetadata: true,
+	})
+	var photoId string
+
+	// test upload gambar membuat variant
+	t.Run("test upload image variants", func(t *testing.T) {
+		response := uploadFile(t, app, "foto.jpg", jpegWithExif(t))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		data := decodeApiResponse(t, response)["data"].(map[string]any)
+		assert.Equal(t, "image/jpeg", data["content_type"])
+		assert.Equal(t, []any{"thumb", "medium"}, data["variants"])
+
+		// metadata pribadi dihapus dari file asli, orientation tetap ada
+		photoId = data["id"].(string)
+		original, err := os.ReadFile(filepath.Join(directory, photoId))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(original), "SECRET")
+		assert.Equal(t, 6, imaging.Orientation(original))
+		assert.Equal(t, float64(len(original)), data["size"])
+		checksum := sha256.Sum256(original)
+		assert.Equal(t, hex.EncodeToString(checksum[:]), data["checksum"])
+		_, err = jpeg.Decode(bytes.NewReader(original))
+		assert.Nil(t, err)
+	})
+
+	// test variant sudah diputar sesuai exif orientation
+	t.Run("test get image variant", func(t *testing.T) {
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/"+photoId+"/variants/medium", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "image/jpeg", response.Header.Get("Content-Type"))
+		assert.Equal(t, "private, max-age=86400", response.Header.Get("Cache-Control"))
+
+		body, _ := io.ReadAll(response.Body)
+		assert.NotContains(t, string(body), "Exif")
+		variant, err := jpeg.Decode(bytes.NewReader(body))
+		assert.Nil(t, err)
+
+		// 40x20 diputar menjadi 20x40, lalu dimuat ke box 16x16
+		assert.Equal(t, image.Rect(0, 0, 8, 16), variant.Bounds())
+		assert.True(t, isRed(variant.At(4, 2)), "top should be the red half")
+		assert.True(t, isBlue(variant.At(4, 13)), "bottom should be the blue half")
+
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/"+photoId+"/variants/thumb", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		body, _ = io.ReadAll(response.Body)
+		thumb, err := jpeg.Decode(bytes.NewReader(body))
+		assert.Nil(t, err)
+		assert.Equal(t, image.Rect(0, 0, 10, 10), thumb.Bounds())
+	})
+
+	// test variant hanya untuk pemilik file atau admin
+	t.Run("test image variant owner", func(t *testing.T) {
+		path := "/files/" + photoId + "/variants/thumb"
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodGet, path, nil), "budi@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodGet, path, nil), "admin@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		// file tanpa entry catalogue tidak punya variant
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/liar/variants/thumb", nil), "admin@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	// test variant yang tidak dikonfigurasi
+	t.Run("test unknown variant", func(t *testing.T) {
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/"+photoId+"/variants/large", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	// test file bukan gambar tidak mendapat variant
+	t.Run("test upload non image", func(t *testing.T) {
+		response := uploadFile(t, app, "catatan.jpg", []byte("bukan gambar"))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		data := decodeApiResponse(t, response)["data"].(map[string]any)
+		assert.Nil(t, data["variants"])
+		assert.NoFileExists(t, filepath.Join(directory, imaging.VariantName(data["id"].(string), "thumb")))
+	})
+
+	// test metadata png dihapus
+	t.Run("test upload png strips text chunks", func(t *testing.T) {
+		encoded := &bytes.Buffer{}
+		assert.Nil(t, png.Encode(encoded, twoColorImage()))
+		raw := encoded.Bytes()
+
+		// sisipkan chunk tEXt setelah IHDR (8 byte header + 25 byte IHDR)
+		text := []byte("\x00\x00\x00\x0dtEXtAuthor\x00SECRET\x00\x00\x00\x00")
+		withText := append(append(append([]byte{}, raw[:33]...), text...), raw[33:]...)
+
+		response := uploadFile(t, app, "gambar.png", withText)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		id := decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+		original, err := os.ReadFile(filepath.Join(directory, id))
+		assert.Nil(t, err)
+		assert.NotContains(t, string(original), "SECRET")
+		assert.Equal(t, raw, original)
+	})
+}
+
+func TestImageTooLarge(t *testing.T) {
+	app, directory := newImagingApp(t, imaging.Config{
+		Variants:  []imaging.Variant{{Name: "thumb", Width: 10, Height: 10}},
+		MaxPixels: 100,
+	})
+
+	response := uploadFile(t, app, "besar.jpg", jpegWithExif(t))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assertStoredFiles(t, directory)
+}
+
+// test gambar di atas max bytes ditolak sebelum dibaca ke memory
+func TestImageTooManyBytes(t *testing.T) {
+	app, directory := newImagingApp(t, imaging.Config{
+		Variants: []imaging.Variant{{Name: "thumb", Width: 10, Height: 10}},
+		MaxBytes: 100,
+	})
+
+	response := uploadFile(t, app, "besar.jpg", jpegWithExif(t))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+	assertStoredFiles(t, directory)
+}
@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/middleware"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// test cors middleware
+func TestCorsMiddleware(t *testing.T) {
+	app := fiber.New()
+	cors := middleware.NewCorsMiddleware(middleware.CorsConfig{
+		AllowOrigins:     []string{"http://localhost:5173", "https://*.example.com"},
+		AllowHeaders:     []string{"Content-Type"},
+		ExposeHeaders:    []string{"X-Request-Id"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+	app.Use(cors.Handle)
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("ok")
+	})
+
+	// test allowed origin
+	t.Run("test allowed origin", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Add("Origin", "http://localhost:5173")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "http://localhost:5173", response.Header.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", response.Header.Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "X-Request-Id", response.Header.Get("Access-Control-Expose-Headers"))
+	})
+
+	// test wildcard subdomain
+	t.Run("test wildcard subdomain", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Add("Origin", "https://app.example.com")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://app.example.com", response.Header.Get("Access-Control-Allow-Origin"))
+
+		// root domain dan scheme lain tidak cocok dengan wildcard
+		for _, origin := range []string{"https://example.com", "http://app.example.com", "https://evilexample.com"} {
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Add("Origin", origin)
+
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.Empty(t, response.Header.Get("Access-Control-Allow-Origin"), origin)
+		}
+	})
+
+	// test preflight
+	t.Run("test preflight request", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodOptions, "/", nil)
+		request.Header.Add("Origin", "https://app.example.com")
+		request.Header.Add("Access-Control-Request-Method", http.MethodPost)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Contains(t, response.Header.Get("Access-Control-Allow-Methods"), http.MethodPost)
+		assert.Equal(t, "Content-Type", response.Header.Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "600", response.Header.Get("Access-Control-Max-Age"))
+	})
+}
+
+// test security headers middleware
+func TestSecurityHeadersMiddleware(t *testing.T) {
+	app := fiber.New()
+	security := middleware.NewSecurityHeadersMiddleware(middleware.SecurityHeadersConfig{
+		HstsMaxAge:            31536000,
+		HstsIncludeSubdomains: true,
+		ContentSecurityPolicy: "script-src 'self' {nonce}",
+	})
+	app.Use(security.Handle)
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return ctx.SendString(middleware.GetCspNonce(ctx))
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "nosniff", response.Header.Get("X-Content-Type-Options"))
+	assert.Equal(t, "strict-origin-when-cross-origin", response.Header.Get("Referrer-Policy"))
+	assert.Equal(t, "max-age=31536000; includeSubDomains", response.Header.Get("Strict-Transport-Security"))
+
+	// nonce pada header sama dengan nonce yang diterima handler
+	body := readBody(response)
+	assert.NotEmpty(t, body)
+	assert.True(t, strings.Contains(response.Header.Get("Content-Security-Policy"), "'nonce-"+body+"'"))
+}
+
+func readBody(response *http.Response) string {
+	body := new(strings.Builder)
+	_, _ = io.Copy(body, response.Body)
+	return body.String()
+}
@@ -0,0 +1,202 @@
+package testing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/archive"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/model/dto"
+	"go_fiber/openapi"
+	"go_fiber/orders"
+	"go_fiber/public"
+	"go_fiber/sharing"
+	"go_fiber/upload"
+	"go_fiber/users"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// jalankan `go test ./testing -run TestOpenApiSpecDrift -update` untuk memperbarui docs/openapi.json
+var updateSpec = flag.Bool("update", false, "update docs/openapi.json")
+
+const openApiSpecFile = "../docs/openapi.json"
+
+func newOpenApiApp() *fiber.App {
+	app := fiber.New()
+	validate := validator.New()
+	Routes.NewTestRoutes(app, validate)
+	Routes.NewTusRoutes(app, handler.NewTusHandler(upload.NewTusStore(upload.TusConfig{}, nil)))
+	Routes.NewFileRoutes(app, handler.NewFileHandler(nil, nil))
+	Routes.NewArchiveRoutes(app, handler.NewArchiveHandler(nil, nil, archive.Config{}, validate))
+	Routes.NewShareRoutes(app, handler.NewShareHandler(nil, nil, nil, sharing.Config{}, validate), handler.NewFileHandler(nil, nil), middleware.NewSignedLinkMiddleware(nil, nil))
+	Routes.NewQuotaRoutes(app, handler.NewQuotaHandler(nil))
+	Routes.NewUserRoutes(app, handler.NewUserHandler(nil, users.Config{}, validate))
+	Routes.NewOrderRoutes(app, handler.NewOrderHandler(nil, nil, validate))
+	Routes.NewOpenApiRoutes(app, Routes.NewOpenApiSpec(app, openapi.Info{Title: "go-fiber", Version: "1.0.0"}))
+	return app
+}
+
+// test spec yang di-generate sama dengan docs/openapi.json
+func TestOpenApiSpecDrift(t *testing.T) {
+	app := newOpenApiApp()
+
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	spec, _ := io.ReadAll(response.Body)
+	if *updateSpec {
+		assert.Nil(t, os.WriteFile(openApiSpecFile, append(spec, '\n'), 0644))
+	}
+
+	expected, err := os.ReadFile(openApiSpecFile)
+	assert.Nil(t, err)
+	assert.JSONEq(t, string(expected), string(spec), "openapi spec drifted from code, rerun with -update")
+}
+
+// test schema dari tag validate
+func TestOpenApiSchema(t *testing.T) {
+	app := newOpenApiApp()
+
+	request := httptest.NewRequest(http.MethodGet, "/openapi.json", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	document := openapi.Document{}
+	body, _ := io.ReadAll(response.Body)
+	assert.Nil(t, json.Unmarshal(body, &document))
+
+	login := document.Components.Schemas["LoginRequest"]
+	assert.NotNil(t, login)
+	assert.ElementsMatch(t, []string{"email", "password"}, login.Required)
+	assert.Equal(t, "email", login.Properties["email"].Format)
+	assert.Equal(t, 6, *login.Properties["password"].MinLength)
+
+	// path parameter fiber diubah ke syntax openapi
+	order := document.Paths["/user/{userId}/order/{orderId}"]
+	assert.NotNil(t, order)
+	assert.Equal(t, "string", order.Get.Parameters[0].Schema.Type)
+	assert.Equal(t, "integer", order.Get.Parameters[1].Schema.Type)
+	assert.Equal(t, float64(0), *order.Get.Parameters[1].Schema.ExclusiveMinimum)
+
+	// route docs tidak masuk ke spec
+	assert.NotContains(t, document.Paths, "/openapi.json")
+	assert.NotContains(t, document.Paths, "/docs")
+}
+
+// test halaman docs
+func TestOpenApiDocs(t *testing.T) {
+	app := newOpenApiApp()
+
+	request := httptest.NewRequest(http.MethodGet, "/docs", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Contains(t, string(body), `spec-url="/openapi.json"`)
+
+	// redoc dilayani dari /public, CSP tidak mengizinkan host lain
+	assert.Contains(t, string(body), `src="/public/vendor/redoc/redoc.standalone.js"`)
+	assert.NotContains(t, response.Header.Get(fiber.HeaderContentSecurityPolicy), "https:")
+}
+
+// test bundle redoc untuk /docs ter-embed, dilayani dari /public dan sama dengan checksum yang di-pin
+func TestRedocBundle(t *testing.T) {
+	bundle, err := public.Files.ReadFile("vendor/redoc/redoc.standalone.js")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("redoc bundle is not generated, run go generate ./public")
+	}
+	assert.Nil(t, err)
+
+	pinned, err := public.Files.ReadFile("vendor/redoc/redoc.standalone.js.sha256")
+	assert.Nil(t, err)
+	sum := sha256.Sum256(bundle)
+	assert.Equal(t, strings.Fields(string(pinned))[0], hex.EncodeToString(sum[:]))
+
+	app := fiber.New()
+	Routes.NewStaticRoutes(app, handler.StaticMount{Prefix: "/public", Files: public.Files})
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, "/public/vendor/redoc/redoc.standalone.js", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Header.Get(fiber.HeaderContentType), "javascript")
+}
+
+// test validasi request terhadap spec
+func TestOpenApiValidatorMiddleware(t *testing.T) {
+	app := fiber.New()
+	spec := Routes.NewOpenApiSpec(app, openapi.Info{Title: "go-fiber", Version: "1.0.0"})
+	app.Use(middleware.NewOpenApiValidatorMiddleware(spec, middleware.OpenApiValidatorConfig{
+		Enabled:           true,
+		ValidateResponses: true,
+	}).Handle)
+	Routes.NewTestRoutes(app, validator.New())
+	ids := registerOrderRoutes(t, app, orders.NewMemoryRepository())
+	Routes.NewOpenApiRoutes(app, spec)
+
+	// test path parameter bukan angka
+	t.Run("test invalid path parameter", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/user/abc/order/0", nil)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		responseBody := struct {
+			Data []dto.FieldError `json:"data"`
+		}{}
+		body, _ := io.ReadAll(response.Body)
+		assert.Nil(t, json.Unmarshal(body, &responseBody))
+		assert.ElementsMatch(t, []dto.FieldError{
+			{In: "path", Field: "orderId", Message: "must be greater than 0"},
+		}, responseBody.Data)
+	})
+
+	// test path parameter valid
+	t.Run("test valid path parameter", func(t *testing.T) {
+		path := "/user/" + ids["reo@example.com"] + "/order"
+		code, _ := userRequest(t, app, http.MethodPost, path, `{"items":[{"product":"kopi","quantity":1,"price":15000}]}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusCreated, code)
+
+		code, _ = userRequest(t, app, http.MethodGet, path+"/1", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+	})
+
+	// test request body json tidak sesuai schema
+	t.Run("test invalid json body", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"email":"reo","password":"123"}`))
+		request.Header.Add("content-type", "application/json")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Contains(t, string(body), `"field":"email"`)
+		assert.Contains(t, string(body), `"message":"must be at least 6 characters"`)
+	})
+
+	// test request body form valid
+	t.Run("test valid form body", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("username=reoshby@gmail.com&password=123456&name=Reo"))
+		request.Header.Add("content-type", "application/x-www-form-urlencoded")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+}
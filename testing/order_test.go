@@ -0,0 +1,220 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/handler"
+	"go_fiber/orders"
+	"io"
+	"math"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// daftarkan route order dengan user dari loginUsers, id user per username
+func registerOrderRoutes(t *testing.T, app *fiber.App, repository orders.Repository) map[string]string {
+	store, ids := loginUsers(t, app)
+	Routes.NewOrderRoutes(app, handler.NewOrderHandler(repository, store, validator.New()))
+	return ids
+}
+
+func orderPath(userId string, orderId ...int) string {
+	path := "/user/" + userId + "/order"
+	for _, id := range orderId {
+		path += "/" + strconv.Itoa(id)
+	}
+	return path
+}
+
+func orderIds(page map[string]any) []int {
+	ids := []int{}
+	for _, item := range page["data"].(map[string]any)["items"].([]any) {
+		ids = append(ids, int(item.(map[string]any)["id"].(float64)))
+	}
+	return ids
+}
+
+// test state machine status order
+func TestOrderStatusTransition(t *testing.T) {
+	assert.True(t, orders.StatusPending.CanTransition(orders.StatusPaid))
+	assert.True(t, orders.StatusPaid.CanTransition(orders.StatusCancelled))
+	assert.True(t, orders.StatusShipped.CanTransition(orders.StatusDelivered))
+	assert.False(t, orders.StatusPending.CanTransition(orders.StatusShipped))
+	assert.False(t, orders.StatusShipped.CanTransition(orders.StatusCancelled))
+	assert.False(t, orders.StatusCancelled.CanTransition(orders.StatusPending))
+	assert.False(t, orders.StatusDelivered.CanTransition(orders.StatusCancelled))
+
+	order, err := orders.NewOrder("reo", []orders.Item{{Product: "kopi", Quantity: 3, Price: 15000}, {Product: "roti", Quantity: 1, Price: 8000}})
+	assert.Nil(t, err)
+	assert.Equal(t, orders.StatusPending, order.Status)
+	assert.Equal(t, int64(53000), order.Total)
+
+	_, err = order.Transition(orders.StatusDelivered)
+	assert.ErrorIs(t, err, orders.ErrInvalidTransition)
+
+	// total yang tidak muat di int64 ditolak, bukan menjadi negatif
+	_, err = orders.NewOrder("reo", []orders.Item{{Product: "emas", Quantity: 3, Price: math.MaxInt64 / 2}})
+	assert.ErrorIs(t, err, orders.ErrInvalidTotal)
+	_, err = orders.NewOrder("reo", []orders.Item{{Product: "emas", Quantity: 1, Price: math.MaxInt64}, {Product: "kopi", Quantity: 1, Price: 1}})
+	assert.ErrorIs(t, err, orders.ErrInvalidTotal)
+}
+
+// test kedua implementasi repository berperilaku sama
+func TestOrderRepository(t *testing.T) {
+	repositories := map[string]orders.Repository{
+		"memory": orders.NewMemoryRepository(),
+	}
+	// driver sqlite hanya ada di build dengan cgo
+	sqlite, err := orders.Config{Driver: "sqlite", Path: filepath.Join(t.TempDir(), "orders.db")}.Open()
+	if errors.Is(err, orders.ErrSQLiteUnavailable) {
+		t.Log(err)
+	} else {
+		assert.Nil(t, err)
+		defer sqlite.(io.Closer).Close()
+		repositories["sqlite"] = sqlite
+	}
+	for name, repository := range repositories {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			items := []orders.Item{{Product: "kopi", Quantity: 2, Price: 15000}}
+
+			for i, userId := range []string{"reo", "budi", "reo", "reo"} {
+				order, err := orders.NewOrder(userId, items)
+				assert.Nil(t, err)
+				order, err = repository.Create(ctx, order)
+				assert.Nil(t, err)
+				assert.Equal(t, i+1, order.ID)
+			}
+
+			order, err := repository.Get(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, "reo", order.UserID)
+			assert.Equal(t, items, order.Items)
+			assert.Equal(t, int64(30000), order.Total)
+			assert.False(t, order.CreatedAt.IsZero())
+			_, err = repository.Get(ctx, 10)
+			assert.ErrorIs(t, err, orders.ErrNotFound)
+
+			// status berubah hanya lewat transisi yang diizinkan
+			order, err = repository.UpdateStatus(ctx, 3, orders.StatusPaid)
+			assert.Nil(t, err)
+			assert.Equal(t, orders.StatusPaid, order.Status)
+			_, err = repository.UpdateStatus(ctx, 3, orders.StatusDelivered)
+			assert.ErrorIs(t, err, orders.ErrInvalidTransition)
+			_, err = repository.UpdateStatus(ctx, 10, orders.StatusPaid)
+			assert.ErrorIs(t, err, orders.ErrNotFound)
+			order, _ = repository.Get(ctx, 3)
+			assert.Equal(t, orders.StatusPaid, order.Status)
+
+			// order terbaru lebih dulu
+			found, total, err := repository.List(ctx, orders.Query{UserID: "reo", Page: 1, PerPage: 2})
+			assert.Nil(t, err)
+			assert.Equal(t, 3, total)
+			assert.Equal(t, []int{4, 3}, []int{found[0].ID, found[1].ID})
+			found, _, _ = repository.List(ctx, orders.Query{UserID: "reo", Page: 2, PerPage: 2})
+			assert.Len(t, found, 1)
+			found, total, _ = repository.List(ctx, orders.Query{Status: orders.StatusPaid})
+			assert.Equal(t, 1, total)
+			assert.Equal(t, 3, found[0].ID)
+		})
+	}
+}
+
+func TestOrders(t *testing.T) {
+	app := fiber.New()
+	ids := registerOrderRoutes(t, app, orders.NewMemoryRepository())
+	reo, budi, admin := ids["reo@example.com"], ids["budi@example.com"], ids["admin@example.com"]
+	item := `{"items":[{"product":"kopi","quantity":2,"price":15000}]}`
+
+	// test user membuat order untuk dirinya sendiri
+	t.Run("test create order", func(t *testing.T) {
+		code, _ := userRequest(t, app, http.MethodPost, orderPath(reo), item, "", "")
+		assert.Equal(t, http.StatusUnauthorized, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(budi), item, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo), `{"items":[]}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo), `{"items":[{"product":"kopi","quantity":0}]}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo), `{"items":[{"product":"emas","quantity":10000,"price":1000000000001}]}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, body := userRequest(t, app, http.MethodPost, orderPath(reo), item, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusCreated, code)
+		data := body["data"].(map[string]any)
+		assert.Equal(t, float64(1), data["id"])
+		assert.Equal(t, reo, data["user_id"])
+		assert.Equal(t, "pending", data["status"])
+		assert.Equal(t, float64(30000), data["total"])
+
+		// order ke-2 reo, order ke-3 budi dibuat admin
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo), item, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusCreated, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(budi), item, "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusCreated, code)
+		code, _ = userRequest(t, app, http.MethodPost, orderPath("tidak-ada"), item, "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	// test user hanya melihat order miliknya, admin melihat semua
+	t.Run("test get and list orders", func(t *testing.T) {
+		code, body := userRequest(t, app, http.MethodGet, orderPath(reo), "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{2, 1}, orderIds(body))
+		_, body = userRequest(t, app, http.MethodGet, orderPath(reo)+"?per_page=1&page=2", "", "reo@example.com", "rahasia")
+		assert.Equal(t, []int{1}, orderIds(body))
+		assert.Equal(t, float64(2), body["data"].(map[string]any)["total_pages"])
+
+		code, _ = userRequest(t, app, http.MethodGet, orderPath(budi), "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = userRequest(t, app, http.MethodGet, orderPath(budi, 3), "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+		_, body = userRequest(t, app, http.MethodGet, orderPath(budi), "", "admin@example.com", "rahasia")
+		assert.Equal(t, []int{3}, orderIds(body))
+
+		// order milik user lain tidak terlihat lewat path user sendiri
+		code, _ = userRequest(t, app, http.MethodGet, orderPath(reo, 3), "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = userRequest(t, app, http.MethodGet, orderPath(admin, 1), "", "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, body = userRequest(t, app, http.MethodGet, orderPath(reo, 1), "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "kopi", body["data"].(map[string]any)["items"].([]any)[0].(map[string]any)["product"])
+	})
+
+	// test cancel & perubahan status oleh admin mengikuti transisi
+	t.Run("test order status", func(t *testing.T) {
+		code, _ := userRequest(t, app, http.MethodPatch, orderPath(reo, 2), `{"status":"paid"}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = userRequest(t, app, http.MethodPatch, orderPath(reo, 2), `{"status":"lunas"}`, "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = userRequest(t, app, http.MethodPatch, orderPath(reo, 2), `{"status":"shipped"}`, "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusConflict, code)
+
+		for _, status := range []string{"paid", "shipped"} {
+			code, body := userRequest(t, app, http.MethodPatch, orderPath(reo, 2), `{"status":"`+status+`"}`, "admin@example.com", "rahasia")
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, status, body["data"].(map[string]any)["status"])
+		}
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo, 2)+"/cancel", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusConflict, code)
+
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(budi, 3)+"/cancel", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+		code, body := userRequest(t, app, http.MethodPost, orderPath(reo, 1)+"/cancel", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "cancelled", body["data"].(map[string]any)["status"])
+		code, _ = userRequest(t, app, http.MethodPost, orderPath(reo, 1)+"/cancel", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusConflict, code)
+
+		_, body = userRequest(t, app, http.MethodGet, orderPath(reo)+"?status=cancelled", "", "reo@example.com", "rahasia")
+		assert.Equal(t, []int{1}, orderIds(body))
+	})
+}
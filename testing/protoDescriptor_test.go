@@ -0,0 +1,89 @@
+package testing
+
+import (
+	"context"
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/model/dto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"testing"
+)
+
+// compile proto/api.proto, descriptor message berdasarkan nama
+func compileApiProto(t *testing.T) protoreflect.FileDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"../proto"}}),
+	}
+	files, err := compiler.Compile(context.Background(), "api.proto")
+	assert.Nil(t, err)
+	return files[0]
+}
+
+type protoMessage interface {
+	MarshalProto() ([]byte, error)
+	UnmarshalProto(data []byte) error
+}
+
+// test encoding manual di model/dto/protobuf.go sama dengan descriptor dari proto/api.proto
+func TestProtobufMatchesDescriptor(t *testing.T) {
+	file := compileApiProto(t)
+
+	testCases := []struct {
+		message string
+		value   protoMessage
+		empty   func() protoMessage
+		fields  map[string]any
+	}{
+		{
+			message: "LoginRequest",
+			value:   &dto.LoginRequest{Email: "reo@example.com", Password: "rahasia"},
+			empty:   func() protoMessage { return &dto.LoginRequest{} },
+			fields:  map[string]any{"email": "reo@example.com", "password": "rahasia"},
+		},
+		{
+			message: "RegisterUser",
+			value:   &dto.RegisterUser{Username: "reo@example.com", Password: "rahasia", Name: "Reo"},
+			empty:   func() protoMessage { return &dto.RegisterUser{} },
+			fields:  map[string]any{"username": "reo@example.com", "password": "rahasia", "name": "Reo"},
+		},
+		{
+			message: "ApiResponse",
+			value:   &dto.ApiResponse{StatusCode: 201, Status: "created", Message: "ok", Data: map[string]any{"id": "1"}},
+			empty:   func() protoMessage { return &dto.ApiResponse{} },
+			fields:  map[string]any{"status_code": int32(201), "status": "created", "message": "ok"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.message, func(t *testing.T) {
+			descriptor := file.Messages().ByName(protoreflect.Name(testCase.message))
+			assert.NotNil(t, descriptor)
+
+			// dto -> descriptor: semua field dikenal dengan nilai yang sama
+			encoded, err := testCase.value.MarshalProto()
+			assert.Nil(t, err)
+			message := dynamicpb.NewMessage(descriptor)
+			assert.Nil(t, proto.Unmarshal(encoded, message))
+			assert.Empty(t, message.GetUnknown())
+			for name, expected := range testCase.fields {
+				field := descriptor.Fields().ByName(protoreflect.Name(name))
+				assert.NotNil(t, field, name)
+				assert.Equal(t, expected, message.Get(field).Interface(), name)
+			}
+
+			// descriptor -> dto: hasil decode sama dengan nilai awal
+			encoded, err = proto.Marshal(message)
+			assert.Nil(t, err)
+			decoded := testCase.empty()
+			assert.Nil(t, decoded.UnmarshalProto(encoded))
+			assert.Equal(t, testCase.value, decoded)
+		})
+	}
+
+	// data ApiResponse adalah google.protobuf.Value
+	data := file.Messages().ByName("ApiResponse").Fields().ByName("data")
+	assert.Equal(t, (&structpb.Value{}).ProtoReflect().Descriptor().FullName(), data.Message().FullName())
+}
@@ -0,0 +1,100 @@
+package testing
+
+import (
+	"bytes"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/model/dto"
+	"google.golang.org/protobuf/encoding/protowire"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// encode LoginRequest sesuai field number di proto/api.proto
+func encodeLoginProto(email, password string) []byte {
+	var buffer []byte
+	buffer = protowire.AppendTag(buffer, 1, protowire.BytesType)
+	buffer = protowire.AppendString(buffer, email)
+	buffer = protowire.AppendTag(buffer, 2, protowire.BytesType)
+	buffer = protowire.AppendString(buffer, password)
+	return buffer
+}
+
+// test login dengan request & response protobuf
+func TestProtobufLogin(t *testing.T) {
+	app := fiber.New()
+	validate := validator.New()
+	Routes.NewTestRoutes(app, validate)
+
+	// test success
+	t.Run("test protobuf login success", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(encodeLoginProto("reoshby@gmail.com", "123456")))
+		request.Header.Add("content-type", "application/x-protobuf")
+		request.Header.Add("Accept", "application/x-protobuf")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/x-protobuf", response.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(response.Body)
+
+		// field 1 = status_code
+		number, wireType, n := protowire.ConsumeTag(body)
+		assert.Equal(t, protowire.Number(1), number)
+		assert.Equal(t, protowire.VarintType, wireType)
+		statusCode, _ := protowire.ConsumeVarint(body[n:])
+		assert.Equal(t, uint64(http.StatusOK), statusCode)
+
+		responseBody := dto.ApiResponse{}
+		assert.Nil(t, responseBody.UnmarshalProto(body))
+		assert.Equal(t, "success login", responseBody.Message)
+		assert.Equal(t, "reoshby@gmail.com", responseBody.Data.(map[string]any)["email"])
+	})
+
+	// test validasi tetap berjalan untuk protobuf
+	t.Run("test protobuf login bad request", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(encodeLoginProto("reoshby", "12")))
+		request.Header.Add("content-type", "application/x-protobuf")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	})
+
+	// test protobuf rusak
+	t.Run("test protobuf malformed body", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader([]byte{0x0a, 0xff}))
+		request.Header.Add("content-type", "application/x-protobuf")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+}
+
+// test register dto round trip
+func TestProtobufRegisterUser(t *testing.T) {
+	user := dto.RegisterUser{Username: "reoshby@gmail.com", Password: "123456", Name: "Reo Sahobby"}
+	encoded, err := user.MarshalProto()
+	assert.Nil(t, err)
+
+	decoded := dto.RegisterUser{}
+	assert.Nil(t, decoded.UnmarshalProto(encoded))
+	assert.Equal(t, user, decoded)
+
+	// handler lain yang membalas dto.ApiResponse juga bisa protobuf
+	app := fiber.New()
+	Routes.NewTestRoutes(app, validator.New())
+	request := httptest.NewRequest(http.MethodGet, "/response-json", nil)
+	request.Header.Add("Accept", "application/x-protobuf")
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/x-protobuf", response.Header.Get("Content-Type"))
+}
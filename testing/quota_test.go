@@ -0,0 +1,206 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/catalogue"
+	"go_fiber/handler"
+	"go_fiber/imaging"
+	"go_fiber/quota"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newQuotaApp(t *testing.T, config quota.Config) (*fiber.App, *storage.ContentStorage) {
+	store := storage.NewContentStorage(t.TempDir())
+	fixture := newFileFixture(t, store)
+	config.Directory = t.TempDir()
+	checker := quota.NewChecker(config, fixture.catalogue, store)
+	images := imaging.NewProcessor(imaging.Config{Variants: []imaging.Variant{{Name: "thumb", Width: 20, Height: 20, Mode: "fit"}}}, store)
+
+	fixture.uploads.Pipeline = upload.Pipeline{checker, images, checker.Recorder(), catalogue.NewProcessor(fixture.catalogue)}
+	fixture.uploads.Quota = checker
+	fixture.files.Images = images
+	fixture.files.Quota = checker
+
+	tusHandler := handler.NewTusHandler(upload.NewTusStore(upload.TusConfig{Directory: t.TempDir()}, store))
+	tusHandler.Quota = checker
+
+	app := fixture.routes()
+	Routes.NewTusRoutes(app, tusHandler)
+	Routes.NewQuotaRoutes(app, handler.NewQuotaHandler(checker))
+	return app, store
+}
+
+// kuota reo dari GET /me/quota
+func quotaOf(t *testing.T, app *fiber.App) map[string]any {
+	response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/me/quota", nil), "reo@example.com"))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	return decodeApiResponse(t, response)["data"].(map[string]any)
+}
+
+// gambar dengan pixel acak tapi tetap sama setiap dibuat, png-nya hampir tidak terkompresi
+func noisyImage(width int, height int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	random := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = byte(random.Intn(256))
+	}
+	return img
+}
+
+// jumlah blob di directory storage
+func countBlobs(t *testing.T, root string) int {
+	blobs := 0
+	err := filepath.WalkDir(filepath.Join(root, "blobs"), func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			blobs++
+		}
+		return err
+	})
+	assert.Nil(t, err)
+	return blobs
+}
+
+func readStored(t *testing.T, store storage.Storage, name string) string {
+	reader, err := store.Open(context.Background(), name)
+	assert.Nil(t, err)
+	defer reader.Close()
+	content, _ := io.ReadAll(reader)
+	return string(content)
+}
+
+func TestContentStorage(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewContentStorage(root)
+
+	// test konten sama disimpan sekali
+	t.Run("test duplicate content stored once", func(t *testing.T) {
+		for _, name := range []string{"pertama", "kedua"} {
+			written, err := store.Save(ctx, name, strings.NewReader("isi yang sama"))
+			assert.Nil(t, err)
+			assert.Equal(t, int64(13), written)
+		}
+		assert.Equal(t, 1, countBlobs(t, root))
+		used, err := store.Usage(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(13), used)
+		assert.Equal(t, "isi yang sama", readStored(t, store, "kedua"))
+	})
+
+	// test blob baru dihapus setelah name terakhir dihapus
+	t.Run("test blob removed with last reference", func(t *testing.T) {
+		assert.Nil(t, store.Remove(ctx, "pertama"))
+		assert.Equal(t, 1, countBlobs(t, root))
+		assert.Equal(t, "isi yang sama", readStored(t, store, "kedua"))
+
+		assert.Nil(t, store.Remove(ctx, "kedua"))
+		assert.Equal(t, 0, countBlobs(t, root))
+		assert.ErrorIs(t, store.Remove(ctx, "kedua"), storage.ErrNotFound)
+	})
+
+	// test name ditimpa konten lain melepas blob lama
+	t.Run("test overwrite releases old blob", func(t *testing.T) {
+		_, err := store.Save(ctx, "laporan", strings.NewReader("versi satu"))
+		assert.Nil(t, err)
+		_, err = store.Save(ctx, "laporan", strings.NewReader("versi dua"))
+		assert.Nil(t, err)
+
+		assert.Equal(t, 1, countBlobs(t, root))
+		assert.Equal(t, "versi dua", readStored(t, store, "laporan"))
+	})
+}
+
+func TestUploadQuota(t *testing.T) {
+	// test kuota user, upload yang melebihi ditolak 413 dari Content-Length sebelum disimpan
+	t.Run("test user quota", func(t *testing.T) {
+		app, store := newQuotaApp(t, quota.Config{User: 1000})
+
+		response := uploadFile(t, app, "satu.txt", []byte(strings.Repeat("a", 600)))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		response = uploadFile(t, app, "dua.txt", []byte(strings.Repeat("b", 300)))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+		assert.Contains(t, decodeApiResponse(t, response)["message"], "user storage quota exceeded")
+		used, _ := store.Usage(context.Background())
+		assert.Equal(t, int64(600), used)
+
+		data := quotaOf(t, app)
+		assert.Equal(t, float64(1), data["files"])
+		assert.Equal(t, float64(600), data["used"])
+		assert.Equal(t, float64(1000), data["limit"])
+		assert.Equal(t, float64(400), data["remaining"])
+	})
+
+	// test variant gambar ikut dihitung, upload yang melebihi kuota karena variant dibatalkan
+	t.Run("test variants count for user quota", func(t *testing.T) {
+		encoded := &bytes.Buffer{}
+		assert.Nil(t, png.Encode(encoded, noisyImage(100, 100)))
+
+		app, _ := newQuotaApp(t, quota.Config{})
+		response := uploadFile(t, app, "foto.png", encoded.Bytes())
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		uploaded := decodeApiResponse(t, response)["data"].(map[string]any)
+		total := int64(encoded.Len()) + int64(uploaded["variant_size"].(float64))
+		assert.Greater(t, total, int64(encoded.Len()))
+		assert.Equal(t, float64(total), quotaOf(t, app)["used"])
+
+		// delete mengembalikan kuota
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/"+uploaded["id"].(string), nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, float64(0), quotaOf(t, app)["used"])
+
+		// file asli masih muat tapi variantnya tidak
+		app, store := newQuotaApp(t, quota.Config{User: total - 1})
+		response = uploadFile(t, app, "foto.png", encoded.Bytes())
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+		assert.Equal(t, float64(0), quotaOf(t, app)["used"])
+		used, _ := store.Usage(context.Background())
+		assert.Equal(t, int64(0), used)
+	})
+
+	// test kuota global menghitung konten duplikat sekali
+	t.Run("test global quota", func(t *testing.T) {
+		app, store := newQuotaApp(t, quota.Config{Global: 1700})
+
+		for _, name := range []string{"asli.txt", "salinan.txt"} {
+			response := uploadFile(t, app, name, []byte(strings.Repeat("a", 600)))
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+		}
+		used, _ := store.Usage(context.Background())
+		assert.Equal(t, int64(600), used)
+
+		response := uploadFile(t, app, "lain.txt", []byte(strings.Repeat("b", 1000)))
+		assert.Equal(t, http.StatusInsufficientStorage, response.StatusCode)
+		assert.Equal(t, "insufficient storage", decodeApiResponse(t, response)["status"])
+		used, _ = store.Usage(context.Background())
+		assert.Equal(t, int64(600), used)
+	})
+
+	// test upload tus ditolak saat dibuat dari Upload-Length
+	t.Run("test tus upload length over quota", func(t *testing.T) {
+		app, _ := newQuotaApp(t, quota.Config{User: 20})
+
+		response := tusRequest(t, app, http.MethodPost, "/uploads", "", map[string]string{"Upload-Length": "21"})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+		response = tusRequest(t, app, http.MethodPost, "/uploads", "", map[string]string{"Upload-Length": "20"})
+		assert.Equal(t, http.StatusCreated, response.StatusCode)
+	})
+}
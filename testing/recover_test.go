@@ -0,0 +1,127 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/monitoring"
+	"go_fiber/view"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type sentryRequest struct {
+	path  string
+	auth  string
+	event monitoring.Event
+}
+
+// stub endpoint sentry, event yang diterima dikirim ke channel
+func newSentryStub(t *testing.T) (*httptest.Server, chan sentryRequest) {
+	events := make(chan sentryRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		received := sentryRequest{path: request.URL.Path, auth: request.Header.Get("X-Sentry-Auth")}
+		assert.Nil(t, json.NewDecoder(request.Body).Decode(&received.event))
+		events <- received
+		writer.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, events
+}
+
+func newPanicApp(t *testing.T, config middleware.RecoverConfig) *fiber.App {
+	recoverMiddleware, err := middleware.NewRecoverMiddleware(config)
+	assert.Nil(t, err)
+
+	app := fiber.New(fiber.Config{
+		Views:             view.NewEngine(),
+		PassLocalsToViews: true,
+		ErrorHandler:      handler.NewDevErrorHandler().ErrorHandler,
+	})
+	app.Use(recoverMiddleware.Handle)
+	app.Use(requestid.New(requestid.Config{ContextKey: view.RequestIdKey}))
+	app.Get("/panic/:id", func(ctx *fiber.Ctx) error {
+		panic("boom " + ctx.Params("id"))
+	})
+	return app
+}
+
+func TestRecoverMiddleware(t *testing.T) {
+	logs := &bytes.Buffer{}
+	sentry, events := newSentryStub(t)
+	app := newPanicApp(t, middleware.RecoverConfig{
+		SentryDSN:   strings.Replace(sentry.URL, "http://", "http://public-key@", 1) + "/42",
+		Environment: "testing",
+		Logger:      slog.New(slog.NewJSONHandler(logs, nil)),
+	})
+
+	// test panic menjadi response 500 lewat error handler
+	t.Run("test recover panic json", func(t *testing.T) {
+		before := monitoring.PanicsTotal.Value()
+
+		request := httptest.NewRequest(http.MethodGet, "/panic/7", nil)
+		request.Header.Add("Authorization", "Bearer secret-token")
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		assert.Nil(t, json.Unmarshal(body, &responseBody))
+		assert.Equal(t, "internal server error", responseBody["message"])
+		assert.NotContains(t, string(body), "boom")
+
+		// metric panic bertambah
+		assert.Equal(t, before+1, monitoring.PanicsTotal.Value())
+
+		// log terstruktur berisi konteks request & stack trace
+		entry := map[string]any{}
+		assert.Nil(t, json.Unmarshal(logs.Bytes(), &entry))
+		assert.Equal(t, "panic recovered", entry["msg"])
+		assert.Equal(t, "boom 7", entry["panic"])
+		assert.Equal(t, "/panic/:id", entry["route"])
+		assert.Equal(t, response.Header.Get(fiber.HeaderXRequestID), entry["request_id"])
+		assert.Contains(t, entry["stack"], "runtime/debug.Stack")
+
+		// event diteruskan ke sentry
+		select {
+		case received := <-events:
+			assert.Equal(t, "/api/42/store/", received.path)
+			assert.Contains(t, received.auth, "sentry_key=public-key")
+			assert.Equal(t, "panic: boom 7", received.event.Message)
+			assert.Equal(t, "testing", received.event.Environment)
+			assert.Equal(t, http.MethodGet, received.event.Request.Method)
+			assert.Len(t, received.event.EventID, 32)
+			assert.NotContains(t, received.event.Request.Headers, "Authorization")
+		case <-time.After(2 * time.Second):
+			t.Fatal("sentry stub did not receive the event")
+		}
+	})
+
+	// test halaman error dev menampilkan stack panic
+	t.Run("test recover panic html", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/panic/8", nil)
+		request.Header.Add("Accept", browserAccept)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusInternalServerError, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Contains(t, string(body), "panic: boom 8")
+		<-events
+	})
+}
+
+func TestRecoverMiddlewareInvalidDsn(t *testing.T) {
+	_, err := middleware.NewRecoverMiddleware(middleware.RecoverConfig{SentryDSN: "http://sentry.local/42"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,152 @@
+package testing
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
+	"go_fiber/Routes"
+	"go_fiber/render"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// test pemilihan media type dari header Accept
+func TestNegotiate(t *testing.T) {
+	offers := []string{"application/json", "application/xml", "application/msgpack"}
+
+	testCases := []struct {
+		accept   string
+		expected string
+		ok       bool
+	}{
+		{"", "application/json", true},
+		{"*/*", "application/json", true},
+		{"application/xml", "application/xml", true},
+		{"application/json;q=0.5, application/msgpack", "application/msgpack", true},
+		{"application/*;q=0.8, application/xml;q=0.9", "application/xml", true},
+		{"*/*;q=0.1, application/json;q=0", "application/xml", true},
+		{"image/png", "", false},
+	}
+
+	for _, testCase := range testCases {
+		mediaType, ok := render.Negotiate(testCase.accept, offers)
+		assert.Equal(t, testCase.ok, ok, testCase.accept)
+		assert.Equal(t, testCase.expected, mediaType, testCase.accept)
+	}
+}
+
+// test response api sesuai header Accept
+func TestContentNegotiationResponse(t *testing.T) {
+	app := fiber.New()
+	validate := validator.New()
+	Routes.NewTestRoutes(app, validate)
+
+	hit := func(accept string) (*http.Response, []byte) {
+		request := httptest.NewRequest(http.MethodGet, "/response-json?name=reo", nil)
+		request.Header.Add("Accept", accept)
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(response.Body)
+		return response, body
+	}
+
+	// test xml
+	t.Run("test xml response", func(t *testing.T) {
+		response, body := hit("application/xml")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "application/xml", response.Header.Get("Content-Type"))
+
+		responseBody := struct {
+			XMLName    xml.Name `xml:"response"`
+			StatusCode int      `xml:"status_code"`
+			Data       string   `xml:"data"`
+		}{}
+		assert.Nil(t, xml.Unmarshal(body, &responseBody))
+		assert.Equal(t, http.StatusOK, responseBody.StatusCode)
+		assert.Equal(t, "your name is [reo]", responseBody.Data)
+	})
+
+	// test msgpack
+	t.Run("test msgpack response", func(t *testing.T) {
+		response, body := hit("application/msgpack")
+		assert.Equal(t, "application/msgpack", response.Header.Get("Content-Type"))
+
+		responseBody := map[string]any{}
+		assert.Nil(t, msgpack.Unmarshal(body, &responseBody))
+		assert.Equal(t, "your name is [reo]", responseBody["data"])
+	})
+
+	// test cbor
+	t.Run("test cbor response", func(t *testing.T) {
+		response, body := hit("application/json;q=0.5, application/cbor")
+		assert.Equal(t, "application/cbor", response.Header.Get("Content-Type"))
+
+		responseBody := map[string]any{}
+		assert.Nil(t, cbor.Unmarshal(body, &responseBody))
+		assert.Equal(t, "your name is [reo]", responseBody["data"])
+	})
+
+	// test media type tidak didukung
+	t.Run("test not acceptable", func(t *testing.T) {
+		response, _ := hit("image/png")
+		assert.Equal(t, http.StatusNotAcceptable, response.StatusCode)
+	})
+}
+
+// test request body msgpack & cbor
+func TestContentNegotiationRequest(t *testing.T) {
+	app := fiber.New()
+	validate := validator.New()
+	Routes.NewTestRoutes(app, validate)
+
+	user := map[string]string{"username": "reoshby@gmail.com", "password": "123456", "name": "Reo Sahobby"}
+	msgpackBody, _ := msgpack.Marshal(user)
+	cborBody, _ := cbor.Marshal(user)
+
+	testCases := []struct {
+		name        string
+		contentType string
+		body        []byte
+		status      int
+	}{
+		{"test body without content type", "", []byte(`{"username":"reoshby@gmail.com","password":"123456","name":"Reo"}`), http.StatusOK},
+		{"test msgpack body", "application/msgpack", msgpackBody, http.StatusOK},
+		{"test cbor body", "application/cbor", cborBody, http.StatusOK},
+		{"test xml body", "application/xml", []byte("<user><username>reoshby@gmail.com</username><password>123456</password><name>Reo</name></user>"), http.StatusOK},
+		{"test broken cbor body", "application/cbor", []byte{0xff, 0x01}, http.StatusBadRequest},
+		{"test unsupported media type", "text/plain", []byte("reo"), http.StatusUnsupportedMediaType},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/register", bytes.NewReader(testCase.body))
+			if testCase.contentType != "" {
+				request.Header.Add("content-type", testCase.contentType)
+			}
+
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, testCase.status, response.StatusCode)
+		})
+	}
+
+	// test error juga mengikuti Accept
+	t.Run("test error response negotiated", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader("reo"))
+		request.Header.Add("content-type", "text/plain")
+		request.Header.Add("Accept", "application/xml")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode)
+		assert.Equal(t, "application/xml", response.Header.Get("Content-Type"))
+	})
+}
@@ -0,0 +1,219 @@
+package testing
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/scanning"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// string test standar eicar, dikenali sebagai malware oleh semua antivirus
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!H+H*`
+
+// fakeClamd implements the clamd PING and INSTREAM commands, content containing the
+// eicar string is reported as infected
+type fakeClamd struct {
+	listener net.Listener
+	received chan []byte
+}
+
+func newFakeClamd(t *testing.T) *fakeClamd {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	clamd := &fakeClamd{listener: listener, received: make(chan []byte, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go clamd.serve(conn)
+		}
+	}()
+	return clamd
+}
+
+func (f *fakeClamd) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	command, err := reader.ReadString(0)
+	if err != nil {
+		return
+	}
+
+	switch command {
+	case "zPING\x00":
+		io.WriteString(conn, "PONG\x00")
+	case "zINSTREAM\x00":
+		content := &bytes.Buffer{}
+		for {
+			var length uint32
+			if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+				return
+			}
+			if length == 0 {
+				break
+			}
+			if _, err := io.CopyN(content, reader, int64(length)); err != nil {
+				return
+			}
+		}
+		f.received <- content.Bytes()
+
+		if strings.Contains(content.String(), eicar) {
+			io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+		} else {
+			io.WriteString(conn, "stream: OK\x00")
+		}
+	default:
+		io.WriteString(conn, "UNKNOWN COMMAND\x00")
+	}
+}
+
+// app dengan karantina, scanner nil berarti scan dimatikan sehingga tidak ada yang di-scan
+func newScanningApp(t *testing.T, scanner scanning.Scanner) (*fiber.App, string) {
+	directory := t.TempDir()
+	store := storage.NewLocalStorage(directory)
+	quarantine := scanning.NewQuarantine(store)
+
+	// tanpa catalogue, download hanya dicek terhadap karantina
+	fixture := newFileFixture(t, store)
+	fixture.uploads.Pipeline = nil
+	if scanner != nil {
+		fixture.uploads.Pipeline = upload.Pipeline{scanning.NewProcessor(scanner, quarantine, store)}
+	}
+	fixture.files.Catalogue = nil
+	fixture.files.Quarantine = quarantine
+	return fixture.routes(), directory
+}
+
+func TestClamdScanner(t *testing.T) {
+	clamd := newFakeClamd(t)
+	scanner := scanning.NewClamdScanner(scanning.ClamdConfig{Address: clamd.listener.Addr().String(), ChunkSize: 7})
+
+	// test ping
+	t.Run("test ping", func(t *testing.T) {
+		assert.Nil(t, scanner.Ping(context.Background()))
+	})
+
+	// test file dikirim utuh walaupun dipotong menjadi beberapa chunk
+	t.Run("test scan clean", func(t *testing.T) {
+		content := strings.Repeat("isi file biasa ", 20)
+		result, err := scanner.Scan(context.Background(), strings.NewReader(content))
+		assert.Nil(t, err)
+		assert.False(t, result.Infected)
+		assert.Equal(t, content, string(<-clamd.received))
+	})
+
+	// test file terinfeksi
+	t.Run("test scan infected", func(t *testing.T) {
+		result, err := scanner.Scan(context.Background(), strings.NewReader("awal "+eicar+" akhir"))
+		assert.Nil(t, err)
+		assert.True(t, result.Infected)
+		assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+		<-clamd.received
+	})
+
+	// test clamd tidak membalas dalam timeout
+	t.Run("test scan timeout", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.Nil(t, err)
+		defer listener.Close()
+		go func() {
+			conn, err := listener.Accept()
+			if err == nil {
+				io.Copy(io.Discard, conn)
+			}
+		}()
+
+		silent := scanning.NewClamdScanner(scanning.ClamdConfig{Address: listener.Addr().String(), Timeout: 100 * time.Millisecond})
+		_, err = silent.Scan(context.Background(), strings.NewReader("isi"))
+		assert.ErrorContains(t, err, "clamd did not reply within 100ms")
+		assert.NotErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestUploadScanning(t *testing.T) {
+	clamd := newFakeClamd(t)
+	app, directory := newScanningApp(t, scanning.NewClamdScanner(scanning.ClamdConfig{Address: clamd.listener.Addr().String(), Timeout: time.Second}))
+	var reportId string
+
+	// test file bersih bisa didownload setelah di-scan
+	t.Run("test upload clean file", func(t *testing.T) {
+		response := uploadFile(t, app, "laporan.txt", []byte("isi laporan"))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		reportId = decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+		<-clamd.received
+
+		response = downloadFile(t, app, "admin@example.com", reportId)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, `attachment; filename="`+reportId+`"`, response.Header.Get("Content-Disposition"))
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, "isi laporan", string(body))
+	})
+
+	// test file terinfeksi ditolak dan dihapus
+	t.Run("test upload infected file", func(t *testing.T) {
+		response := uploadFile(t, app, "virus.txt", []byte(eicar))
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+		<-clamd.received
+
+		body := decodeApiResponse(t, response)
+		assert.Equal(t, "upload rejected : file is infected (Eicar-Test-Signature)", body["message"])
+		assertStoredFiles(t, directory, reportId, scanning.RecordName(reportId))
+	})
+
+	// test file yang belum di-scan tidak bisa didownload
+	t.Run("test download quarantined file", func(t *testing.T) {
+		assert.Nil(t, os.WriteFile(filepath.Join(directory, "belum.txt"), []byte("belum di-scan"), 0644))
+
+		response := downloadFile(t, app, "admin@example.com", "belum.txt")
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+		assert.Empty(t, response.Header.Get("Content-Disposition"))
+		assert.Equal(t, "file is quarantined until it has been scanned clean", decodeApiResponse(t, response)["message"])
+	})
+}
+
+func TestUploadScannerUnavailable(t *testing.T) {
+	// alamat yang tidak ada listener-nya
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	app, directory := newScanningApp(t, scanning.NewClamdScanner(scanning.ClamdConfig{Address: address, Timeout: time.Second}))
+	response := uploadFile(t, app, "laporan.txt", []byte("isi laporan"))
+	assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	assert.Contains(t, decodeApiResponse(t, response)["message"], "malware scanner unavailable")
+	assertStoredFiles(t, directory)
+}
+
+// test tanpa scanner upload tetap dikarantina, kecuali development yang melewati scan
+func TestUploadScanningDisabled(t *testing.T) {
+	app, _ := newScanningApp(t, nil)
+	response := uploadFile(t, app, "laporan.txt", []byte("isi laporan"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	id := decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+	assert.Equal(t, http.StatusForbidden, downloadFile(t, app, "admin@example.com", id).StatusCode)
+
+	app, _ = newScanningApp(t, scanning.SkipScanner{})
+	response = uploadFile(t, app, "laporan.txt", []byte("isi laporan"))
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	id = decodeApiResponse(t, response)["data"].(map[string]any)["id"].(string)
+	assert.Equal(t, http.StatusOK, downloadFile(t, app, "admin@example.com", id).StatusCode)
+}
@@ -0,0 +1,183 @@
+package testing
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/catalogue"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/sharing"
+	"go_fiber/storage"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newShareApp(t *testing.T) (*fiber.App, *sharing.Signer) {
+	directory := t.TempDir()
+	fixture := newFileFixture(t, storage.NewLocalStorage(directory))
+
+	// satu file milik reo & satu file milik budi
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "file-1"), []byte("isi rahasia"), 0644))
+	assert.Nil(t, fixture.catalogue.Save(context.Background(), catalogue.Entry{ID: "file-1", Name: "rahasia.txt", Size: 11, Owner: fixture.ids["reo@example.com"]}))
+	assert.Nil(t, fixture.catalogue.Save(context.Background(), catalogue.Entry{ID: "file-2", Name: "budi.txt", Owner: fixture.ids["budi@example.com"]}))
+
+	signer, err := sharing.NewSigner([]byte(strings.Repeat("s", 32)))
+	assert.Nil(t, err)
+	links := sharing.NewStore(t.TempDir())
+	shareHandler := handler.NewShareHandler(fixture.catalogue, links, signer, sharing.Config{MaxExpiry: time.Hour}, validator.New())
+
+	Routes.NewShareRoutes(fixture.app, shareHandler, fixture.files, middleware.NewSignedLinkMiddleware(signer, links))
+	return fixture.app, signer
+}
+
+// buat link share sebagai reo, body json kosong jika body ""
+func createShareLink(t *testing.T, app *fiber.App, fileId string, body string) (int, map[string]any) {
+	request := loginAs(httptest.NewRequest(http.MethodPost, "/files/"+fileId+"/share", strings.NewReader(body)), "reo@example.com")
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	responseBody := decodeApiResponse(t, response)
+	data, _ := responseBody["data"].(map[string]any)
+	if data == nil {
+		data = responseBody
+	}
+	return response.StatusCode, data
+}
+
+// request ke url link, hanya path & query yang dipakai
+func openShareLink(t *testing.T, app *fiber.App, link string) *http.Response {
+	parsed, err := url.Parse(link)
+	assert.Nil(t, err)
+	response, err := app.Test(httptest.NewRequest(http.MethodGet, parsed.RequestURI(), nil))
+	assert.Nil(t, err)
+	return response
+}
+
+func TestShareLinks(t *testing.T) {
+	app, signer := newShareApp(t)
+
+	// test link bisa dipakai berulang & download dihitung
+	t.Run("test share and download", func(t *testing.T) {
+		code, link := createShareLink(t, app, "file-1", "")
+		assert.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, "file-1", link["file_id"])
+		assert.Contains(t, link["url"], "/shared/"+link["id"].(string)+"?expires=")
+
+		for i := 0; i < 2; i++ {
+			response := openShareLink(t, app, link["url"].(string))
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.Equal(t, `attachment; filename="rahasia.txt"`, response.Header.Get("Content-Disposition"))
+			body, _ := io.ReadAll(response.Body)
+			assert.Equal(t, "isi rahasia", string(body))
+		}
+
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodGet, "/files/file-1/share", nil), "reo@example.com"))
+		assert.Nil(t, err)
+		links := decodeApiResponse(t, response)["data"].([]any)
+		assert.Len(t, links, 1)
+		assert.Equal(t, float64(2), links[0].(map[string]any)["downloads"])
+	})
+
+	// test url yang diubah ditolak
+	t.Run("test tampered link", func(t *testing.T) {
+		_, link := createShareLink(t, app, "file-1", `{"expires_in": 60}`)
+		parsed, _ := url.Parse(link["url"].(string))
+		query := parsed.Query()
+
+		// perpanjang expiry tanpa signature baru
+		query.Set("expires", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		response := openShareLink(t, app, parsed.Path+"?"+query.Encode())
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+
+		response = openShareLink(t, app, parsed.Path+"?expires="+parsed.Query().Get("expires")+"&signature=salah")
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	// test link kedaluwarsa
+	t.Run("test expired link", func(t *testing.T) {
+		_, link := createShareLink(t, app, "file-1", "")
+		expires := time.Now().Add(-time.Minute).Unix()
+		signature := signer.Sign(link["id"].(string), expires, "")
+
+		response := openShareLink(t, app, "/shared/"+link["id"].(string)+"?expires="+strconv.FormatInt(expires, 10)+"&signature="+signature)
+		assert.Equal(t, http.StatusGone, response.StatusCode)
+		assert.Equal(t, "share link has expired", decodeApiResponse(t, response)["message"])
+	})
+
+	// test link sekali pakai
+	t.Run("test single use link", func(t *testing.T) {
+		_, link := createShareLink(t, app, "file-1", `{"single_use": true}`)
+		assert.Equal(t, http.StatusOK, openShareLink(t, app, link["url"].(string)).StatusCode)
+
+		response := openShareLink(t, app, link["url"].(string))
+		assert.Equal(t, http.StatusGone, response.StatusCode)
+		assert.Equal(t, "share link has already been used", decodeApiResponse(t, response)["message"])
+	})
+
+	// test link terikat ip
+	t.Run("test ip bound link", func(t *testing.T) {
+		_, other := createShareLink(t, app, "file-1", `{"ip": "10.1.2.3"}`)
+		assert.Equal(t, http.StatusForbidden, openShareLink(t, app, other["url"].(string)).StatusCode)
+
+		// app.Test memakai ip client 0.0.0.0
+		_, own := createShareLink(t, app, "file-1", `{"ip": "0.0.0.0"}`)
+		assert.Equal(t, http.StatusOK, openShareLink(t, app, own["url"].(string)).StatusCode)
+
+		// menghapus ip=1 dari url membuat signature tidak cocok
+		assert.Equal(t, http.StatusForbidden, openShareLink(t, app, strings.Replace(own["url"].(string), "ip=1&", "", 1)).StatusCode)
+	})
+
+	// test link yang dicabut
+	t.Run("test revoked link", func(t *testing.T) {
+		_, link := createShareLink(t, app, "file-1", "")
+		id := link["id"].(string)
+
+		response, err := app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/file-1/share/"+id, nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.NotNil(t, decodeApiResponse(t, response)["data"].(map[string]any)["revoked_at"])
+
+		response = openShareLink(t, app, link["url"].(string))
+		assert.Equal(t, http.StatusGone, response.StatusCode)
+		assert.Equal(t, "share link has been revoked", decodeApiResponse(t, response)["message"])
+
+		// link tidak bisa dicabut lewat file lain
+		response, err = app.Test(loginAs(httptest.NewRequest(http.MethodDelete, "/files/file-2/share/"+id, nil), "reo@example.com"))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusForbidden, response.StatusCode)
+	})
+
+	// test request share tidak valid
+	t.Run("test invalid share request", func(t *testing.T) {
+		code, _ := createShareLink(t, app, "file-1", `{"expires_in": 7200}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = createShareLink(t, app, "file-1", `{"ip": "bukan-ip"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, _ = createShareLink(t, app, "file-2", "")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = createShareLink(t, app, "tidak-ada", "")
+		assert.Equal(t, http.StatusNotFound, code)
+
+		// tanpa login
+		response, err := app.Test(httptest.NewRequest(http.MethodPost, "/files/file-1/share", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+	})
+}
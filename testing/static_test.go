@@ -0,0 +1,143 @@
+package testing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/handler"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newStaticApp() *fiber.App {
+	modTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	files := fstest.MapFS{
+		"css/app.css":         {Data: []byte("body{}"), ModTime: modTime},
+		"css/app.css.br":      {Data: []byte("brotli"), ModTime: modTime},
+		"css/app.css.gz":      {Data: []byte("gzip"), ModTime: modTime},
+		"js/app.3f2a9c1b.js":  {Data: []byte("console.log(1)"), ModTime: modTime},
+		".env":                {Data: []byte("SECRET=1")},
+		"docs/.hidden":        {Data: []byte("hidden")},
+		"docs/readme.txt":     {Data: []byte("readme")},
+		"spa/index.html":      {Data: []byte("<div id=app></div>")},
+		"spa/assets/logo.svg": {Data: []byte("<svg></svg>")},
+	}
+
+	spa, _ := fs.Sub(files, "spa")
+	app := fiber.New()
+	Routes.NewStaticRoutes(app,
+		handler.StaticMount{Prefix: "/public", Files: files, MaxAge: 3600, Precompressed: true, Browse: true},
+		handler.StaticMount{Prefix: "/app", Files: spa, SPA: true},
+	)
+	return app
+}
+
+func TestStaticMount(t *testing.T) {
+	app := newStaticApp()
+
+	get := func(url string, headers map[string]string) (*http.Response, string) {
+		request := httptest.NewRequest(http.MethodGet, url, nil)
+		for key, value := range headers {
+			request.Header.Add(key, value)
+		}
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(response.Body)
+		return response, string(body)
+	}
+
+	// test cache header dan etag
+	t.Run("test cache headers", func(t *testing.T) {
+		response, body := get("/public/css/app.css", nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "body{}", body)
+		assert.Equal(t, "public, max-age=3600", response.Header.Get("Cache-Control"))
+		assert.Equal(t, "Mon, 01 Jan 2024 00:00:00 GMT", response.Header.Get("Last-Modified"))
+		assert.NotEmpty(t, response.Header.Get("ETag"))
+
+		// conditional request
+		notModified, _ := get("/public/css/app.css", map[string]string{"If-None-Match": response.Header.Get("ETag")})
+		assert.Equal(t, http.StatusNotModified, notModified.StatusCode)
+		notModified, _ = get("/public/css/app.css", map[string]string{"If-Modified-Since": "Tue, 02 Jan 2024 00:00:00 GMT"})
+		assert.Equal(t, http.StatusNotModified, notModified.StatusCode)
+
+		// fingerprinted asset
+		response, _ = get("/public/js/app.3f2a9c1b.js", nil)
+		assert.Equal(t, "public, max-age=31536000, immutable", response.Header.Get("Cache-Control"))
+		sum := sha256.Sum256([]byte("body{}"))
+		response, _ = get("/public/css/app.css?v="+hex.EncodeToString(sum[:])[:10], nil)
+		assert.Equal(t, "public, max-age=31536000, immutable", response.Header.Get("Cache-Control"))
+
+		// ?v= yang tidak sama dengan hash isi file tidak di-cache selamanya
+		response, _ = get("/public/css/app.css?v=abc", nil)
+		assert.Equal(t, "public, max-age=3600", response.Header.Get("Cache-Control"))
+		response, _ = get("/public/css/app.css?v=0000000000", nil)
+		assert.Equal(t, "public, max-age=3600", response.Header.Get("Cache-Control"))
+	})
+
+	// test request sebagian file
+	t.Run("test range request", func(t *testing.T) {
+		response, body := get("/public/js/app.3f2a9c1b.js", map[string]string{"Range": "bytes=8-11"})
+		assert.Equal(t, http.StatusPartialContent, response.StatusCode)
+		assert.Equal(t, "bytes 8-11/14", response.Header.Get("Content-Range"))
+		assert.Equal(t, "log(", body)
+
+		response, _ = get("/public/js/app.3f2a9c1b.js", map[string]string{"Range": "bytes=100-"})
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, response.StatusCode)
+
+		response, body = get("/public/js/app.3f2a9c1b.js", nil)
+		assert.Equal(t, "bytes", response.Header.Get("Accept-Ranges"))
+		assert.Equal(t, "console.log(1)", body)
+	})
+
+	// test file precompressed
+	t.Run("test precompressed variants", func(t *testing.T) {
+		response, body := get("/public/css/app.css", map[string]string{"Accept-Encoding": "gzip, br"})
+		assert.Equal(t, "br", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, "brotli", body)
+		assert.Contains(t, response.Header.Get("Content-Type"), "text/css")
+
+		response, body = get("/public/css/app.css", map[string]string{"Accept-Encoding": "gzip"})
+		assert.Equal(t, "gzip", response.Header.Get("Content-Encoding"))
+		assert.Equal(t, "gzip", body)
+	})
+
+	// test dotfile tidak dilayani
+	t.Run("test hidden files", func(t *testing.T) {
+		response, _ := get("/public/.env", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		response, _ = get("/public/docs/.hidden", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+		response, _ = get("/public/../public/.env", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	// test directory listing
+	t.Run("test directory listing", func(t *testing.T) {
+		response, body := get("/public/docs", nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Contains(t, body, `<a href="/public/docs/readme.txt">readme.txt</a>`)
+		assert.NotContains(t, body, ".hidden")
+	})
+
+	// test spa fallback
+	t.Run("test spa fallback", func(t *testing.T) {
+		response, body := get("/app/orders/12", map[string]string{"Accept": "text/html"})
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "<div id=app></div>", body)
+
+		response, _ = get("/app/assets/logo.svg", nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		// asset yang tidak ada tetap 404
+		response, _ = get("/app/assets/missing.js", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
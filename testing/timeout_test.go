@@ -0,0 +1,143 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"go_fiber/middleware"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTimeoutApp() *fiber.App {
+	app := fiber.New()
+	app.Use(middleware.NewTimeoutMiddleware(middleware.TimeoutConfig{
+		Default: 50 * time.Millisecond,
+		Routes: []middleware.RouteTimeout{
+			{Method: http.MethodGet, Path: "/slow/:id", Timeout: 500 * time.Millisecond},
+		},
+	}).Handle)
+
+	// handler yang memperhatikan context
+	app.Get("/wait", func(ctx *fiber.Ctx) error {
+		select {
+		case <-ctx.UserContext().Done():
+			return ctx.UserContext().Err()
+		case <-time.After(time.Second):
+			return ctx.SendString("done")
+		}
+	})
+	// handler yang mengabaikan context
+	app.Get("/sleep", func(ctx *fiber.Ctx) error {
+		time.Sleep(100 * time.Millisecond)
+		return ctx.SendString("done")
+	})
+	app.Get("/slow/:id", func(ctx *fiber.Ctx) error {
+		time.Sleep(100 * time.Millisecond)
+		return ctx.SendString("done " + ctx.Params("id"))
+	})
+	app.Get("/deadline", func(ctx *fiber.Ctx) error {
+		deadline, ok := ctx.UserContext().Deadline()
+		if !ok {
+			return fiber.ErrInternalServerError
+		}
+		return ctx.SendString(time.Until(deadline).Round(50 * time.Millisecond).String())
+	})
+	app.Get("/canceled", func(ctx *fiber.Ctx) error {
+		return context.Canceled
+	})
+	return app
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	app := newTimeoutApp()
+	logs := &bytes.Buffer{}
+	log.SetOutput(logs)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	// test handler dibatalkan lewat context
+	t.Run("test timeout context cancellation", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/wait", nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusGatewayTimeout, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		responseBody := map[string]any{}
+		assert.Nil(t, json.Unmarshal(body, &responseBody))
+		assert.Equal(t, "gateway timeout", responseBody["status"])
+		assert.Contains(t, logs.String(), "route timed out GET /wait")
+	})
+
+	// test handler yang mengabaikan context tetap mendapat 504
+	t.Run("test timeout handler ignoring context", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/sleep", nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusGatewayTimeout, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.NotContains(t, string(body), "done")
+	})
+
+	// test timeout per route lebih panjang dari default
+	t.Run("test timeout per route", func(t *testing.T) {
+		logs.Reset()
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/slow/7", nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, "done 7", string(body))
+		assert.Empty(t, logs.String())
+	})
+
+	// test handler mendapat context dengan deadline
+	t.Run("test timeout deadline propagated", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/deadline", nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, "50ms", string(body))
+	})
+
+	// test context dibatalkan menjadi 503
+	t.Run("test timeout canceled", func(t *testing.T) {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/canceled", nil), -1)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+	})
+}
+
+func TestTimeoutConfig(t *testing.T) {
+	config := viper.New()
+	config.SetConfigType("json")
+	assert.Nil(t, config.ReadConfig(strings.NewReader(`{"timeouts": {"default": "3s", "server": "10s", "routes": [{"method": "POST", "path": "/upload-file", "timeout": "30s"}]}}`)))
+
+	var timeoutConfig middleware.TimeoutConfig
+	assert.Nil(t, config.UnmarshalKey("timeouts", &timeoutConfig))
+	assert.Equal(t, 3*time.Second, timeoutConfig.Default)
+	assert.Equal(t, 30*time.Second, timeoutConfig.Routes[0].Timeout)
+	assert.Equal(t, 30*time.Second, timeoutConfig.Longest())
+	assert.Equal(t, 10*time.Second, timeoutConfig.Server)
+
+	// test hanya route yang lebih lama dari timeout server mendapat deadline koneksi sendiri
+	header := &fasthttp.RequestHeader{}
+	header.SetMethod(http.MethodPost)
+	header.SetRequestURI("/upload-file?name=a")
+	requestConfig := timeoutConfig.RequestConfig(header)
+	assert.Equal(t, 31*time.Second, requestConfig.ReadTimeout)
+	assert.Equal(t, 31*time.Second, requestConfig.WriteTimeout)
+
+	header.SetMethod(http.MethodGet)
+	assert.Equal(t, fasthttp.RequestConfig{}, timeoutConfig.RequestConfig(header))
+}
@@ -0,0 +1,232 @@
+package testing
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/middleware"
+	"go_fiber/server"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// generate certificate, jika parent nil maka certificate self-signed (CA)
+func generateCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, []byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"go_fiber"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:     []string{"localhost"},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	assert.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.Nil(t, err)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	assert.Nil(t, err)
+
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	return cert, key, certPem, keyPem
+}
+
+// test cert reloader
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+
+	_, _, certPem, keyPem := generateCertificate(t, "first", nil, nil)
+	assert.Nil(t, os.WriteFile(certFile, certPem, 0600))
+	assert.Nil(t, os.WriteFile(keyFile, keyPem, 0600))
+
+	reloader, err := server.NewCertReloader(certFile, keyFile, 0)
+	assert.Nil(t, err)
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.Nil(t, err)
+	leaf, _ := x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "first", leaf.Subject.CommonName)
+
+	// rotate certificate
+	_, _, certPem, keyPem = generateCertificate(t, "second", nil, nil)
+	assert.Nil(t, os.WriteFile(certFile, certPem, 0600))
+	assert.Nil(t, os.WriteFile(keyFile, keyPem, 0600))
+	future := time.Now().Add(time.Minute)
+	assert.Nil(t, os.Chtimes(certFile, future, future))
+
+	cert, err = reloader.GetCertificate(nil)
+	assert.Nil(t, err)
+	leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	assert.Equal(t, "second", leaf.Subject.CommonName)
+}
+
+// test mutual tls
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey, caPem, _ := generateCertificate(t, "test-ca", nil, nil)
+	_, _, serverPem, serverKeyPem := generateCertificate(t, "localhost", caCert, caKey)
+	_, _, clientPem, clientKeyPem := generateCertificate(t, "client-one", caCert, caKey)
+
+	files := map[string][]byte{"ca.crt": caPem, "server.crt": serverPem, "server.key": serverKeyPem}
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), content, 0600))
+	}
+
+	tlsConfig, err := server.NewTLSConfig(server.TLSConfig{
+		Enabled:      true,
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		MinVersion:   "1.2",
+		ClientAuth:   "require",
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	})
+	assert.Nil(t, err)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(middleware.ClientCertMiddleware)
+	app.Get("/whoami", func(ctx *fiber.Ctx) error {
+		return ctx.SendString(middleware.GetClientCertSubject(ctx))
+	})
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	assert.Nil(t, err)
+	go app.Listener(listener)
+	defer app.Shutdown()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM(caPem)
+	url := "https://" + listener.Addr().String() + "/whoami"
+
+	// test with client certificate
+	t.Run("test with client certificate", func(t *testing.T) {
+		clientCert, err := tls.X509KeyPair(clientPem, clientKeyPem)
+		assert.Nil(t, err)
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      rootCAs,
+			Certificates: []tls.Certificate{clientCert},
+		}}}
+		response, err := client.Get(url)
+		assert.Nil(t, err)
+		defer response.Body.Close()
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "CN=client-one,O=go_fiber", string(body))
+	})
+
+	// test without client certificate -> handshake ditolak
+	t.Run("test without client certificate", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}}}
+		_, err := client.Get(url)
+		assert.NotNil(t, err)
+	})
+}
+
+// test client_auth=request, certificate self-signed tidak menjadi identitas
+func TestClientCertUnverified(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey, caPem, _ := generateCertificate(t, "test-ca", nil, nil)
+	_, _, serverPem, serverKeyPem := generateCertificate(t, "localhost", caCert, caKey)
+	_, _, intruderPem, intruderKeyPem := generateCertificate(t, "admin", nil, nil)
+
+	files := map[string][]byte{"ca.crt": caPem, "server.crt": serverPem, "server.key": serverKeyPem}
+	for name, content := range files {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), content, 0600))
+	}
+
+	tlsConfig, err := server.NewTLSConfig(server.TLSConfig{
+		Enabled:      true,
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		MinVersion:   "1.2",
+		ClientAuth:   "request",
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	})
+	assert.Nil(t, err)
+
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	app.Use(middleware.ClientCertMiddleware)
+	app.Get("/whoami", func(ctx *fiber.Ctx) error {
+		return ctx.SendString(middleware.GetClientCertSubject(ctx))
+	})
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	assert.Nil(t, err)
+	go app.Listener(listener)
+	defer app.Shutdown()
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AppendCertsFromPEM(caPem)
+	intruderCert, err := tls.X509KeyPair(intruderPem, intruderKeyPem)
+	assert.Nil(t, err)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{intruderCert},
+	}}}
+	response, err := client.Get("https://" + listener.Addr().String() + "/whoami")
+	assert.Nil(t, err)
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(response.Body)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "", string(body))
+}
+
+// test tls config validation
+func TestTLSConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	_, _, certPem, keyPem := generateCertificate(t, "localhost", nil, nil)
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+	assert.Nil(t, os.WriteFile(certFile, certPem, 0600))
+	assert.Nil(t, os.WriteFile(keyFile, keyPem, 0600))
+
+	_, err := server.NewTLSConfig(server.TLSConfig{CertFile: certFile, KeyFile: keyFile, MinVersion: "0.9"})
+	assert.NotNil(t, err)
+
+	_, err = server.NewTLSConfig(server.TLSConfig{CertFile: certFile, KeyFile: keyFile, CipherSuites: []string{"TLS_FAKE"}})
+	assert.NotNil(t, err)
+
+	_, err = server.NewTLSConfig(server.TLSConfig{CertFile: certFile, KeyFile: keyFile, ClientAuth: "require"})
+	assert.NotNil(t, err)
+}
+
+// test http -> https redirect
+func TestHTTPSRedirect(t *testing.T) {
+	app := server.NewHTTPSRedirectApp("8443")
+
+	request := httptest.NewRequest(http.MethodGet, "http://example.com:8080/hello?name=reo", nil)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusPermanentRedirect, response.StatusCode)
+	assert.Equal(t, "https://example.com:8443/hello?name=reo", response.Header.Get("Location"))
+}
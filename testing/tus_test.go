@@ -0,0 +1,260 @@
+package testing
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/storage"
+	"go_fiber/upload"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTusApp(t *testing.T, config upload.TusConfig) (*fiber.App, *upload.TusStore, string) {
+	target := t.TempDir()
+	config.Directory = t.TempDir()
+	store := upload.NewTusStore(config, storage.NewLocalStorage(target))
+
+	app := fiber.New(fiber.Config{StreamRequestBody: true})
+	app.Use(middleware.NewBodyLimitMiddleware(middleware.BodyLimitConfig{
+		Default: 1024,
+		Routes:  []middleware.RouteBodyLimit{{Method: http.MethodPatch, Path: "/uploads/:id", Limit: 1024, Stream: true}},
+	}).Handle)
+	loginUsers(t, app)
+	Routes.NewTusRoutes(app, handler.NewTusHandler(store))
+	return app, store, target
+}
+
+// request tus sebagai reo
+func tusRequest(t *testing.T, app *fiber.App, method string, target string, body string, headers map[string]string) *http.Response {
+	request := loginAs(httptest.NewRequest(method, target, strings.NewReader(body)), "reo@example.com")
+	request.Header.Set("Tus-Resumable", handler.TusVersion)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func createTusUpload(t *testing.T, app *fiber.App, length int, filename string) string {
+	response := tusRequest(t, app, http.MethodPost, "/uploads", "", map[string]string{
+		"Upload-Length":   strconv.Itoa(length),
+		"Upload-Metadata": "filename " + base64.StdEncoding.EncodeToString([]byte(filename)) + ",filetype " + base64.StdEncoding.EncodeToString([]byte("text/plain")),
+	})
+	assert.Equal(t, http.StatusCreated, response.StatusCode)
+	assert.NotEmpty(t, response.Header.Get("Upload-Expires"))
+
+	location := response.Header.Get("Location")
+	assert.True(t, strings.HasPrefix(location, "http://example.com/uploads/"))
+	return strings.TrimPrefix(location, "http://example.com")
+}
+
+func patchChunk(t *testing.T, app *fiber.App, location string, offset int, chunk string, checksum string) *http.Response {
+	headers := map[string]string{
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": strconv.Itoa(offset),
+	}
+	if checksum != "" {
+		headers["Upload-Checksum"] = checksum
+	}
+	return tusRequest(t, app, http.MethodPatch, location, chunk, headers)
+}
+
+func sha1Checksum(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return "sha1 " + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func TestTusProtocol(t *testing.T) {
+	app, store, target := newTusApp(t, upload.TusConfig{MaxSize: 4096})
+
+	// test informasi server
+	t.Run("test tus options", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodOptions, "/uploads", nil)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, "1.0.0", response.Header.Get("Tus-Version"))
+		assert.Equal(t, "4096", response.Header.Get("Tus-Max-Size"))
+		assert.Contains(t, response.Header.Get("Tus-Extension"), "checksum")
+		assert.Equal(t, "md5,sha1,sha256", response.Header.Get("Tus-Checksum-Algorithm"))
+	})
+
+	// test versi protokol tidak didukung
+	t.Run("test tus version required", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/uploads", nil)
+		request.Header.Set("Upload-Length", "10")
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusPreconditionFailed, response.StatusCode)
+	})
+
+	// test upload melebihi max size
+	t.Run("test tus max size", func(t *testing.T) {
+		response := tusRequest(t, app, http.MethodPost, "/uploads", "", map[string]string{"Upload-Length": "4097"})
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+
+	// test upload dalam beberapa chunk sampai selesai
+	t.Run("test tus resumable upload", func(t *testing.T) {
+		location := createTusUpload(t, app, 11, "halo.txt")
+
+		response := patchChunk(t, app, location, 0, "hello ", sha1Checksum("hello "))
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, "6", response.Header.Get("Upload-Offset"))
+
+		// offset untuk melanjutkan upload
+		response = tusRequest(t, app, http.MethodHead, location, "", nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "6", response.Header.Get("Upload-Offset"))
+		assert.Equal(t, "11", response.Header.Get("Upload-Length"))
+		assert.Equal(t, "no-store", response.Header.Get("Cache-Control"))
+		assert.Contains(t, response.Header.Get("Upload-Metadata"), "filename "+base64.StdEncoding.EncodeToString([]byte("halo.txt")))
+
+		// offset salah
+		response = patchChunk(t, app, location, 3, "world", "")
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+		// checksum salah, chunk dibuang
+		response = patchChunk(t, app, location, 6, "world", sha1Checksum("w0rld"))
+		assert.Equal(t, handler.StatusChecksumMismatch, response.StatusCode)
+		response = tusRequest(t, app, http.MethodHead, location, "", nil)
+		assert.Equal(t, "6", response.Header.Get("Upload-Offset"))
+
+		// chunk melebihi Upload-Length
+		response = patchChunk(t, app, location, 6, "world!", "")
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+		response = patchChunk(t, app, location, 6, "world", sha1Checksum("world"))
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, "11", response.Header.Get("Upload-Offset"))
+
+		completed, err := store.Get(location[strings.LastIndex(location, "/")+1:])
+		assert.Nil(t, err)
+		assert.Equal(t, "halo.txt", completed.File.Name)
+		saved, err := os.ReadFile(filepath.Join(target, completed.File.ID))
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", string(saved))
+
+		// upload yang sudah selesai tidak bisa ditambah
+		response = patchChunk(t, app, location, 11, "!", "")
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+
+	// test content type chunk salah
+	t.Run("test tus patch content type", func(t *testing.T) {
+		location := createTusUpload(t, app, 5, "salah.txt")
+		response := tusRequest(t, app, http.MethodPatch, location, "hello", map[string]string{"Upload-Offset": "0"})
+		assert.Equal(t, http.StatusUnsupportedMediaType, response.StatusCode)
+	})
+
+	// test upload hanya bisa dipakai user yang membuatnya
+	t.Run("test tus upload owner", func(t *testing.T) {
+		location := createTusUpload(t, app, 5, "milik-reo.txt")
+
+		request := httptest.NewRequest(http.MethodHead, location, nil)
+		request.Header.Set("Tus-Resumable", handler.TusVersion)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+		for _, method := range []string{http.MethodHead, http.MethodDelete} {
+			request := loginAs(httptest.NewRequest(method, location, nil), "budi@example.com")
+			request.Header.Set("Tus-Resumable", handler.TusVersion)
+			response, err := app.Test(request)
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusNotFound, response.StatusCode, method)
+		}
+		response = tusRequest(t, app, http.MethodHead, location, "", nil)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	// test termination
+	t.Run("test tus termination", func(t *testing.T) {
+		location := createTusUpload(t, app, 5, "hapus.txt")
+
+		response := tusRequest(t, app, http.MethodDelete, location, "", nil)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+
+		response = tusRequest(t, app, http.MethodHead, location, "", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+
+	// test id upload tidak valid
+	t.Run("test tus unknown upload", func(t *testing.T) {
+		response := tusRequest(t, app, http.MethodHead, "/uploads/..%2F..%2Fconfig", "", nil)
+		assert.Equal(t, http.StatusNotFound, response.StatusCode)
+	})
+}
+
+func TestTusExpiration(t *testing.T) {
+	app, store, _ := newTusApp(t, upload.TusConfig{Expiration: 50 * time.Millisecond})
+	location := createTusUpload(t, app, 10, "lama.txt")
+	patchChunk(t, app, location, 0, "halo", "")
+
+	time.Sleep(60 * time.Millisecond)
+
+	// test upload kadaluarsa
+	response := tusRequest(t, app, http.MethodHead, location, "", nil)
+	assert.Equal(t, http.StatusGone, response.StatusCode)
+
+	// test cleanup upload yang ditinggalkan
+	removed, err := store.Cleanup(time.Now())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, removed)
+
+	response = tusRequest(t, app, http.MethodHead, location, "", nil)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+// test lock upload berlaku antar store di direktori yang sama, seperti proses prefork
+func TestTusLockAcrossStores(t *testing.T) {
+	config := upload.TusConfig{Directory: t.TempDir()}
+	first := upload.NewTusStore(config, storage.NewLocalStorage(t.TempDir()))
+	second := upload.NewTusStore(config, storage.NewLocalStorage(t.TempDir()))
+
+	created, err := first.Create(10, map[string]string{"filename": "lock.txt"}, "")
+	assert.Nil(t, err)
+
+	// PATCH yang masih berjalan memegang lock sampai body selesai dibaca
+	reader, writer := io.Pipe()
+	written := make(chan error)
+	go func() {
+		_, err := first.WriteChunk(context.Background(), created.ID, 0, reader, nil)
+		written <- err
+	}()
+	writer.Write([]byte("halo"))
+
+	terminated := make(chan error)
+	go func() {
+		terminated <- second.Terminate(created.ID)
+	}()
+	select {
+	case <-terminated:
+		t.Fatal("terminate did not wait for the running chunk")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	writer.Close()
+	assert.Nil(t, <-written)
+	assert.Nil(t, <-terminated)
+
+	_, err = first.Get(created.ID)
+	assert.ErrorIs(t, err, upload.ErrUploadNotFound)
+	entries, _ := os.ReadDir(config.Directory)
+	assert.Empty(t, entries)
+}
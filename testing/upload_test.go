@@ -0,0 +1,147 @@
+package testing
+
+import (
+	"bytes"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/storage"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const uploadLimit = 64 * 1024
+
+func newUploadApp(t *testing.T) (*fiber.App, string) {
+	directory := t.TempDir()
+	testHandler := handler.NewTestHandler(validator.New())
+	testHandler.Storage = storage.NewLocalStorage(directory)
+
+	app := fiber.New(fiber.Config{
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
+	app.Use(middleware.NewBodyLimitMiddleware(middleware.BodyLimitConfig{
+		Default: 1024,
+		Routes: []middleware.RouteBodyLimit{
+			{Method: http.MethodPost, Path: "/upload-file", Limit: uploadLimit, Stream: true},
+		},
+	}).Handle)
+	app.Post("/upload-file", testHandler.MultiPartFormHandler)
+	app.Post("/login", testHandler.RequestBodyHandler)
+	return app, directory
+}
+
+// jalankan app di listener tcp, app.Test tidak bisa mengirim body chunked
+func serveApp(t *testing.T, app *fiber.App) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go app.Listener(listener)
+	t.Cleanup(func() { app.Shutdown() })
+	return "http://" + listener.Addr().String()
+}
+
+// kirim request dengan transfer-encoding chunked
+func postChunked(t *testing.T, url string, contentType string, body io.Reader) *http.Response {
+	request, err := http.NewRequest(http.MethodPost, url, io.NopCloser(body))
+	assert.Nil(t, err)
+	request.Header.Add("Content-Type", contentType)
+	request.ContentLength = -1
+
+	response, err := http.DefaultClient.Do(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func TestStreamingUpload(t *testing.T) {
+	app, directory := newUploadApp(t)
+	baseUrl := serveApp(t, app)
+	var uploadedId string
+
+	// test upload file tersimpan di storage
+	t.Run("test upload file", func(t *testing.T) {
+		content := bytes.Repeat([]byte("fiber"), 10*1024)
+		body, contentType := multipartBody(t, "file", "../../contoh.txt", content)
+
+		request := httptest.NewRequest(http.MethodPost, "/upload-file", body)
+		request.Header.Add("Content-Type", contentType)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		responseBody := decodeApiResponse(t, response)
+		data := responseBody["data"].(map[string]any)
+		assert.Equal(t, "contoh.txt", data["name"])
+		assert.Equal(t, float64(len(content)), data["size"])
+
+		uploadedId = data["id"].(string)
+		assert.Len(t, data["checksum"], 64)
+		saved, err := os.ReadFile(filepath.Join(directory, uploadedId))
+		assert.Nil(t, err)
+		assert.Equal(t, content, saved)
+	})
+
+	// test upload melebihi limit dengan content-length
+	t.Run("test upload too large", func(t *testing.T) {
+		body, contentType := multipartBody(t, "file", "besar.txt", bytes.Repeat([]byte("x"), uploadLimit+1))
+
+		request := httptest.NewRequest(http.MethodPost, "/upload-file", body)
+		request.Header.Add("Content-Type", contentType)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+		responseBody := decodeApiResponse(t, response)
+		assert.Equal(t, "request entity too large", responseBody["status"])
+		assertStoredFiles(t, directory, uploadedId)
+	})
+
+	// test upload chunked tanpa content-length dihentikan saat melebihi limit
+	t.Run("test upload chunked too large", func(t *testing.T) {
+		body, contentType := multipartBody(t, "file", "chunked.txt", bytes.Repeat([]byte("x"), 2*uploadLimit))
+
+		response := postChunked(t, baseUrl+"/upload-file", contentType, body)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+
+		entries, _ := os.ReadDir(directory)
+		for _, entry := range entries {
+			assert.NotEqual(t, "chunked.txt", entry.Name())
+			assert.False(t, strings.HasPrefix(entry.Name(), ".upload-"))
+		}
+	})
+
+	// test tanpa field file
+	t.Run("test upload without file", func(t *testing.T) {
+		body, contentType := multipartBody(t, "dokumen", "contoh.txt", []byte("fiber"))
+
+		request := httptest.NewRequest(http.MethodPost, "/upload-file", body)
+		request.Header.Add("Content-Type", contentType)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+	})
+
+	// test route non-streaming memakai limit default
+	t.Run("test default body limit", func(t *testing.T) {
+		payload := `{"email":"reoshby@gmail.com","password":"` + strings.Repeat("x", 2048) + `"}`
+
+		response := postChunked(t, baseUrl+"/login", fiber.MIMEApplicationJSON, strings.NewReader(payload))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+	})
+
+	// test route non-streaming di bawah limit tetap berjalan
+	t.Run("test body under default limit", func(t *testing.T) {
+		payload := `{"email":"reoshby@gmail.com","password":"123456"}`
+
+		response := postChunked(t, baseUrl+"/login", fiber.MIMEApplicationJSON, strings.NewReader(payload))
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+	})
+}
@@ -0,0 +1,221 @@
+package testing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/expvar"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/Routes"
+	"go_fiber/handler"
+	"go_fiber/middleware"
+	"go_fiber/orders"
+	"go_fiber/users"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newUserApp(t *testing.T) (*fiber.App, users.Store) {
+	store := users.NewFileStore(t.TempDir())
+	validate := validator.New()
+
+	testHandler := handler.NewTestHandler(validate)
+	testHandler.Users = store
+	testHandler.UserConfig = testUserConfig
+
+	app := fiber.New()
+	app.Use(middleware.NewUserAuthMiddleware(store, testUserConfig).Handle)
+	Routes.NewTestHandlerRoutes(app, testHandler)
+	Routes.NewUserRoutes(app, handler.NewUserHandler(store, testUserConfig, validate))
+	return app, store
+}
+
+func registerUser(t *testing.T, app *fiber.App, username string, name string) (*http.Response, string) {
+	body := `{"username":"` + username + `","password":"rahasia","name":"` + name + `"}`
+	request := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+	request.Header.Set("Content-Type", fiber.MIMEApplicationJSON)
+
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+	content, _ := io.ReadAll(response.Body)
+	return response, string(content)
+}
+
+func TestUserManagement(t *testing.T) {
+	app, store := newUserApp(t)
+	ids := map[string]string{}
+
+	// test register menyimpan user, response tanpa password
+	t.Run("test register", func(t *testing.T) {
+		for _, user := range [][2]string{{"admin@example.com", "Admin"}, {"reo@example.com", "Reo Sahobby"}, {"budi@example.com", "Budi"}} {
+			response, body := registerUser(t, app, user[0], user[1])
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.NotContains(t, body, "rahasia")
+			assert.NotContains(t, body, "password")
+		}
+
+		response, _ := registerUser(t, app, "REO@example.com", "Reo Lain")
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+	})
+
+	// test /me dengan login
+	t.Run("test me", func(t *testing.T) {
+		code, _ := userRequest(t, app, http.MethodGet, "/me", "", "", "")
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		code, _ = userRequest(t, app, http.MethodGet, "/me", "", "reo@example.com", "salah123")
+		assert.Equal(t, http.StatusUnauthorized, code)
+		code, _ = userRequest(t, app, http.MethodGet, "/me", "", "tidak-ada@example.com", "rahasia")
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		// skema selain Basic diabaikan, request lanjut tanpa login
+		request := httptest.NewRequest(http.MethodGet, "/me", nil)
+		request.Header.Set("Authorization", "Bearer secret-token")
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, "login required", decodeApiResponse(t, response)["message"])
+
+		for _, username := range []string{"admin@example.com", "reo@example.com", "budi@example.com"} {
+			code, body := userRequest(t, app, http.MethodGet, "/me", "", username, "rahasia")
+			assert.Equal(t, http.StatusOK, code)
+			data := body["data"].(map[string]any)
+			assert.Equal(t, username, data["username"])
+			ids[username] = data["id"].(string)
+		}
+		// register tidak memberi role admin, admin dibuat lewat PromoteAdmin
+		_, body := userRequest(t, app, http.MethodGet, "/me", "", "admin@example.com", "rahasia")
+		assert.Equal(t, "user", body["data"].(map[string]any)["role"])
+		_, err = users.PromoteAdmin(context.Background(), store, "admin@example.com")
+		assert.Nil(t, err)
+		_, body = userRequest(t, app, http.MethodGet, "/me", "", "admin@example.com", "rahasia")
+		assert.Equal(t, "admin", body["data"].(map[string]any)["role"])
+
+		_, err = users.PromoteAdmin(context.Background(), store, "tidak-ada@example.com")
+		assert.ErrorIs(t, err, users.ErrNotFound)
+	})
+
+	// test hanya admin yang bisa melihat daftar user
+	t.Run("test list users", func(t *testing.T) {
+		code, _ := userRequest(t, app, http.MethodGet, "/users", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, body := userRequest(t, app, http.MethodGet, "/users?per_page=2", "", "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		data := body["data"].(map[string]any)
+		assert.Equal(t, float64(3), data["total"])
+		assert.Equal(t, float64(2), data["total_pages"])
+		assert.Len(t, data["items"], 2)
+
+		_, body = userRequest(t, app, http.MethodGet, "/users?name=reo", "", "admin@example.com", "rahasia")
+		assert.Equal(t, []string{ids["reo@example.com"]}, pageIds(body))
+		_, body = userRequest(t, app, http.MethodGet, "/users?email=BUDI", "", "admin@example.com", "rahasia")
+		assert.Equal(t, []string{ids["budi@example.com"]}, pageIds(body))
+	})
+
+	// test user hanya bisa melihat dirinya sendiri
+	t.Run("test get user", func(t *testing.T) {
+		code, _ := userRequest(t, app, http.MethodGet, "/users/"+ids["budi@example.com"], "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = userRequest(t, app, http.MethodGet, "/users/"+ids["reo@example.com"], "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = userRequest(t, app, http.MethodGet, "/users/"+ids["budi@example.com"], "", "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = userRequest(t, app, http.MethodGet, "/users/tidak-ada", "", "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusNotFound, code)
+	})
+
+	// test update, role hanya bisa diubah admin
+	t.Run("test update user", func(t *testing.T) {
+		target := "/users/" + ids["reo@example.com"]
+
+		code, body := userRequest(t, app, http.MethodPatch, target, `{"name":"Reo S","password":"baru123"}`, "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "Reo S", body["data"].(map[string]any)["name"])
+
+		code, _ = userRequest(t, app, http.MethodGet, "/me", "", "reo@example.com", "rahasia")
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		code, _ = userRequest(t, app, http.MethodPatch, target, `{"role":"admin"}`, "reo@example.com", "baru123")
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = userRequest(t, app, http.MethodPatch, target, `{"username":"budi@example.com"}`, "reo@example.com", "baru123")
+		assert.Equal(t, http.StatusConflict, code)
+		code, _ = userRequest(t, app, http.MethodPatch, target, `{"username":"bukan-email"}`, "reo@example.com", "baru123")
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, body = userRequest(t, app, http.MethodPatch, target, `{"role":"admin"}`, "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "admin", body["data"].(map[string]any)["role"])
+	})
+
+	// test soft delete lalu restore oleh admin
+	t.Run("test delete and restore", func(t *testing.T) {
+		target := "/users/" + ids["budi@example.com"]
+
+		code, body := userRequest(t, app, http.MethodDelete, target, "", "budi@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, body["data"].(map[string]any)["deleted_at"])
+
+		code, _ = userRequest(t, app, http.MethodGet, "/me", "", "budi@example.com", "rahasia")
+		assert.Equal(t, http.StatusUnauthorized, code)
+		response, _ := registerUser(t, app, "budi@example.com", "Budi Lain")
+		assert.Equal(t, http.StatusConflict, response.StatusCode)
+
+		_, body = userRequest(t, app, http.MethodGet, "/users?email=budi", "", "admin@example.com", "rahasia")
+		assert.Empty(t, pageIds(body))
+		_, body = userRequest(t, app, http.MethodGet, "/users?email=budi&deleted=true", "", "admin@example.com", "rahasia")
+		assert.Equal(t, []string{ids["budi@example.com"]}, pageIds(body))
+
+		code, _ = userRequest(t, app, http.MethodPost, target+"/restore", "", "", "")
+		assert.Equal(t, http.StatusUnauthorized, code)
+		code, _ = userRequest(t, app, http.MethodPost, target+"/restore", "", "admin@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+		code, _ = userRequest(t, app, http.MethodGet, "/me", "", "budi@example.com", "rahasia")
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+// test marker username kosong dari crash sebelum id ditulis tidak menahan username
+func TestUsernameEmptyMarker(t *testing.T) {
+	ctx := context.Background()
+	directory := t.TempDir()
+	store := users.NewFileStore(directory)
+
+	sum := sha256.Sum256([]byte("reo@example.com"))
+	assert.Nil(t, os.MkdirAll(filepath.Join(directory, "usernames"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "usernames", hex.EncodeToString(sum[:])), nil, 0o644))
+
+	user, err := testUserConfig.NewUser("Reo@example.com", "rahasia", "Reo")
+	assert.Nil(t, err)
+	created, err := store.Create(ctx, user)
+	assert.Nil(t, err)
+
+	found, err := store.GetByUsername(ctx, "reo@example.com")
+	assert.Nil(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	_, err = store.Create(ctx, user)
+	assert.ErrorIs(t, err, users.ErrUsernameTaken)
+}
+
+// test /debug/vars hanya untuk admin
+func TestMetricsAdminOnly(t *testing.T) {
+	app := fiber.New()
+	registerOrderRoutes(t, app, orders.NewMemoryRepository())
+	app.Use("/debug/vars", middleware.RequireAdmin, expvar.New())
+
+	code, _ := userRequest(t, app, http.MethodGet, "/debug/vars", "", "", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = userRequest(t, app, http.MethodGet, "/debug/vars", "", "reo@example.com", "rahasia")
+	assert.Equal(t, http.StatusForbidden, code)
+	code, body := userRequest(t, app, http.MethodGet, "/debug/vars", "", "admin@example.com", "rahasia")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "memstats")
+}
@@ -0,0 +1,110 @@
+package testing
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/stretchr/testify/assert"
+	"go_fiber/middleware"
+	"go_fiber/public"
+	"go_fiber/view"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// test layout, partials, flash dan helper view
+func TestViewLayoutAndHelpers(t *testing.T) {
+	app := fiber.New(fiber.Config{
+		Views:             view.NewEngine(),
+		PassLocalsToViews: true,
+	})
+	assets := view.NewAssetHasher(public.Files, "/public")
+	app.Use(requestid.New(requestid.Config{ContextKey: view.RequestIdKey}))
+	app.Use(middleware.NewViewGlobalsMiddleware(view.Config{AppName: "go-fiber", DateFormat: "2006-01-02"}, assets).Handle)
+
+	app.Get("/page", func(ctx *fiber.Ctx) error {
+		return ctx.Render("index", fiber.Map{
+			"title":      "Page",
+			"header":     "Belajar GOlang Fiber",
+			"renderedAt": "2024-03-01T10:00:00Z",
+		}, view.DefaultLayout)
+	})
+	app.Post("/flash", func(ctx *fiber.Ctx) error {
+		view.SetFlash(ctx, "success", "data tersimpan")
+		return ctx.Redirect("/page")
+	})
+
+	// test render dengan layout dan helper
+	t.Run("test render layout", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/page", nil)
+		request.Header.Add("Accept", "text/html")
+
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+
+		body, _ := io.ReadAll(response.Body)
+		html := string(body)
+		assert.Contains(t, html, "<title>Page | go-fiber</title>")
+		assert.Contains(t, html, `<a class="brand" href="/">go-fiber</a>`)
+		assert.Contains(t, html, "<h1>Belajar GOlang Fiber</h1>")
+		assert.Contains(t, html, "2024-03-01")
+		assert.Contains(t, html, "request "+response.Header.Get("X-Request-Id"))
+		assert.Regexp(t, `href="/public/css/app.css\?v=[0-9a-f]{10}"`, html)
+	})
+
+	// test flash message tampil sekali
+	t.Run("test flash message", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/flash", nil)
+		response, err := app.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusFound, response.StatusCode)
+		cookies := response.Cookies()
+		assert.NotEmpty(t, cookies)
+
+		request = httptest.NewRequest(http.MethodGet, "/page", nil)
+		request.Header.Add("Accept", "text/html")
+		for _, cookie := range cookies {
+			request.AddCookie(cookie)
+		}
+		response, err = app.Test(request)
+		assert.Nil(t, err)
+
+		body, _ := io.ReadAll(response.Body)
+		assert.Contains(t, string(body), `<div class="flash flash-success" role="alert">data tersimpan</div>`)
+
+		// cookie flash dihapus setelah tampil
+		cleared := false
+		for _, cookie := range response.Cookies() {
+			if cookie.Name == "flash" && cookie.Value == "" {
+				cleared = true
+			}
+		}
+		assert.True(t, cleared)
+	})
+}
+
+// test dev mode membaca template dari disk dan reload setiap render
+func TestViewDevEngineReload(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "page.mustache"), []byte("<p>first</p>"), 0644))
+
+	app := fiber.New(fiber.Config{Views: view.NewDevEngine(dir)})
+	app.Get("/", func(ctx *fiber.Ctx) error {
+		return ctx.Render("page", fiber.Map{})
+	})
+
+	render := func() string {
+		response, err := app.Test(httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(response.Body)
+		return string(body)
+	}
+
+	assert.Equal(t, "<p>first</p>", render())
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "page.mustache"), []byte("<p>second</p>"), 0644))
+	assert.Equal(t, "<p>second</p>", render())
+}
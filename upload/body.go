@@ -0,0 +1,67 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gofiber/fiber/v2"
+	"io"
+)
+
+const bodyKey = "uploadBody"
+
+var ErrBodyTooLarge = errors.New("request body too large")
+
+// LimitedBody counts bytes read from the request stream and fails with
+// ErrBodyTooLarge once more than Limit bytes have been read.
+type LimitedBody struct {
+	reader   io.Reader
+	limit    int64
+	read     int64
+	exceeded bool
+}
+
+func (l *LimitedBody) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+
+	// baca satu byte lebih dari sisa limit untuk tahu apakah body melebihi limit
+	if remaining := l.limit - l.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		l.exceeded = true
+		return n - int(l.read-l.limit), ErrBodyTooLarge
+	}
+	return n, err
+}
+
+func (l *LimitedBody) Exceeded() bool {
+	return l.exceeded
+}
+
+// batasi stream body request, dipanggil middleware body limit untuk route streaming
+func LimitBody(ctx *fiber.Ctx, limit int64) *LimitedBody {
+	body := &LimitedBody{reader: rawBody(ctx), limit: limit}
+	ctx.Locals(bodyKey, body)
+	return body
+}
+
+// Body returns the request body as a stream. With StreamRequestBody enabled the
+// body is read from the connection as the handler consumes it.
+func Body(ctx *fiber.Ctx) io.Reader {
+	if body, ok := ctx.Locals(bodyKey).(*LimitedBody); ok {
+		return body
+	}
+	return rawBody(ctx)
+}
+
+func rawBody(ctx *fiber.Ctx) io.Reader {
+	if stream := ctx.Context().RequestBodyStream(); stream != nil {
+		return stream
+	}
+	return bytes.NewReader(ctx.Body())
+}
@@ -0,0 +1,143 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go_fiber/storage"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// batas ukuran field non-file, field teks dibaca ke memory
+const maxFieldSize = 1 << 20
+
+var (
+	ErrNotMultipart  = errors.New("request is not multipart/form-data")
+	ErrFieldTooLarge = errors.New("multipart field too large")
+	ErrStorage       = errors.New("cant save uploaded file")
+)
+
+// File is an uploaded file that has been written to storage under ID. Name is the
+// original client filename and Checksum the hex SHA-256 of the stored content.
+// VariantSize is the total size of the generated Variants.
+type File struct {
+	ID          string   `json:"id"`
+	Field       string   `json:"field"`
+	Name        string   `json:"name"`
+	ContentType string   `json:"content_type"`
+	Size        int64    `json:"size"`
+	Checksum    string   `json:"checksum"`
+	Owner       string   `json:"owner,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Variants    []string `json:"variants,omitempty"`
+	VariantSize int64    `json:"variant_size,omitempty"`
+}
+
+// id file di storage, nama file dari client tidak dipakai supaya upload dengan nama sama tidak saling timpa
+func NewFileID() string {
+	return uuid.NewString()
+}
+
+// tag dipisah koma, contoh "invoice, 2024"
+func ParseTags(value string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// StreamMultipart reads the multipart body part by part. File parts are written to
+// store as they arrive, so an upload is never held in memory; the other fields are
+// returned as values. Files already saved are removed again when reading fails.
+func StreamMultipart(ctx *fiber.Ctx, store storage.Storage) ([]File, map[string]string, error) {
+	boundary := string(ctx.Request().Header.MultipartFormBoundary())
+	if boundary == "" {
+		return nil, nil, ErrNotMultipart
+	}
+
+	var files []File
+	values := map[string]string{}
+	reader := multipart.NewReader(Body(ctx), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return files, values, nil
+		}
+		if err != nil {
+			removeFiles(ctx, store, files)
+			return nil, nil, err
+		}
+
+		if part.FileName() == "" {
+			value, err := io.ReadAll(io.LimitReader(part, maxFieldSize+1))
+			if err == nil && len(value) > maxFieldSize {
+				err = ErrFieldTooLarge
+			}
+			if err != nil {
+				removeFiles(ctx, store, files)
+				return nil, nil, err
+			}
+			values[part.FormName()] = string(value)
+			continue
+		}
+
+		file := File{
+			ID:          NewFileID(),
+			Field:       part.FormName(),
+			Name:        filepath.Base(part.FileName()),
+			ContentType: part.Header.Get(fiber.HeaderContentType),
+		}
+		checksum := sha256.New()
+		source := &partReader{reader: io.TeeReader(part, checksum)}
+		file.Size, err = store.Save(ctx.UserContext(), file.ID, source)
+		if err != nil {
+			removeFiles(ctx, store, files)
+			return nil, nil, saveError(source, err)
+		}
+		file.Checksum = hex.EncodeToString(checksum.Sum(nil))
+		files = append(files, file)
+	}
+}
+
+// reader part yang mencatat error baca, untuk membedakan error body request dengan error storage
+type partReader struct {
+	reader io.Reader
+	err    error
+}
+
+func (p *partReader) Read(buffer []byte) (int, error) {
+	n, err := p.reader.Read(buffer)
+	if err != nil && err != io.EOF {
+		p.err = err
+	}
+	return n, err
+}
+
+func saveError(source *partReader, err error) error {
+	if source.err != nil {
+		return source.err
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return err
+	}
+	return fmt.Errorf("%w : %w", ErrStorage, err)
+}
+
+func removeFiles(ctx *fiber.Ctx, store storage.Storage, files []File) {
+	for _, file := range files {
+		store.Remove(ctx.UserContext(), file.ID)
+	}
+}
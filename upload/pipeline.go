@@ -0,0 +1,65 @@
+package upload
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// processor membungkus error dengan ErrRejected jika file ditolak karena isinya
+	ErrRejected = errors.New("upload rejected")
+	// processor membungkus error dengan ErrUnavailable jika service yang dibutuhkan sedang mati
+	ErrUnavailable = errors.New("upload processing unavailable")
+	// upload melebihi kuota pemilik file
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	// upload melebihi kuota seluruh server
+	ErrInsufficientStorage = errors.New("insufficient storage")
+)
+
+// Processor post-processes a completed upload, for example generating image variants.
+// It may update file (e.g. Size after rewriting it) and fails the upload by returning an error.
+type Processor interface {
+	Process(ctx context.Context, file *File) error
+}
+
+// Reverter is implemented by processors that leave something behind besides the stored
+// file (variants, scan records, catalogue entries), so a failed upload can be undone.
+// Revert must tolerate side effects that were never made.
+type Reverter interface {
+	Revert(ctx context.Context, file *File) error
+}
+
+// Pipeline runs processors in order on every completed upload. When a processor fails,
+// the processors that ran, including the failing one, are reverted in reverse order.
+// The stored file itself is removed by the caller.
+type Pipeline []Processor
+
+func (p Pipeline) Run(ctx context.Context, file *File) error {
+	for i, processor := range p {
+		if err := processor.Process(ctx, file); err != nil {
+			p[:i+1].Revert(ctx, file)
+			return err
+		}
+	}
+	return nil
+}
+
+// Revert undoes every processor in reverse order, e.g. for a file that was processed
+// before another file of the same upload failed. It continues after errors and returns
+// the first one.
+func (p Pipeline) Revert(ctx context.Context, file *File) error {
+	// upload bisa gagal karena timeout, pembersihan tetap dijalankan
+	ctx = context.WithoutCancel(ctx)
+
+	var first error
+	for i := len(p) - 1; i >= 0; i-- {
+		reverter, ok := p[i].(Reverter)
+		if !ok {
+			continue
+		}
+		if err := reverter.Revert(ctx, file); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
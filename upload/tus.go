@@ -0,0 +1,459 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go_fiber/filelock"
+	"go_fiber/storage"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUploadNotFound    = errors.New("upload not found")
+	ErrUploadExpired     = errors.New("upload expired")
+	ErrOffsetMismatch    = errors.New("upload offset does not match")
+	ErrExceedsLength     = errors.New("chunk exceeds upload length")
+	ErrChecksumMismatch  = errors.New("checksum mismatch")
+	ErrChecksumAlgorithm = errors.New("unsupported checksum algorithm")
+	ErrInvalidMetadata   = errors.New("invalid upload metadata")
+)
+
+var uploadIdPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// algoritma checksum yang didukung untuk header Upload-Checksum
+var checksumAlgorithms = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// TusConfig is loaded from the "tus" section of config.json. Unfinished uploads are
+// kept in Directory and removed once they have not been touched for Expiration.
+type TusConfig struct {
+	Directory       string        `mapstructure:"directory"`
+	MaxSize         int64         `mapstructure:"max_size"`
+	Expiration      time.Duration `mapstructure:"expiration"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// TusUpload is the state of one resumable upload, stored next to its data as JSON.
+type TusUpload struct {
+	ID        string            `json:"id"`
+	Length    int64             `json:"length"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata"`
+	Owner     string            `json:"owner,omitempty"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	File      *File             `json:"file,omitempty"`
+}
+
+// upload selesai ketika seluruh data sudah dipindah ke storage
+func (t *TusUpload) Completed() bool {
+	return t.File != nil
+}
+
+// Checksum is a parsed Upload-Checksum header, verified against each PATCH chunk.
+type Checksum struct {
+	Algorithm string
+	Sum       []byte
+}
+
+// parse header "sha1 <base64>"
+func ParseChecksum(header string) (*Checksum, error) {
+	algorithm, encoded, found := strings.Cut(strings.TrimSpace(header), " ")
+	if _, ok := checksumAlgorithms[algorithm]; !ok || !found {
+		return nil, ErrChecksumAlgorithm
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum : %w", err)
+	}
+	return &Checksum{Algorithm: algorithm, Sum: sum}, nil
+}
+
+// daftar algoritma untuk header Tus-Checksum-Algorithm
+func ChecksumAlgorithms() []string {
+	var algorithms []string
+	for algorithm := range checksumAlgorithms {
+		algorithms = append(algorithms, algorithm)
+	}
+	sort.Strings(algorithms)
+	return algorithms
+}
+
+// parse header Upload-Metadata "key base64,key base64"
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, ErrInvalidMetadata
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, ErrInvalidMetadata
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// encode metadata untuk response HEAD, urut berdasarkan key
+func EncodeMetadata(metadata map[string]string) string {
+	var pairs []string
+	for key, value := range metadata {
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// TusStore keeps unfinished tus uploads on disk and moves finished ones to storage,
+// where Pipeline runs on them like on any other upload.
+//
+// Unfinished uploads are not encrypted, even when the storage is: chunks are appended
+// and rolled back in place, which the segmented encryption does not support. They are
+// only readable by the server user and are removed once finished or expired.
+type TusStore struct {
+	Pipeline Pipeline
+
+	config  TusConfig
+	storage storage.Storage
+}
+
+// function provider
+func NewTusStore(config TusConfig, store storage.Storage) *TusStore {
+	if config.Expiration <= 0 {
+		config.Expiration = 24 * time.Hour
+	}
+
+	return &TusStore{
+		config:  config,
+		storage: store,
+	}
+}
+
+func (t *TusStore) MaxSize() int64 {
+	return t.config.MaxSize
+}
+
+// buat upload baru dengan data kosong
+func (t *TusStore) Create(length int64, metadata map[string]string, owner string) (*TusUpload, error) {
+	if err := os.MkdirAll(t.config.Directory, 0o700); err != nil {
+		return nil, err
+	}
+
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return nil, err
+	}
+
+	upload := &TusUpload{
+		ID:        hex.EncodeToString(buffer),
+		Length:    length,
+		Metadata:  metadata,
+		Owner:     owner,
+		ExpiresAt: time.Now().Add(t.config.Expiration).UTC(),
+	}
+	data, err := os.OpenFile(t.dataPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	data.Close()
+
+	return upload, t.save(upload)
+}
+
+func (t *TusStore) Get(id string) (*TusUpload, error) {
+	upload, err := t.load(id)
+	if err != nil {
+		return nil, err
+	}
+	if !upload.Completed() && time.Now().After(upload.ExpiresAt) {
+		return nil, ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// WriteChunk appends body at offset. When checksum is given the chunk is verified and
+// discarded on mismatch; without it, bytes received before a broken connection are kept.
+// The last chunk moves the upload to storage.
+func (t *TusStore) WriteChunk(ctx context.Context, id string, offset int64, body io.Reader, checksum *Checksum) (*TusUpload, error) {
+	unlock, err := t.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err := t.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Completed() || offset != upload.Offset {
+		return upload, ErrOffsetMismatch
+	}
+
+	data, err := os.OpenFile(t.dataPath(id), os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	defer data.Close()
+	if _, err := data.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var hasher hash.Hash
+	writer := io.Writer(data)
+	if checksum != nil {
+		hasher = checksumAlgorithms[checksum.Algorithm]()
+		writer = io.MultiWriter(data, hasher)
+	}
+
+	// baca satu byte lebih dari sisa length untuk mendeteksi chunk yang kebesaran
+	remaining := upload.Length - upload.Offset
+	written, copyErr := io.Copy(writer, io.LimitReader(body, remaining+1))
+
+	rollback := func(err error) (*TusUpload, error) {
+		if truncateErr := data.Truncate(offset); truncateErr != nil {
+			return nil, truncateErr
+		}
+		return upload, err
+	}
+	switch {
+	case written > remaining:
+		return rollback(ErrExceedsLength)
+	case copyErr != nil && checksum != nil:
+		return rollback(copyErr)
+	case checksum != nil && !bytes.Equal(hasher.Sum(nil), checksum.Sum):
+		return rollback(ErrChecksumMismatch)
+	}
+
+	upload.Offset += written
+	upload.ExpiresAt = time.Now().Add(t.config.Expiration).UTC()
+	if err := t.save(upload); err != nil {
+		return nil, err
+	}
+	if copyErr != nil {
+		return upload, copyErr
+	}
+
+	if upload.Offset == upload.Length {
+		return upload, t.finish(ctx, upload)
+	}
+	return upload, nil
+}
+
+// hapus upload beserta datanya
+func (t *TusStore) Terminate(id string) error {
+	unlock, err := t.lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := t.load(id); err != nil {
+		return err
+	}
+	return t.remove(id)
+}
+
+// Cleanup removes uploads that expired before now, returning how many were removed.
+func (t *TusStore) Cleanup(now time.Time) (int, error) {
+	entries, err := os.ReadDir(t.config.Directory)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		id, found := strings.CutSuffix(entry.Name(), ".info")
+		if !found {
+			continue
+		}
+
+		// cek ulang setelah lock, PATCH yang sedang berjalan bisa memperpanjang expiry
+		unlock, err := t.lock(id)
+		if err != nil {
+			return removed, err
+		}
+		upload, err := t.load(id)
+		if err != nil || now.Before(upload.ExpiresAt) {
+			unlock()
+			continue
+		}
+		err = t.remove(id)
+		unlock()
+		if err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// jalankan cleanup secara berkala sampai ctx dibatalkan
+func (t *TusStore) StartCleanup(ctx context.Context, report func(removed int, err error)) {
+	interval := t.config.CleanupInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				removed, err := t.Cleanup(now)
+				if report != nil {
+					report(removed, err)
+				}
+			}
+		}
+	}()
+}
+
+// pindahkan data ke storage dengan nama dari metadata filename
+func (t *TusStore) finish(ctx context.Context, upload *TusUpload) error {
+	name := upload.Metadata["filename"]
+	if name == "" {
+		name = upload.ID
+	}
+
+	data, err := os.Open(t.dataPath(upload.ID))
+	if err != nil {
+		return err
+	}
+	file := &File{
+		ID:          NewFileID(),
+		Field:       "tus",
+		Name:        filepath.Base(name),
+		ContentType: upload.Metadata["filetype"],
+		Owner:       upload.Owner,
+		Tags:        ParseTags(upload.Metadata["tags"]),
+	}
+
+	checksum := sha256.New()
+	file.Size, err = t.storage.Save(ctx, file.ID, io.TeeReader(data, checksum))
+	data.Close()
+	if err != nil {
+		return fmt.Errorf("%w : %w", ErrStorage, err)
+	}
+	file.Checksum = hex.EncodeToString(checksum.Sum(nil))
+
+	// upload yang ditolak pipeline dihapus seluruhnya
+	if err := t.Pipeline.Run(ctx, file); err != nil {
+		t.storage.Remove(ctx, file.ID)
+		t.remove(upload.ID)
+		return err
+	}
+
+	upload.File = file
+	if err := t.save(upload); err != nil {
+		return err
+	}
+	return os.Remove(t.dataPath(upload.ID))
+}
+
+func (t *TusStore) load(id string) (*TusUpload, error) {
+	if !uploadIdPattern.MatchString(id) {
+		return nil, ErrUploadNotFound
+	}
+
+	content, err := os.ReadFile(t.infoPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &TusUpload{}
+	if err := json.Unmarshal(content, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// info ditulis ke file sementara lalu di-rename agar tidak pernah terbaca setengah
+func (t *TusStore) save(upload *TusUpload) error {
+	content, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+
+	temp := t.infoPath(upload.ID) + ".tmp"
+	if err := os.WriteFile(temp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(temp, t.infoPath(upload.ID))
+}
+
+func (t *TusStore) remove(id string) error {
+	if err := os.Remove(t.dataPath(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return os.Remove(t.infoPath(id))
+}
+
+// lock per upload lewat file .lock agar berlaku juga antar proses prefork. PATCH paralel,
+// termination & cleanup untuk upload yang sama dijalankan bergantian
+func (t *TusStore) lock(id string) (func(), error) {
+	if !uploadIdPattern.MatchString(id) {
+		return nil, ErrUploadNotFound
+	}
+	unlock, err := filelock.Lock(t.lockPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		// upload sudah dihapus, file lock ikut dihapus selagi masih dipegang
+		if _, err := os.Stat(t.infoPath(id)); errors.Is(err, fs.ErrNotExist) {
+			os.Remove(t.lockPath(id))
+		}
+		unlock()
+	}, nil
+}
+
+func (t *TusStore) dataPath(id string) string {
+	return filepath.Join(t.config.Directory, id+".bin")
+}
+
+func (t *TusStore) infoPath(id string) string {
+	return filepath.Join(t.config.Directory, id+".info")
+}
+
+func (t *TusStore) lockPath(id string) string {
+	return filepath.Join(t.config.Directory, id+".lock")
+}
@@ -0,0 +1,272 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/google/uuid"
+	"go_fiber/filelock"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FileStore keeps one JSON document per user. Usernames are reserved with a marker file
+// linked into place exclusively, so two prefork processes cannot register the same
+// username and a crash never leaves a marker without an id.
+//
+//	users/<id>.json          the user
+//	usernames/<hash>         id of the user with that username
+type FileStore struct {
+	directory string
+}
+
+// function provider
+func NewFileStore(directory string) *FileStore {
+	return &FileStore{
+		directory: directory,
+	}
+}
+
+// user di disk beserta hash password, User sendiri tidak meng-encode hash
+type record struct {
+	User
+	PasswordHash string `json:"password_hash"`
+}
+
+func (f *FileStore) Create(ctx context.Context, user User) (User, error) {
+	for _, directory := range []string{"users", "usernames"} {
+		if err := os.MkdirAll(filepath.Join(f.directory, directory), 0o755); err != nil {
+			return User{}, err
+		}
+	}
+
+	now := time.Now().UTC()
+	user.ID = uuid.NewString()
+	user.CreatedAt, user.UpdatedAt = now, now
+	if err := f.reserve(user.Username, user.ID); err != nil {
+		return User{}, err
+	}
+	if err := f.save(user); err != nil {
+		f.release(user.Username)
+		return User{}, err
+	}
+	return user, nil
+}
+
+func (f *FileStore) Get(ctx context.Context, id string) (User, error) {
+	encoded, err := os.ReadFile(f.userPath(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+
+	var stored record
+	if err := json.Unmarshal(encoded, &stored); err != nil {
+		return User{}, err
+	}
+	stored.User.PasswordHash = stored.PasswordHash
+	return stored.User, nil
+}
+
+func (f *FileStore) GetByUsername(ctx context.Context, username string) (User, error) {
+	id, err := os.ReadFile(f.usernamePath(username))
+	if errors.Is(err, fs.ErrNotExist) {
+		return User{}, ErrNotFound
+	}
+	if err != nil {
+		return User{}, err
+	}
+	return f.Get(ctx, string(id))
+}
+
+// username yang berubah dipesan dulu, username lama baru dilepas setelah user tersimpan
+func (f *FileStore) Update(ctx context.Context, user User) error {
+	current, err := f.Get(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	renamed := !strings.EqualFold(current.Username, user.Username)
+	if renamed {
+		if err := f.reserve(user.Username, user.ID); err != nil {
+			return err
+		}
+	}
+
+	user.CreatedAt = current.CreatedAt
+	user.UpdatedAt = time.Now().UTC()
+	if err := f.save(user); err != nil {
+		if renamed {
+			f.release(user.Username)
+		}
+		return err
+	}
+	if renamed {
+		f.release(current.Username)
+	}
+	return nil
+}
+
+func (f *FileStore) List(ctx context.Context, query Query) ([]User, int, error) {
+	files, err := os.ReadDir(filepath.Join(f.directory, "users"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return []User{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matched := []User{}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, err
+		}
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		user, err := f.Get(ctx, strings.TrimSuffix(file.Name(), ".json"))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if query.Matches(user) {
+			matched = append(matched, user)
+		}
+	}
+
+	// urutan terdaftar, id untuk urutan yang stabil
+	sort.Slice(matched, func(i, j int) bool {
+		if result := matched[i].CreatedAt.Compare(matched[j].CreatedAt); result != 0 {
+			return result < 0
+		}
+		return matched[i].ID < matched[j].ID
+	})
+	return query.Paginate(matched), len(matched), nil
+}
+
+// Matches reports whether user passes the filters of the query.
+func (q Query) Matches(user User) bool {
+	if user.Deleted() && !q.Deleted {
+		return false
+	}
+	if q.Name != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(q.Name)) {
+		return false
+	}
+	if q.Email != "" && !strings.Contains(strings.ToLower(user.Username), strings.ToLower(q.Email)) {
+		return false
+	}
+	return true
+}
+
+// Paginate returns the users of the requested page, pages start at 1.
+func (q Query) Paginate(users []User) []User {
+	if q.PerPage <= 0 {
+		return users
+	}
+	start := (max(q.Page, 1) - 1) * q.PerPage
+	if start >= len(users) {
+		return []User{}
+	}
+	return users[start:min(start+q.PerPage, len(users))]
+}
+
+func (f *FileStore) save(user User) error {
+	encoded, err := json.Marshal(record{User: user, PasswordHash: user.PasswordHash})
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Join(f.directory, "users"), ".user-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.Write(encoded)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), f.userPath(user.ID))
+}
+
+// id ditulis ke file sementara lalu di-link ke nama marker, link gagal jika marker sudah ada
+func (f *FileStore) reserve(username string, id string) error {
+	temp, err := os.CreateTemp(filepath.Join(f.directory, "usernames"), ".username-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	_, err = temp.WriteString(id)
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	path := f.usernamePath(username)
+	err = os.Link(temp.Name(), path)
+	if errors.Is(err, fs.ErrExist) {
+		return f.reclaim(path, temp.Name())
+	}
+	return err
+}
+
+// marker kosong ditinggalkan crash versi lama di antara create & write, dianggap bebas.
+// diambil di bawah lock supaya hanya satu proses yang menggantinya
+func (f *FileStore) reclaim(path string, temp string) error {
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	id, err := os.ReadFile(path)
+	if err == nil && len(id) > 0 {
+		return ErrUsernameTaken
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	err = os.Link(temp, path)
+	if errors.Is(err, fs.ErrExist) {
+		return ErrUsernameTaken
+	}
+	return err
+}
+
+func (f *FileStore) release(username string) {
+	os.Remove(f.usernamePath(username))
+}
+
+// id dibersihkan agar tidak bisa keluar dari directory
+func (f *FileStore) userPath(id string) string {
+	return filepath.Join(f.directory, "users", filepath.Base(filepath.Clean("/"+id))+".json")
+}
+
+// username tidak peka huruf besar kecil, nama file dari hash supaya aman untuk semua karakter
+func (f *FileStore) usernamePath(username string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(username)))
+	return filepath.Join(f.directory, "usernames", hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,112 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+var (
+	ErrNotFound      = errors.New("user not found")
+	ErrUsernameTaken = errors.New("username is already taken")
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// User is a registered user. Username is the email address used to log in. A deleted
+// user keeps its record (and username) until it is restored.
+type User struct {
+	ID           string     `json:"id"`
+	Username     string     `json:"username"`
+	Name         string     `json:"name"`
+	Role         string     `json:"role"`
+	PasswordHash string     `json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+func (u User) Deleted() bool {
+	return u.DeletedAt != nil
+}
+
+// bandingkan password dengan hash bcrypt
+func (u User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// Query filters and paginates List. Name and Email match case-insensitively on any part
+// of the value, deleted users are only listed with Deleted.
+type Query struct {
+	Name    string
+	Email   string
+	Deleted bool
+	Page    int
+	PerPage int
+}
+
+// Store keeps the users. Create and Update return ErrUsernameTaken when another user,
+// deleted or not, already has the username.
+type Store interface {
+	Create(ctx context.Context, user User) (User, error)
+	Get(ctx context.Context, id string) (User, error)
+	GetByUsername(ctx context.Context, username string) (User, error)
+	Update(ctx context.Context, user User) error
+	// List returns one page of the matching users and the number of all matching users
+	List(ctx context.Context, query Query) ([]User, int, error)
+}
+
+// Config is loaded from the "users" section of config.json.
+type Config struct {
+	Directory  string `mapstructure:"directory"`
+	BcryptCost int    `mapstructure:"bcrypt_cost"`
+}
+
+// user baru dengan role user dan password yang sudah di-hash, belum disimpan ke store.
+// register tidak pernah memberi role lain, admin dibuat lewat PromoteAdmin
+func (c Config) NewUser(username string, password string, name string) (User, error) {
+	user := User{
+		Username: username,
+		Name:     name,
+		Role:     RoleUser,
+	}
+	return user, c.SetPassword(&user, password)
+}
+
+// PromoteAdmin gives the admin role to a registered user that is not deleted, see the
+// "promote-admin" command.
+func PromoteAdmin(ctx context.Context, store Store, username string) (User, error) {
+	user, err := store.GetByUsername(ctx, username)
+	if err != nil {
+		return User{}, err
+	}
+	if user.Deleted() {
+		return User{}, ErrNotFound
+	}
+	if user.IsAdmin() {
+		return user, nil
+	}
+	user.Role = RoleAdmin
+	return user, store.Update(ctx, user)
+}
+
+func (c Config) SetPassword(user *User, password string) error {
+	cost := c.BcryptCost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hash)
+	return nil
+}
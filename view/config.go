@@ -0,0 +1,23 @@
+package view
+
+// key locals yang otomatis tersedia di template lewat PassLocalsToViews
+const (
+	AppNameKey     = "appName"
+	CurrentUserKey = "currentUser"
+	CsrfTokenKey   = "csrfToken"
+	RequestIdKey   = "requestId"
+	FlashKey       = "flash"
+	AssetKey       = "asset"
+	FormatDateKey  = "formatDate"
+)
+
+// Config is loaded from the "view" section of config.json. With DevMode views and
+// assets are read from Directory and AssetDir on disk instead of the embedded copies.
+type Config struct {
+	DevMode     bool   `mapstructure:"dev_mode"`
+	Directory   string `mapstructure:"directory"`
+	AppName     string `mapstructure:"app_name"`
+	DateFormat  string `mapstructure:"date_format"`
+	AssetDir    string `mapstructure:"asset_dir"`
+	AssetPrefix string `mapstructure:"asset_prefix"`
+}
@@ -0,0 +1,10 @@
+package view
+
+import (
+	"embed"
+)
+
+// template mustache yang di-embed ke binary
+//
+//go:embed *.mustache layouts partials errors
+var Files embed.FS
@@ -0,0 +1,21 @@
+package view
+
+import (
+	"github.com/gofiber/template/mustache/v2"
+	"net/http"
+)
+
+// layout default untuk semua halaman, konten halaman dirender ke {{{embed}}}
+const DefaultLayout = "layouts/main"
+
+// engine mustache dari template yang di-embed, dengan dukungan partial {{> partials/header}}
+func NewEngine() *mustache.Engine {
+	return mustache.NewFileSystem(http.FS(Files), ".mustache")
+}
+
+// engine mustache dari disk untuk development, template di-reload setiap render
+func NewDevEngine(directory string) *mustache.Engine {
+	engine := mustache.NewFileSystem(http.Dir(directory), ".mustache")
+	engine.Reload(true)
+	return engine
+}
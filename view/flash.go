@@ -0,0 +1,64 @@
+package view
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const flashCookie = "flash"
+
+// Flash is a one-shot message shown on the next rendered page
+type Flash struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+// simpan flash message di cookie, ditampilkan di halaman berikutnya lalu dihapus
+func SetFlash(ctx *fiber.Ctx, level, message string) {
+	flashes := append(readFlashes(ctx), Flash{Level: level, Message: message})
+	encoded, err := json.Marshal(flashes)
+	if err != nil {
+		return
+	}
+
+	ctx.Cookie(&fiber.Cookie{
+		Name:     flashCookie,
+		Value:    base64.RawURLEncoding.EncodeToString(encoded),
+		Path:     "/",
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// ambil flash message dan hapus cookie-nya
+func ConsumeFlashes(ctx *fiber.Ctx) []Flash {
+	flashes := readFlashes(ctx)
+	if len(flashes) > 0 {
+		ctx.Cookie(&fiber.Cookie{
+			Name:    flashCookie,
+			Path:    "/",
+			Expires: time.Unix(0, 0),
+		})
+	}
+	return flashes
+}
+
+func readFlashes(ctx *fiber.Ctx) []Flash {
+	value := ctx.Cookies(flashCookie)
+	if value == "" {
+		return nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil
+	}
+
+	var flashes []Flash
+	if err := json.Unmarshal(decoded, &flashes); err != nil {
+		return nil
+	}
+	return flashes
+}
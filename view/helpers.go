@@ -0,0 +1,75 @@
+package view
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/cbroglie/mustache"
+	"io/fs"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AssetHasher builds asset URLs with a content hash, so assets can be cached forever
+type AssetHasher struct {
+	files  fs.FS
+	prefix string
+	hashes sync.Map
+}
+
+// function provider
+func NewAssetHasher(files fs.FS, prefix string) *AssetHasher {
+	return &AssetHasher{
+		files:  files,
+		prefix: strings.TrimSuffix(prefix, "/"),
+	}
+}
+
+// css/app.css -> /public/css/app.css?v=1a2b3c4d5e
+func (a *AssetHasher) URL(path string) string {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+	url := a.prefix + "/" + path
+
+	if hash, ok := a.hashes.Load(path); ok {
+		return url + "?v=" + hash.(string)
+	}
+
+	content, err := fs.ReadFile(a.files, path)
+	if err != nil {
+		// file tidak ada, url tetap dikembalikan tanpa hash
+		return url
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])[:10]
+	a.hashes.Store(path, hash)
+	return url + "?v=" + hash
+}
+
+// lambda {{#asset}}css/app.css{{/asset}}
+func (a *AssetHasher) Lambda() mustache.LambdaFunc {
+	return func(text string, render mustache.RenderFunc) (string, error) {
+		path, err := render(text)
+		if err != nil {
+			return "", err
+		}
+		return a.URL(path), nil
+	}
+}
+
+// lambda {{#formatDate}}{{createdAt}}{{/formatDate}}, value harus RFC 3339
+func FormatDateLambda(layout string) mustache.LambdaFunc {
+	return func(text string, render mustache.RenderFunc) (string, error) {
+		value, err := render(text)
+		if err != nil {
+			return "", err
+		}
+
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+		if err != nil {
+			// bukan tanggal, tampilkan apa adanya
+			return value, nil
+		}
+		return parsed.Format(layout), nil
+	}
+}